@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/tagcheck"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeViolations is returned by check and policy commands when stacks violate the policy
+const exitCodeViolations = 2
+
+var tagCheckFormat string
+
+// newCheckCommand creates the check command, grouping the compliance checks
+func newCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check detected stacks for compliance",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newCheckTagsCommand())
+
+	return cmd
+}
+
+// newCheckTagsCommand creates the check tags subcommand
+func newCheckTagsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tags <policy.yaml>",
+		Short: "Check that every stack has the tags required by a policy",
+		Long: `tags checks the tags of every detected stack against the required tags of a
+policy file, and reports missing, empty and invalid tags.
+
+The policy is a YAML or JSON file listing the required keys, each with an optional
+regular expression the whole value must match:
+
+  required:
+    - key: Owner
+    - key: CostCenter
+      pattern: "[0-9]{4}"
+    - key: Environment
+      pattern: "dev|staging|prod"
+
+Stacks whose tags could not be read are reported as errors. The command exits
+with status 2 when any stack violates the policy or could not be checked.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCheckTags,
+	}
+
+	addAWSFlags(cmd)
+	addInputFlag(cmd)
+	addProfileScanFlags(cmd)
+	addFilterFlags(cmd)
+	cmd.Flags().StringVarP(&tagCheckFormat, "output", "o", "table", "Output format (table, json, junit)")
+
+	return cmd
+}
+
+func runCheckTags(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	formatter, err := tagcheck.FormatterFactory(tagCheckFormat)
+	if err != nil {
+		return err
+	}
+
+	policy, err := tagcheck.LoadPolicy(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig()
+	if err := prepareSource(&cfg); err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	var scanErrors []models.ScanError
+	if doc.Scan != nil {
+		scanErrors = doc.Scan.Errors
+	}
+	report := tagcheck.Check(doc.Stacks, policy, scanErrors)
+	result, err := formatter.Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format tag check report: %w", err)
+	}
+	fmt.Println(result)
+
+	if report.HasViolations() {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: exitCodeViolations}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/spf13/cobra"
+)
+
+// newCredentialsCommand creates the credentials command, grouping credential cache maintenance
+func newCredentialsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "credentials",
+		Short: "Manage cached AWS credentials",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newClearCacheCommand())
+
+	return cmd
+}
+
+// newClearCacheCommand creates the credentials clear-cache subcommand
+func newClearCacheCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear-cache",
+		Short: "Delete the assumed-role credentials cached by --cache-credentials",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := aws.DefaultCredentialCacheDir()
+			if err != nil {
+				return err
+			}
+
+			removed, err := aws.ClearCredentialCache(dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %d cached credential(s) from %s\n", removed, dir)
+			return nil
+		},
+	}
+}
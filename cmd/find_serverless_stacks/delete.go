@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/deletion"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteExecute     bool
+	deleteYes         bool
+	deleteWaitTimeout time.Duration
+)
+
+// newDeleteCommand creates the delete subcommand
+func newDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete detected stacks and their deployment buckets",
+		Long: `delete removes the stacks of a saved report (--input) or of a scan narrowed
+down with filter flags. Each stack's versioned ServerlessDeploymentBucket is emptied
+first, then the stack is deleted and the command waits until the deletion completes.
+
+By default nothing is deleted: the command shows what would be removed. Add --execute
+to delete, and confirm by typing the prompt or pass --yes. Stacks with termination
+protection, nested stacks, stacks in the middle of an operation and stacks recreated
+since the report are refused.`,
+		Args: cobra.NoArgs,
+		RunE: runDelete,
+	}
+
+	addAWSFlags(cmd)
+	addInputFlag(cmd)
+	addFilterFlags(cmd)
+	cmd.Flags().BoolVar(&deleteExecute, "execute", false, "Delete the stacks instead of showing what would be deleted")
+	cmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip the typed confirmation")
+	cmd.Flags().DurationVar(&deleteWaitTimeout, "wait-timeout", 30*time.Minute, "Longest to wait for each stack deletion to complete")
+
+	return cmd
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	cfg := newConfig()
+	if err := prepareSource(&cfg); err != nil {
+		return err
+	}
+	if err := validateDelete(cfg); err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	targets, err := deletionTargets(doc.Stacks, cfg.Region)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No stacks to delete.")
+		return nil
+	}
+
+	deleter := deletion.NewDeleter(newDeletionClients(cfg))
+	deleter.WaitTimeout = deleteWaitTimeout
+
+	plan, err := deleter.Plan(ctx, targets)
+	if err != nil {
+		return err
+	}
+	fmt.Println(deletion.FormatPlan(plan))
+
+	deletable := len(plan.Deletable())
+	if !deleteExecute {
+		fmt.Println("\nDry run: nothing was deleted. Re-run with --execute to delete these stacks.")
+		return nil
+	}
+	if deletable == 0 {
+		return nil
+	}
+
+	if !deleteYes {
+		if err := confirmDeletion(cmd.InOrStdin(), cmd.OutOrStdout(), deletable); err != nil {
+			return err
+		}
+	}
+
+	failed := 0
+	deleter.Execute(ctx, plan, func(result deletion.Result) {
+		if result.Err != nil {
+			failed++
+		}
+		fmt.Println(deletion.FormatResult(result))
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d stacks", failed, deletable)
+	}
+	return nil
+}
+
+// deletionTargets converts the stacks to deletion targets. Stacks without a region
+// use the region of their stack ID, then region.
+func deletionTargets(stacks []models.Stack, region string) ([]deletion.Target, error) {
+	targets := make([]deletion.Target, 0, len(stacks))
+	for _, stack := range stacks {
+		target := deletion.Target{StackName: stack.StackName, StackID: stack.StackID, Region: stack.Region}
+		if target.Region == "" {
+			target.Region = aws.RegionOfARN(stack.StackID)
+		}
+		if target.Region == "" {
+			target.Region = region
+		}
+		if target.Region == "" {
+			return nil, fmt.Errorf("stack %s has no region; pass --region", stack.StackName)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// validateDelete checks the flags of a deletion before any stack is read
+func validateDelete(cfg config.Config) error {
+	// Planning and deleting call AWS even when the stacks come from --input
+	if cfg.InputPath != "" {
+		if err := validateAWSConfig(cfg); err != nil {
+			return err
+		}
+	}
+
+	// The read-only session policy denies the deletion calls
+	if cfg.AssumeRole != nil && cfg.AssumeRole.ReadOnlyPolicy {
+		return fmt.Errorf("delete cannot use --read-only-session-policy: the session would not be allowed to delete stacks")
+	}
+
+	// Without a selection every detected stack in the region would be deleted
+	if cfg.InputPath == "" && cfg.Filter == nil {
+		return fmt.Errorf("delete requires --input or at least one filter flag (--name-glob, --name-regex, --tag, --created-*, --updated-*, --where)")
+	}
+	return nil
+}
+
+// confirmDeletion asks the user to type the number of stacks to delete
+func confirmDeletion(in io.Reader, out io.Writer, count int) error {
+	expected := fmt.Sprintf("delete %d", count)
+	fmt.Fprintf(out, "\nType '%s' to permanently delete %d stacks and their bucket contents: ", expected, count)
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+
+	if strings.TrimSpace(line) != expected {
+		return fmt.Errorf("deletion cancelled: confirmation did not match '%s'", expected)
+	}
+	return nil
+}
+
+// newDeletionClients returns a factory of CloudFormation and S3 clients for a region, using the configured credentials
+func newDeletionClients(cfg config.Config) deletion.ClientFactory {
+	return func(ctx context.Context, region string) (deletion.Clients, error) {
+		auth, err := newAuthConfig(cfg)
+		if err != nil {
+			return deletion.Clients{}, err
+		}
+		auth.Region = region
+
+		cfClient, err := aws.CreateCloudFormationClient(ctx, auth)
+		if err != nil {
+			return deletion.Clients{}, err
+		}
+		s3Client, err := aws.CreateS3Client(ctx, auth)
+		if err != nil {
+			return deletion.Clients{}, err
+		}
+
+		return deletion.Clients{CloudFormation: cfClient, S3: s3Client}, nil
+	}
+}
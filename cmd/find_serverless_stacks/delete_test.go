@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/deletion"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletionTargets(t *testing.T) {
+	stacks := []models.Stack{
+		{StackName: "orders-dev", StackID: "arn:orders-dev", Region: "eu-west-1"},
+		{StackName: "users-dev", StackID: "arn:users-dev"},
+		{StackName: "billing-dev", StackID: "arn:aws-cn:cloudformation:cn-north-1:123456789012:stack/billing-dev/0f1e2d3c"},
+	}
+
+	targets, err := deletionTargets(stacks, "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, []deletion.Target{
+		{StackName: "orders-dev", StackID: "arn:orders-dev", Region: "eu-west-1"},
+		{StackName: "users-dev", StackID: "arn:users-dev", Region: "us-east-1"},
+		{StackName: "billing-dev", StackID: "arn:aws-cn:cloudformation:cn-north-1:123456789012:stack/billing-dev/0f1e2d3c", Region: "cn-north-1"},
+	}, targets)
+
+	_, err = deletionTargets(stacks, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stack users-dev has no region")
+}
+
+func TestValidateDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{name: "saved report", cfg: config.Config{Region: "us-east-1", InputPath: "stacks.json"}},
+		{name: "filtered scan", cfg: config.Config{Region: "us-east-1", Filter: &filter.Filter{}}},
+		{name: "no selection", cfg: config.Config{Region: "us-east-1"}, wantErr: "delete requires --input"},
+		{
+			name:    "saved report with an invalid region",
+			cfg:     config.Config{Region: "moon-base-1", InputPath: "stacks.json"},
+			wantErr: "moon-base-1",
+		},
+		{
+			name:    "saved report with session flags but no role",
+			cfg:     config.Config{Region: "us-east-1", InputPath: "stacks.json", SessionFlags: []string{"--external-id"}},
+			wantErr: "--external-id requires --assume-role",
+		},
+		{
+			name:    "saved report with an invalid role",
+			cfg:     config.Config{Region: "us-east-1", InputPath: "stacks.json", AssumeRole: &config.AssumeRoleConfig{RoleARN: "not-an-arn"}},
+			wantErr: "AssumeRole configuration invalid",
+		},
+		{
+			name:    "read-only session",
+			cfg:     config.Config{Region: "us-east-1", Filter: &filter.Filter{}, AssumeRole: &config.AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/scanner", ReadOnlyPolicy: true}},
+			wantErr: "cannot use --read-only-session-policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDelete(tt.cfg)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConfirmDeletion(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectError bool
+	}{
+		{name: "matching confirmation", input: "delete 2\n"},
+		{name: "matching confirmation without newline", input: "delete 2"},
+		{name: "wrong count", input: "delete 3\n", expectError: true},
+		{name: "yes is not enough", input: "yes\n", expectError: true},
+		{name: "no input", input: "", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := confirmDeletion(strings.NewReader(tt.input), &out, 2)
+
+			assert.Contains(t, out.String(), "Type 'delete 2'")
+			if tt.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "deletion cancelled")
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/diff"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeDifferences is returned by diff when the two scans differ
+const exitCodeDifferences = 2
+
+var diffFormat string
+
+// newDiffCommand creates the diff subcommand
+func newDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <before.json> [after.json]",
+		Short: "Compare two scan results",
+		Long: `diff compares two saved JSON outputs and reports stacks that were added, removed,
+updated, had their tags changed or were reclassified, matched by stack ID.
+
+When only one file is given, it is compared against a live scan using the AWS flags.
+The command exits with status 2 when differences are found.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: runDiff,
+	}
+
+	addAWSFlags(cmd)
+	cmd.Flags().StringVarP(&diffFormat, "output", "o", "text", "Output format (text, json, markdown)")
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	formatter, err := diff.FormatterFactory(diffFormat)
+	if err != nil {
+		return err
+	}
+
+	before, err := models.LoadStacksOutput(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to load scan result: %w", err)
+	}
+
+	after, err := loadDiffTarget(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	result := diff.Compare(before.Stacks, after)
+
+	report, err := formatter.Format(result)
+	if err != nil {
+		return fmt.Errorf("failed to format diff: %w", err)
+	}
+	fmt.Println(report)
+
+	if result.HasDifferences() {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: exitCodeDifferences}
+	}
+
+	return nil
+}
+
+// loadDiffTarget loads the second scan from a file, or runs a live scan when none is given
+func loadDiffTarget(ctx context.Context, args []string) ([]models.Stack, error) {
+	if len(args) == 2 {
+		after, err := models.LoadStacksOutput(args[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scan result: %w", err)
+		}
+		return after.Stacks, nil
+	}
+
+	return scanStacks(ctx, newConfig())
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/doctor"
+	"github.com/spf13/cobra"
+)
+
+var doctorFormat string
+
+// newDoctorCommand creates the doctor subcommand
+func newDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose credential, region and permission problems",
+		Long: `doctor checks, one step at a time, everything a scan needs: that the profile
+exists in the shared config files, that its credentials load, that STS accepts them,
+that the region is enabled, that --assume-role succeeds and that each CloudFormation
+action of the scan is allowed. Each failure comes with a suggested fix.
+
+The command exits with status 1 when a check fails.`,
+		Args: cobra.NoArgs,
+		RunE: runDoctor,
+	}
+
+	addAWSFlags(cmd)
+	cmd.Flags().StringVarP(&doctorFormat, "output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	formatter, err := doctor.FormatterFactory(doctorFormat)
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig()
+	opts := doctor.Options{
+		Profile: cfg.Profile,
+		Region:  cfg.Region,
+		Now:     time.Now(),
+	}
+	if cfg.AssumeRole != nil {
+		if err := cfg.AssumeRole.Validate(); err != nil {
+			return fmt.Errorf("AssumeRole configuration invalid: %w", err)
+		}
+		opts.RoleARN = cfg.AssumeRole.RoleARN
+		opts.ExternalID = cfg.AssumeRole.ExternalID
+	}
+
+	auth, err := newAuthConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	report := doctor.Run(ctx, doctor.NewAWSEnv(auth), opts)
+	result, err := formatter.Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format doctor report: %w", err)
+	}
+	fmt.Println(result)
+
+	if report.Failed() > 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: 1}
+	}
+
+	return nil
+}
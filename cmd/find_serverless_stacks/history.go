@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/history"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyAccount string
+	historyRegion  string
+	historyFormat  string
+)
+
+// newHistoryCommand creates the history subcommand and its queries
+func newHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Query the local scan history",
+		Long: `history answers questions about scans recorded with --record-history,
+such as when a service first appeared, what existed on a given date, and how
+many Serverless stacks were deployed per month.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&historyDB, "history-db", "", "Path to the history database (default ~/.find-serverless-stacks/history.db)")
+	cmd.PersistentFlags().StringVar(&historyAccount, "account", "", "Only include scans of this AWS account ID")
+	cmd.PersistentFlags().StringVar(&historyRegion, "region", "", "Only include scans of this region")
+
+	firstSeenCmd := &cobra.Command{
+		Use:   "first-seen <service>",
+		Short: "Show when the stacks of a service first appeared",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runHistoryFirstSeen,
+	}
+	firstSeenCmd.Flags().StringVarP(&historyFormat, "output", "o", "text", "Output format (text, json)")
+
+	atCmd := &cobra.Command{
+		Use:   "at <date>",
+		Short: "Show the stacks that existed at a date (YYYY-MM-DD or RFC3339)",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runHistoryAt,
+	}
+	addOutputFlags(atCmd)
+
+	monthlyCmd := &cobra.Command{
+		Use:   "monthly",
+		Short: "Count Serverless stacks per month",
+		Args:  cobra.NoArgs,
+		RunE:  runHistoryMonthly,
+	}
+	monthlyCmd.Flags().StringVarP(&historyFormat, "output", "o", "text", "Output format (text, json)")
+
+	cmd.AddCommand(firstSeenCmd, atCmd, monthlyCmd)
+	return cmd
+}
+
+func runHistoryFirstSeen(cmd *cobra.Command, args []string) error {
+	if err := validateHistoryFormat(historyFormat); err != nil {
+		return err
+	}
+
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	appearances := history.FirstSeen(snapshots, args[0])
+	if historyFormat == "json" {
+		return printJSON(appearances)
+	}
+
+	if len(appearances) == 0 {
+		fmt.Printf("No stacks found for service %q\n", args[0])
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STACK NAME\tACCOUNT\tREGION\tFIRST SEEN\tLAST SEEN")
+	for _, a := range appearances {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.StackName, a.Account, a.Region, a.FirstSeen.Format(time.RFC3339), a.LastSeen.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+func runHistoryAt(cmd *cobra.Command, args []string) error {
+	cfg := newConfig()
+	if err := validateOutputConfig(cfg); err != nil {
+		return err
+	}
+
+	at, err := parseHistoryDate(args[0])
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	result, err := formatHistoryAt(cfg, snapshots, at)
+	if err != nil {
+		return err
+	}
+	fmt.Print(result)
+	return nil
+}
+
+// formatHistoryAt formats the stacks that existed at a time like a live scan of
+// the same stacks, with the output options of cfg
+func formatHistoryAt(cfg config.Config, snapshots []history.Snapshot, at time.Time) (string, error) {
+	formatter, err := newFormatter(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	doc := models.StacksOutput{SchemaVersion: cfg.SchemaVersion, Stacks: history.At(snapshots, at)}
+	result, err := formatter.FormatDocument(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to format output: %w", err)
+	}
+	return result, nil
+}
+
+func runHistoryMonthly(cmd *cobra.Command, args []string) error {
+	if err := validateHistoryFormat(historyFormat); err != nil {
+		return err
+	}
+
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return err
+	}
+
+	counts := history.MonthlyCounts(snapshots)
+	if historyFormat == "json" {
+		return printJSON(counts)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MONTH\tSTACKS")
+	for _, c := range counts {
+		fmt.Fprintf(w, "%s\t%d\n", c.Month, c.Stacks)
+	}
+	return w.Flush()
+}
+
+// recordScan stores the detected stacks as a snapshot of the account and the scanned region
+func recordScan(cfg config.Config, account string, stacks []models.Stack, scannedAt time.Time) error {
+	store, err := history.Open(cfg.HistoryPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return store.Record(history.Snapshot{
+		Account:   account,
+		Region:    cfg.Region,
+		ScannedAt: scannedAt,
+		Stacks:    stacks,
+	})
+}
+
+// loadSnapshots reads the snapshots selected by the history flags
+func loadSnapshots() ([]history.Snapshot, error) {
+	path := historyDB
+	if path == "" {
+		defaultPath, err := history.DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no scan history found at %s (record scans with --record-history): %w", path, err)
+	}
+
+	store, err := history.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	return store.Snapshots(history.Filter{
+		Account: historyAccount,
+		Region:  historyRegion,
+	})
+}
+
+// parseHistoryDate parses a date or timestamp; a bare date means the end of that day in UTC
+func parseHistoryDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s': expected YYYY-MM-DD or RFC3339", value)
+	}
+	return day.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// validateHistoryFormat checks the output format of the history reports
+func validateHistoryFormat(format string) error {
+	switch format {
+	case "text", "json":
+		return nil
+	default:
+		return fmt.Errorf("invalid output format '%s'. Supported formats: text, json", format)
+	}
+}
+
+// printJSON writes a value to stdout as JSON
+func printJSON(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
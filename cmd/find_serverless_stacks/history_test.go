@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/history"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHistoryDate(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Time
+		expectError bool
+	}{
+		{
+			name:     "bare date means end of day",
+			value:    "2026-03-01",
+			expected: time.Date(2026, 3, 1, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			name:     "RFC3339 timestamp",
+			value:    "2026-03-01T09:30:00Z",
+			expected: time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:        "invalid date",
+			value:       "March 1st",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseHistoryDate(tt.value)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %s, got %s", tt.expected, result)
+		})
+	}
+}
+
+func TestFormatHistoryAt(t *testing.T) {
+	scannedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []history.Snapshot{{
+		Account:   "123456789012",
+		Region:    "us-east-1",
+		ScannedAt: scannedAt,
+		Stacks: []models.Stack{
+			{StackName: "orders-api-prod", Region: "us-east-1", CreatedAt: createdAt, NeverUpdated: true, StackStatus: "CREATE_COMPLETE", StackTags: map[string]string{"Owner": "team-a"}},
+			{StackName: "billing-dev", Region: "us-east-1", CreatedAt: createdAt, NeverUpdated: true, StackTags: map[string]string{"Owner": "team-b"}},
+		},
+	}}
+
+	tests := []struct {
+		name     string
+		cfg      config.Config
+		expected string
+	}{
+		{
+			name:     "template",
+			cfg:      config.Config{OutputFormat: "template", Template: "{{range .Stacks}}{{.StackName}} {{end}}", SchemaVersion: 1},
+			expected: "billing-dev orders-api-prod",
+		},
+		{
+			name:     "columns and sort",
+			cfg:      config.Config{OutputFormat: "tsv", Columns: []string{"stackName", "stackTags.Owner"}, SortBy: "stackName", Reverse: true, SchemaVersion: 1},
+			expected: "stackName\tstackTags.Owner\norders-api-prod\tteam-a\nbilling-dev\tteam-b",
+		},
+		{
+			name:     "schema version and fields",
+			cfg:      config.Config{OutputFormat: "json", Query: "stacks[?stackStatus].[updatedAt, neverUpdated, stackStatus]", Fields: []string{"stackStatus"}, SchemaVersion: 2},
+			expected: `[[null,true,"CREATE_COMPLETE"]]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := formatHistoryAt(tt.cfg, snapshots, scannedAt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, strings.TrimSpace(result))
+		})
+	}
+}
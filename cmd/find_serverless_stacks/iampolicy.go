@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/expr"
+	"github.com/hassaku63/find-serverless-stacks/internal/iampolicy"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyFeatures    []string
+	policyAccount     string
+	policyRegions     []string
+	policyPartition   string
+	policyBuckets     []string
+	trustPolicy       bool
+	trustedPrincipals []string
+	allowSessionTags  bool
+	allowSourceID     bool
+)
+
+// newIAMPolicyCommand creates the iam-policy subcommand
+func newIAMPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "iam-policy",
+		Short: "Print the least-privilege IAM policy for the chosen features",
+		Long: `iam-policy prints the IAM identity policy granting exactly the actions the tool
+calls for the chosen features. Scanning is always included; add --feature delete for
+the delete subcommand and --feature doctor for the region opt-in check of doctor.
+
+--region and --account scope stack ARNs, and the region of ListStacks, to the
+regions and account being scanned. ARNs use the partition of the regions, such as
+aws-cn for cn-north-1; pass --partition when no region is given.
+
+With --feature delete, the deployment bucket permissions match the bucket names
+Serverless Framework generates, *-serverlessdeploymentbucket-*. That pattern misses
+buckets set with provider.deploymentBucket and names shortened for long stack
+names. Pass --input with a scan saved with --fields outputs to grant exactly the
+buckets of its stacks, from their ServerlessDeploymentBucketName outputs, or name
+the buckets with --bucket.
+
+With --trust, the command prints instead the trust policy of a cross-account
+scanning role, allowing the --trusted-principal accounts or ARNs to assume it,
+optionally only with --external-id. Add --allow-session-tags and
+--allow-source-identity when the scanner passes --session-tag or --source-identity.
+
+The policies are generated from the registry of API calls the tool makes, so they
+follow the tool as it changes.`,
+		Example: `  find_serverless_stacks iam-policy --region us-east-1 --account 123456789012
+  find_serverless_stacks iam-policy --feature delete
+  find_serverless_stacks iam-policy --feature delete --input stacks.json
+  find_serverless_stacks iam-policy --region cn-north-1 --region cn-northwest-1
+  find_serverless_stacks iam-policy --trust --trusted-principal 111111111111 --external-id my-id`,
+		Args: cobra.NoArgs,
+		RunE: runIAMPolicy,
+	}
+
+	cmd.Flags().StringSliceVar(&policyFeatures, "feature", nil, "Optional features to grant (delete, doctor)")
+	cmd.Flags().StringVar(&policyAccount, "account", "", "Account ID to scope stack ARNs to (default: any account)")
+	cmd.Flags().StringSliceVarP(&policyRegions, "region", "r", nil, "Regions to scope the policy to; repeatable (default: any region)")
+	cmd.Flags().StringSliceVar(&policyBuckets, "bucket", nil, "Deployment bucket the delete feature may empty; repeatable (default: generated bucket names)")
+	addInputFlag(cmd)
+	cmd.Flags().StringVar(&policyPartition, "partition", "", "Partition of the ARNs: aws, aws-cn or aws-us-gov (default: the partition of --region, or aws)")
+	cmd.Flags().BoolVar(&trustPolicy, "trust", false, "Print the trust policy of a cross-account scanning role instead")
+	cmd.Flags().StringSliceVar(&trustedPrincipals, "trusted-principal", nil, "Account ID or IAM ARN allowed to assume the role (with --trust); repeatable")
+	cmd.Flags().StringVar(&externalID, "external-id", "", "External ID the trusted principals must pass (with --trust)")
+	cmd.Flags().BoolVar(&allowSessionTags, "allow-session-tags", false, "Allow the trusted principals to tag their sessions (with --trust)")
+	cmd.Flags().BoolVar(&allowSourceID, "allow-source-identity", false, "Allow the trusted principals to set a source identity (with --trust)")
+
+	return cmd
+}
+
+func runIAMPolicy(cmd *cobra.Command, args []string) error {
+	doc, err := buildIAMPolicy()
+	if err != nil {
+		return err
+	}
+
+	data, err := doc.Marshal()
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
+
+// buildIAMPolicy builds the policy selected by the flags
+func buildIAMPolicy() (*iampolicy.Document, error) {
+	if trustPolicy {
+		if len(policyFeatures) > 0 || policyAccount != "" || len(policyRegions) > 0 || len(policyBuckets) > 0 || inputPath != "" {
+			return nil, fmt.Errorf("--feature, --account, --region, --bucket and --input cannot be used with --trust")
+		}
+		return iampolicy.Trust(iampolicy.TrustOptions{
+			Principals:     trustedPrincipals,
+			ExternalID:     externalID,
+			SessionTags:    allowSessionTags,
+			SourceIdentity: allowSourceID,
+			Partition:      policyPartition,
+		})
+	}
+
+	if len(trustedPrincipals) > 0 || externalID != "" || allowSessionTags || allowSourceID {
+		return nil, fmt.Errorf("--trusted-principal, --external-id, --allow-session-tags and --allow-source-identity require --trust")
+	}
+
+	var features []aws.Feature
+	for _, name := range policyFeatures {
+		feature, err := iampolicy.ParseFeature(name)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, feature)
+	}
+
+	buckets := policyBuckets
+	if len(buckets) > 0 || inputPath != "" {
+		if !slices.Contains(features, aws.FeatureDelete) {
+			return nil, fmt.Errorf("--bucket and --input require --feature delete")
+		}
+	}
+	if inputPath != "" {
+		scanned, err := savedDeploymentBuckets(inputPath)
+		if err != nil {
+			return nil, err
+		}
+		buckets = append(slices.Clone(buckets), scanned...)
+	}
+
+	return iampolicy.Permissions(iampolicy.Options{
+		Features:  features,
+		Partition: policyPartition,
+		Account:   policyAccount,
+		Regions:   policyRegions,
+		Buckets:   buckets,
+	})
+}
+
+// savedDeploymentBuckets returns the deployment buckets of the stacks of a saved scan
+func savedDeploymentBuckets(path string) ([]string, error) {
+	saved, err := models.LoadStacksOutput(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load input: %w", err)
+	}
+	if slices.Contains(saved.MissingFields, "outputs") {
+		return nil, fmt.Errorf("--input: %w", &expr.MissingFieldsError{Fields: []string{"outputs"}})
+	}
+	if len(saved.Stacks) == 0 {
+		// No buckets would fall back to the pattern, granting more than the scan needs
+		return nil, fmt.Errorf("--input: %s has no stacks", path)
+	}
+
+	buckets, err := iampolicy.DeploymentBuckets(saved.Stacks)
+	if err != nil {
+		return nil, fmt.Errorf("--input: %w", err)
+	}
+	return buckets, nil
+}
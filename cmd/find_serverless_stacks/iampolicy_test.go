@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildIAMPolicy(t *testing.T) {
+	deleteActions := []string{
+		"cloudformation:ListStacks", "cloudformation:DeleteStack", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks",
+		"s3:ListBucketVersions", "s3:DeleteObject", "s3:DeleteObjectVersion",
+	}
+
+	tests := []struct {
+		name        string
+		features    []string
+		regions     []string
+		partition   string
+		trust       bool
+		principals  []string
+		externalID  string
+		tagSessions bool
+		buckets     []string
+		input       string
+		wantActions []string
+		wantARN     string
+		wantErr     string
+	}{
+		{
+			name:        "scan",
+			wantActions: []string{"cloudformation:ListStacks", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"},
+		},
+		{
+			name:        "doctor",
+			features:    []string{"doctor"},
+			wantActions: []string{"cloudformation:ListStacks", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks", "account:GetRegionOptStatus"},
+		},
+		{
+			name:        "trust",
+			trust:       true,
+			principals:  []string{"111111111111"},
+			externalID:  "my-id",
+			wantActions: []string{"sts:AssumeRole"},
+		},
+		{
+			name:        "trust with session tags",
+			trust:       true,
+			principals:  []string{"111111111111"},
+			tagSessions: true,
+			wantActions: []string{"sts:AssumeRole", "sts:TagSession"},
+		},
+		{name: "session tags without trust", tagSessions: true, wantErr: "require --trust"},
+		{
+			name:        "china regions",
+			regions:     []string{"cn-north-1"},
+			wantActions: []string{"cloudformation:ListStacks", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"},
+			wantARN:     "arn:aws-cn:cloudformation:cn-north-1:*:stack/*/*",
+		},
+		{
+			name:        "govcloud trust",
+			trust:       true,
+			principals:  []string{"111111111111"},
+			partition:   "aws-us-gov",
+			wantActions: []string{"sts:AssumeRole"},
+			wantARN:     "arn:aws-us-gov:iam::111111111111:root",
+		},
+		{name: "partition of other regions", regions: []string{"us-east-1"}, partition: "aws-cn", wantErr: "not aws-cn"},
+		{name: "unknown feature", features: []string{"drift"}, wantErr: "unknown feature"},
+		{name: "feature with trust", features: []string{"delete"}, trust: true, principals: []string{"111111111111"}, wantErr: "cannot be used with --trust"},
+		{name: "external ID without trust", externalID: "my-id", wantErr: "require --trust"},
+		{
+			name:        "bucket",
+			features:    []string{"delete"},
+			buckets:     []string{"my-deployments"},
+			wantActions: deleteActions,
+			wantARN:     "arn:aws:s3:::my-deployments/*",
+		},
+		{
+			name:        "buckets of a saved scan",
+			features:    []string{"delete"},
+			input:       `{"schemaVersion":1,"scan":{"fields":["outputs"]},"stacks":[{"stackName":"app-dev","region":"us-east-1","outputs":{"ServerlessDeploymentBucketName":"app-dev-serverlessdeploymentbuck-1a2b"}}]}`,
+			wantActions: deleteActions,
+			wantARN:     "arn:aws:s3:::app-dev-serverlessdeploymentbuck-1a2b/*",
+		},
+		{
+			name:     "saved scan without outputs",
+			features: []string{"delete"},
+			input:    `{"schemaVersion":1,"scan":{"fields":[]},"stacks":[{"stackName":"app-dev","region":"us-east-1"}]}`,
+			wantErr:  "re-save with --fields outputs",
+		},
+		{
+			name:     "saved stack without a bucket output",
+			features: []string{"delete"},
+			input:    `{"schemaVersion":1,"scan":{"fields":["outputs"]},"stacks":[{"stackName":"app-dev","region":"us-east-1"}]}`,
+			wantErr:  "name its bucket with --bucket",
+		},
+		{
+			name:     "saved scan without stacks",
+			features: []string{"delete"},
+			input:    `{"schemaVersion":1,"scan":{"fields":["outputs"]},"stacks":[]}`,
+			wantErr:  "has no stacks",
+		},
+		{name: "bucket without delete", buckets: []string{"my-deployments"}, wantErr: "require --feature delete"},
+		{name: "bucket with trust", buckets: []string{"my-deployments"}, trust: true, principals: []string{"111111111111"}, wantErr: "cannot be used with --trust"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyFeatures, policyRegions, policyAccount, policyPartition = tt.features, tt.regions, "", tt.partition
+			trustPolicy, trustedPrincipals, externalID = tt.trust, tt.principals, tt.externalID
+			allowSessionTags, policyBuckets, inputPath = tt.tagSessions, tt.buckets, ""
+			if tt.input != "" {
+				inputPath = filepath.Join(t.TempDir(), "stacks.json")
+				require.NoError(t, os.WriteFile(inputPath, []byte(tt.input), 0o644))
+			}
+			defer func() {
+				policyFeatures, policyRegions, policyPartition = nil, nil, ""
+				trustPolicy, trustedPrincipals, externalID = false, nil, ""
+				allowSessionTags, policyBuckets, inputPath = false, nil, ""
+			}()
+
+			doc, err := buildIAMPolicy()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var actions []string
+			for _, statement := range doc.Statement {
+				actions = append(actions, statement.Action...)
+			}
+			assert.Equal(t, tt.wantActions, actions)
+
+			if tt.wantARN != "" {
+				data, err := doc.Marshal()
+				require.NoError(t, err)
+				assert.Contains(t, string(data), tt.wantARN)
+			}
+		})
+	}
+}
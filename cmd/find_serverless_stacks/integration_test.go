@@ -1,13 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
 	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/hassaku63/find-serverless-stacks/internal/policy"
+	"github.com/hassaku63/find-serverless-stacks/internal/summary"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,7 +106,7 @@ func TestCreateAWSClient(t *testing.T) {
 	}
 }
 
-func TestRunDetection(t *testing.T) {
+func TestDetectStacks(t *testing.T) {
 	cfg := config.Config{
 		Profile:      "test-profile",
 		Region:       "us-east-1",
@@ -112,7 +120,9 @@ func TestRunDetection(t *testing.T) {
 		details:   make(map[string]*types.Stack),
 	}
 
-	output, err := runDetection(context.Background(), mockClient, cfg)
+	stacks, err := detectStacks(context.Background(), mockClient, cfg)
+	require.NoError(t, err)
+	output, err := formatOutput(stacks, cfg.OutputFormat)
 	require.NoError(t, err)
 	assert.Contains(t, output, `"stacks":[]`)
 
@@ -132,13 +142,16 @@ func TestRunDetection(t *testing.T) {
 		},
 	}
 
-	output, err = runDetection(context.Background(), mockClient, cfg)
+	stacks, err = detectStacks(context.Background(), mockClient, cfg)
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	output, err = formatOutput(stacks, cfg.OutputFormat)
 	require.NoError(t, err)
 	assert.Contains(t, output, stackName)
 	assert.Contains(t, output, "ServerlessDeploymentBucket")
 }
 
-func TestRunDetection_ErrorHandling(t *testing.T) {
+func TestDetectStacks_ErrorHandling(t *testing.T) {
 	cfg := config.Config{
 		Profile:      "test-profile",
 		Region:       "us-east-1",
@@ -150,11 +163,79 @@ func TestRunDetection_ErrorHandling(t *testing.T) {
 		shouldErr: true,
 	}
 
-	_, err := runDetection(context.Background(), mockClient, cfg)
+	_, err := detectStacks(context.Background(), mockClient, cfg)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to detect serverless stacks")
 }
 
+func TestScanOutput(t *testing.T) {
+	mockClient := &mockAWSClient{
+		stacks: []types.StackSummary{
+			{StackName: aws.String("test-stack"), StackId: aws.String("test-stack-id")},
+		},
+		resources: map[string][]types.StackResource{
+			"test-stack": {
+				{LogicalResourceId: aws.String("ServerlessDeploymentBucket"), ResourceType: aws.String("AWS::S3::Bucket")},
+			},
+		},
+	}
+	cfg := config.Config{
+		Profile:       "scanner",
+		Region:        "us-east-1",
+		AssumeRole:    &config.AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/Scanner"},
+		SchemaVersion: models.LatestSchemaVersion,
+	}
+
+	doc, err := scanOutput(context.Background(), mockClient, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.LatestSchemaVersion, doc.SchemaVersion)
+	require.Len(t, doc.Stacks, 1)
+
+	require.NotNil(t, doc.Scan)
+	assert.False(t, doc.Scan.StartedAt.IsZero())
+	assert.GreaterOrEqual(t, doc.Scan.Duration, 0.0)
+	assert.Equal(t, "scanner", doc.Scan.Profile)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/Scanner", doc.Scan.RoleARN)
+	assert.Equal(t, "aws", doc.Scan.Partition)
+	assert.Equal(t, []string{"us-east-1"}, doc.Scan.Regions)
+	assert.Equal(t, version, doc.Scan.ToolVersion)
+	assert.Equal(t, []string{"ServerlessDeploymentBucket"}, doc.Scan.RulesApplied)
+	require.NotNil(t, doc.Scan.StacksScanned)
+	assert.Equal(t, 1, *doc.Scan.StacksScanned)
+	assert.Empty(t, doc.Scan.Errors)
+	assert.NotNil(t, doc.Scan.Errors)
+}
+
+func TestScanOutput_Partition(t *testing.T) {
+	tests := []struct {
+		region        string
+		stackID       string
+		wantPartition string
+	}{
+		{region: "us-east-1", stackID: "arn:aws:cloudformation:us-east-1:123456789012:stack/api-dev/1", wantPartition: "aws"},
+		{region: "cn-north-1", stackID: "arn:aws-cn:cloudformation:cn-north-1:123456789012:stack/api-dev/1", wantPartition: "aws-cn"},
+		{region: "us-gov-west-1", stackID: "arn:aws-us-gov:cloudformation:us-gov-west-1:123456789012:stack/api-dev/1", wantPartition: "aws-us-gov"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			mockClient := &mockAWSClient{
+				stacks: []types.StackSummary{{StackName: aws.String("api-dev"), StackId: aws.String(tt.stackID)}},
+				resources: map[string][]types.StackResource{
+					"api-dev": {{LogicalResourceId: aws.String("ServerlessDeploymentBucket"), ResourceType: aws.String("AWS::S3::Bucket")}},
+				},
+			}
+
+			doc, err := scanOutput(context.Background(), mockClient, config.Config{Region: tt.region, SchemaVersion: 1})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPartition, doc.Scan.Partition)
+			require.Len(t, doc.Stacks, 1)
+			assert.Equal(t, tt.stackID, doc.Stacks[0].StackID)
+		})
+	}
+}
+
 func TestFormatOutput(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -204,3 +285,256 @@ func TestFormatOutput_InvalidFormat(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create formatter")
 }
+
+func TestNewFormatter_Template(t *testing.T) {
+	stacks := []models.Stack{{StackName: "test-stack", StackTags: map[string]string{"Owner": "team-a"}}}
+
+	t.Run("inline template", func(t *testing.T) {
+		formatter, err := newFormatter(config.Config{
+			OutputFormat: "template",
+			Template:     `{{range .Stacks}}{{.StackName}} {{tag . "Owner"}}{{end}}`,
+		})
+		require.NoError(t, err)
+
+		output, err := formatter.Format(stacks)
+		require.NoError(t, err)
+		assert.Equal(t, "test-stack team-a", output)
+	})
+
+	t.Run("template file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "stacks.tmpl")
+		require.NoError(t, os.WriteFile(path, []byte(`{{len .Stacks}}`), 0o644))
+
+		formatter, err := newFormatter(config.Config{OutputFormat: "template", TemplateFile: path})
+		require.NoError(t, err)
+
+		output, err := formatter.Format(stacks)
+		require.NoError(t, err)
+		assert.Equal(t, "1", output)
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		_, err := newFormatter(config.Config{OutputFormat: "template", Template: `{{range .Stacks}}`})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse template")
+	})
+
+	t.Run("missing template file", func(t *testing.T) {
+		_, err := newFormatter(config.Config{OutputFormat: "template", TemplateFile: filepath.Join(t.TempDir(), "missing.tmpl")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to read template file")
+	})
+
+	t.Run("template without template output", func(t *testing.T) {
+		_, err := newFormatter(config.Config{OutputFormat: "json", Template: `{{len .Stacks}}`})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "require --output template")
+	})
+}
+
+func TestNewFormatter_Columns(t *testing.T) {
+	stacks := []models.Stack{{StackName: "test-stack", Region: "us-east-1"}}
+
+	formatter, err := newFormatter(config.Config{OutputFormat: "csv", Columns: []string{"stackName", "region"}})
+	require.NoError(t, err)
+
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Equal(t, "stackName,region\r\ntest-stack,us-east-1", output)
+
+	_, err = newFormatter(config.Config{OutputFormat: "json", Columns: []string{"stackName"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--columns requires")
+
+	_, err = newFormatter(config.Config{OutputFormat: "tsv", Columns: []string{"name"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field 'name'")
+}
+
+func TestNewFormatter_Fields(t *testing.T) {
+	stacks := []models.Stack{{
+		StackName:   "test-stack",
+		Region:      "us-east-1",
+		StackStatus: "UPDATE_COMPLETE",
+		Outputs:     map[string]string{"ServiceEndpoint": "https://example.com"},
+	}}
+
+	formatter, err := newFormatter(config.Config{OutputFormat: "json"})
+	require.NoError(t, err)
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "stackStatus")
+
+	formatter, err = newFormatter(config.Config{OutputFormat: "json", Fields: []string{"stackStatus"}})
+	require.NoError(t, err)
+	output, err = formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"stackStatus":"UPDATE_COMPLETE"`)
+	assert.NotContains(t, output, "outputs")
+
+	// Columns bring in the optional fields they refer to
+	formatter, err = newFormatter(config.Config{OutputFormat: "tsv", Columns: []string{"stackName", "outputs.ServiceEndpoint"}})
+	require.NoError(t, err)
+	output, err = formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Equal(t, "stackName\toutputs.ServiceEndpoint\ntest-stack\thttps://example.com", output)
+
+	// So does the query
+	formatter, err = newFormatter(config.Config{OutputFormat: "json", Query: "stacks[?outputs.ServiceEndpoint].stackName"})
+	require.NoError(t, err)
+	output, err = formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.JSONEq(t, `["test-stack"]`, output)
+
+	// Termination protection is written when selected, also when it is off
+	unprotected := false
+	stacks[0].TerminationProtection = &unprotected
+	formatter, err = newFormatter(config.Config{OutputFormat: "json", Fields: []string{"terminationProtection"}})
+	require.NoError(t, err)
+	output, err = formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"terminationProtection":false`)
+
+	_, err = newFormatter(config.Config{OutputFormat: "json", Fields: []string{"status"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --fields")
+}
+
+func TestNewSummaryFormatter(t *testing.T) {
+	formatter, err := newSummaryFormatter(config.Config{OutputFormat: "table"})
+	require.NoError(t, err)
+	assert.IsType(t, &summary.TableFormatter{}, formatter)
+
+	formatter, err = newSummaryFormatter(config.Config{OutputFormat: "json"})
+	require.NoError(t, err)
+	assert.IsType(t, output.JSON[*summary.Report]{}, formatter)
+
+	_, err = newSummaryFormatter(config.Config{OutputFormat: "csv"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported summary format: csv")
+
+	_, err = newSummaryFormatter(config.Config{OutputFormat: "json", Query: "stacks[0]"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--summary cannot be combined")
+}
+
+func TestWriteSummary(t *testing.T) {
+	scanned := 0
+	doc := &models.StacksOutput{
+		Scan:   &models.ScanMetadata{StartedAt: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), StacksScanned: &scanned},
+		Stacks: []models.Stack{},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeSummary(&out, doc))
+	assert.Contains(t, out.String(), "STACKS")
+	assert.Regexp(t, `Scanned\s+0\n`, out.String())
+}
+
+func TestNewFormatter_Pipeline(t *testing.T) {
+	stacks := []models.Stack{
+		{StackName: "orders-prod", Region: "us-west-2", StackTags: map[string]string{"Environment": "prod"}},
+		{StackName: "orders-dev", Region: "us-east-1", StackTags: map[string]string{"Environment": "dev"}},
+		{StackName: "billing-prod", Region: "us-east-1", StackTags: map[string]string{"Environment": "prod"}},
+	}
+
+	formatter, err := newFormatter(config.Config{OutputFormat: "json", Query: "stacks[].stackName"})
+	require.NoError(t, err)
+	result, err := formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Equal(t, `["billing-prod","orders-dev","orders-prod"]`, result)
+
+	formatter, err = newFormatter(config.Config{OutputFormat: "tsv", Columns: []string{"stackName"}, SortBy: "stackTags.Environment", Reverse: true})
+	require.NoError(t, err)
+	result, err = formatter.Format(stacks)
+	require.NoError(t, err)
+	assert.Equal(t, "stackName\norders-prod\nbilling-prod\norders-dev", result)
+
+	_, err = newFormatter(config.Config{OutputFormat: "json", Query: "stacks[?"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid query")
+
+	_, err = newFormatter(config.Config{OutputFormat: "json", SortBy: "owner"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --sort-by")
+}
+
+func TestLoadStacks_Input(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stacks.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"schemaVersion":1,"scan":{"accountId":"123456789012"},"stacks":[{"stackName":"saved-stack","region":"us-east-1"}]}`), 0o644))
+
+	doc, err := loadStacks(context.Background(), config.Config{InputPath: path})
+	require.NoError(t, err)
+	require.Len(t, doc.Stacks, 1)
+	assert.Equal(t, "saved-stack", doc.Stacks[0].StackName)
+
+	// The metadata of the saved scan is kept
+	require.NotNil(t, doc.Scan)
+	assert.Equal(t, "123456789012", doc.Scan.AccountID)
+
+	stackFilter, err := filter.New(filter.Options{NameGlob: "other-*"})
+	require.NoError(t, err)
+	doc, err = loadStacks(context.Background(), config.Config{InputPath: path, Filter: stackFilter})
+	require.NoError(t, err)
+	assert.Empty(t, doc.Stacks)
+
+	// Ages in --where are measured from the scan of saved documents
+	aged := filepath.Join(t.TempDir(), "aged.json")
+	require.NoError(t, os.WriteFile(aged, []byte(`{"schemaVersion":2,"scan":{"startedAt":"2024-03-15T00:00:00Z"},"stacks":[{"stackName":"saved-stack","region":"us-east-1","updatedAt":"2024-03-01T00:00:00Z"}]}`), 0o644))
+	stackFilter, err = filter.New(filter.Options{Where: `age < duration("720h")`})
+	require.NoError(t, err)
+	doc, err = loadStacks(context.Background(), config.Config{InputPath: aged, Filter: stackFilter})
+	require.NoError(t, err)
+	assert.Len(t, doc.Stacks, 1)
+
+	_, err = loadStacks(context.Background(), config.Config{InputPath: filepath.Join(t.TempDir(), "missing.json")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load input")
+}
+
+func TestLoadStacks_InputMissingFields(t *testing.T) {
+	mockClient := &mockAWSClient{
+		stacks: []types.StackSummary{{StackName: aws.String("orders-api-prod"), StackId: aws.String("orders-api-prod-id")}},
+		resources: map[string][]types.StackResource{
+			"orders-api-prod": {{LogicalResourceId: aws.String("ServerlessDeploymentBucket"), ResourceType: aws.String("AWS::S3::Bucket")}},
+		},
+		details: map[string]*types.Stack{
+			"orders-api-prod": {StackName: aws.String("orders-api-prod"), EnableTerminationProtection: aws.Bool(true)},
+		},
+	}
+	doc, err := scanOutput(context.Background(), mockClient, config.Config{Region: "us-east-1", SchemaVersion: models.LatestSchemaVersion})
+	require.NoError(t, err)
+
+	save := func(fields []string) string {
+		formatter, err := newFormatter(config.Config{OutputFormat: "json", Fields: fields})
+		require.NoError(t, err)
+		saved, err := formatter.FormatDocument(*doc)
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "stacks.json")
+		require.NoError(t, os.WriteFile(path, []byte(saved), 0o644))
+		return path
+	}
+	rules, err := policy.ParsePolicy([]byte("rules:\n  - {id: prod-termination-protection, when: stage == \"prod\", condition: terminationProtection}"))
+	require.NoError(t, err)
+
+	// A report saved with the default fields has no termination protection to check
+	defaultSaved := save(nil)
+	saved, err := loadStacks(context.Background(), config.Config{InputPath: defaultSaved})
+	require.NoError(t, err)
+	report := policy.Evaluate(saved.Stacks, rules, scanTime(saved), saved.MissingFields)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, policy.StatusError, report.Results[0].Status)
+	assert.Equal(t, "failed to evaluate condition: field terminationProtection not in input; re-save with --fields terminationProtection", report.Results[0].Message)
+
+	stackFilter, err := filter.New(filter.Options{Where: "!terminationProtection"})
+	require.NoError(t, err)
+	_, err = loadStacks(context.Background(), config.Config{InputPath: defaultSaved, Filter: stackFilter})
+	assert.EqualError(t, err, "--where: field terminationProtection not in input; re-save with --fields terminationProtection")
+
+	// Saved with the field, the rule is evaluated
+	saved, err = loadStacks(context.Background(), config.Config{InputPath: save([]string{"terminationProtection"})})
+	require.NoError(t, err)
+	report = policy.Evaluate(saved.Stacks, rules, scanTime(saved), saved.MissingFields)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, policy.StatusPass, report.Results[0].Status)
+}
@@ -1,28 +1,75 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/hassaku63/find-serverless-stacks/internal/aws"
 	"github.com/hassaku63/find-serverless-stacks/internal/config"
 	"github.com/hassaku63/find-serverless-stacks/internal/detector"
+	"github.com/hassaku63/find-serverless-stacks/internal/expr"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
+	"github.com/hassaku63/find-serverless-stacks/internal/history"
+	"github.com/hassaku63/find-serverless-stacks/internal/iampolicy"
 	"github.com/hassaku63/find-serverless-stacks/internal/models"
 	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/hassaku63/find-serverless-stacks/internal/query"
+	"github.com/hassaku63/find-serverless-stacks/internal/summary"
 	"github.com/spf13/cobra"
 )
 
+// Build information, set with -ldflags at release time
 var (
-	profile      string
-	region       string
-	outputFormat string
+	version   = "dev"
+	buildTime = "unknown"
+	gitCommit = "unknown"
+)
+
+var (
+	profile       string
+	region        string
+	outputFormat  string
+	templateText  string
+	templateFile  string
+	queryExpr     string
+	columns       []string
+	fieldNames    []string
+	inputPath     string
+	sortBy        string
+	reverseSort   bool
+	showSummary   bool
+	withSummary   bool
+	schemaVersion = models.DefaultSchemaVersion
+
+	// Filter parameters
+	filterOpts filter.Options
 
 	// AssumeRole parameters
 	assumeRole  string
 	sessionName string
 	duration    int32
 	externalID  string
+
+	// AssumeRole session parameters
+	sourceIdentity        string
+	sessionTags           sessionTagsFlag
+	transitiveTagKeys     []string
+	sessionPolicyFile     string
+	readOnlySessionPolicy bool
+
+	// Credential cache parameters
+	cacheCredentials bool
+
+	// Scan history parameters
+	recordHistory bool
+	historyDB     string
 )
 
 func main() {
@@ -31,35 +78,437 @@ func main() {
 		Short: "Find CloudFormation stacks deployed by Serverless Framework",
 		Long: `find_serverless_stacks identifies CloudFormation stacks deployed by Serverless Framework
 by detecting the presence of ServerlessDeploymentBucket resources.`,
-		RunE: runCommand,
+		RunE:    runCommand,
+		Version: fmt.Sprintf("%s (commit %s, built %s)", version, gitCommit, buildTime),
 	}
 
-	rootCmd.Flags().StringVarP(&profile, "profile", "p", "default", "AWS profile name")
-	rootCmd.Flags().StringVarP(&region, "region", "r", "", "AWS region name (required)")
-	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "Output format (json, tsv)")
+	addAWSFlags(rootCmd)
+	addInputFlag(rootCmd)
+	addProfileScanFlags(rootCmd)
+	addOutputFlags(rootCmd)
+	rootCmd.Flags().BoolVar(&showSummary, "summary", false, "Print aggregate statistics instead of the stacks (json or table output)")
+	rootCmd.Flags().BoolVar(&withSummary, "with-summary", false, "Print the stacks, then aggregate statistics as a table on stderr")
+	addFilterFlags(rootCmd)
+	rootCmd.Flags().BoolVar(&recordHistory, "record-history", false, "Record the scan in the local history database")
+	rootCmd.Flags().StringVar(&historyDB, "history-db", "", "Path to the history database (default ~/.find-serverless-stacks/history.db)")
 
-	// AssumeRole flags
-	rootCmd.Flags().StringVar(&assumeRole, "assume-role", "", "ARN of the IAM role to assume")
-	rootCmd.Flags().StringVar(&sessionName, "session-name", "find-serverless-stacks-session", "Session name for the assumed role session")
-	rootCmd.Flags().Int32Var(&duration, "duration", 3600, "Session duration in seconds (900-43200)")
-	rootCmd.Flags().StringVar(&externalID, "external-id", "", "External ID for AssumeRole (required by some roles for security)")
+	rootCmd.MarkFlagsMutuallyExclusive("summary", "with-summary")
+	rootCmd.MarkFlagsMutuallyExclusive("input", "record-history")
 
-	rootCmd.MarkFlagRequired("region")
+	rootCmd.AddCommand(newDiffCommand())
+	rootCmd.AddCommand(newHistoryCommand())
+	rootCmd.AddCommand(newSchemaCommand())
+	rootCmd.AddCommand(newStaleCommand())
+	rootCmd.AddCommand(newDeleteCommand())
+	rootCmd.AddCommand(newCheckCommand())
+	rootCmd.AddCommand(newPolicyCommand())
+	rootCmd.AddCommand(newDoctorCommand())
+	rootCmd.AddCommand(newIAMPolicyCommand())
+	rootCmd.AddCommand(newCredentialsCommand())
 
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			if exitErr.err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", exitErr.err)
+			}
+			os.Exit(exitErr.code)
+		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		code, hint := errorStatus(err)
+		if hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
+		os.Exit(code)
+	}
+}
+
+// errorStatus returns the exit code of a failed command and a hint for fixing it.
+// AWS errors exit with the documented code of their type, everything else with 1.
+func errorStatus(err error) (int, string) {
+	var awsErr *aws.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Type.ExitCode(), awsErr.Hint()
+	}
+	return 1, ""
+}
+
+// addAWSFlags registers the AWS connection flags shared by commands that scan an account
+func addAWSFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&profile, "profile", "p", "default", "AWS profile name")
+	cmd.Flags().StringVarP(&region, "region", "r", "", "AWS region name (required when scanning)")
+
+	// AssumeRole flags
+	cmd.Flags().StringVar(&assumeRole, "assume-role", "", "ARN of the IAM role to assume")
+	cmd.Flags().StringVar(&sessionName, "session-name", "find-serverless-stacks-session", "Session name for the assumed role session")
+	cmd.Flags().Int32Var(&duration, "duration", 3600, "Session duration in seconds (900-43200)")
+	cmd.Flags().StringVar(&externalID, "external-id", "", "External ID for AssumeRole (required by some roles for security)")
+	cmd.Flags().StringVar(&sourceIdentity, "source-identity", "", "Source identity of the assumed role session, recorded in CloudTrail")
+	cmd.Flags().Var(&sessionTags, "session-tag", "Tag of the assumed role session: key=value (repeatable)")
+	cmd.Flags().StringSliceVar(&transitiveTagKeys, "transitive-tag-key", nil, "Comma-separated session tag keys passed on to roles assumed by the session")
+	cmd.Flags().StringVar(&sessionPolicyFile, "session-policy-file", "", "JSON session policy file limiting the permissions of the assumed role session")
+	cmd.Flags().BoolVar(&readOnlySessionPolicy, "read-only-session-policy", false, "Limit the assumed role session to the read actions of a scan")
+	cmd.MarkFlagsMutuallyExclusive("session-policy-file", "read-only-session-policy")
+	cmd.Flags().BoolVar(&cacheCredentials, "cache-credentials", false, "Reuse --assume-role credentials across runs, cached in ~/.find-serverless-stacks/cache/credentials")
+}
+
+// addOutputFlags registers the flags that format a stacks output document, shared
+// by the commands that print one
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "Output format ("+supportedFormats()+")")
+	cmd.Flags().StringVar(&templateText, "template", "", "Go template for --output template, executed over the stacks output")
+	cmd.Flags().StringVar(&templateFile, "template-file", "", "File containing the Go template for --output template")
+	cmd.Flags().StringVar(&queryExpr, "query", "", "JMESPath expression applied to the stacks output before formatting")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil, "Comma-separated fields for tsv, csv, markdown and table output (e.g. stackName,region,stackTags.Owner)")
+	cmd.Flags().StringSliceVar(&fieldNames, "fields", nil, "Comma-separated optional fields to include, or 'all' ("+strings.Join(models.OptionalFields, ", ")+")")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "Sort by a field such as createdAt or stackTags.Owner (default: region, then stackName)")
+	cmd.Flags().BoolVar(&reverseSort, "reverse", false, "Reverse the sort order")
+	cmd.Flags().IntVar(&schemaVersion, "schema-version", models.DefaultSchemaVersion, fmt.Sprintf("Output schema version (1-%d); version 2 reports unknown timestamps as null", models.LatestSchemaVersion))
+	cmd.MarkFlagsMutuallyExclusive("template", "template-file")
+}
+
+// validateOutputConfig checks the output format and schema version of a stacks output document
+func validateOutputConfig(cfg config.Config) error {
+	if !config.ValidateOutputFormat(cfg.OutputFormat) {
+		return fmt.Errorf("invalid output format '%s'. Supported formats: %s", cfg.OutputFormat, supportedFormats())
+	}
+
+	if cfg.SchemaVersion < 1 || cfg.SchemaVersion > models.LatestSchemaVersion {
+		return fmt.Errorf("invalid schema version %d. Supported versions: 1-%d", cfg.SchemaVersion, models.LatestSchemaVersion)
+	}
+	return nil
+}
+
+// sessionTagsFlag collects the --session-tag values, rejecting malformed tags while the flags are parsed
+type sessionTagsFlag []config.SessionTag
+
+func (f *sessionTagsFlag) String() string {
+	tags := make([]string, len(*f))
+	for i, tag := range *f {
+		tags[i] = tag.Key + "=" + tag.Value
+	}
+	return "[" + strings.Join(tags, ",") + "]"
+}
+
+func (f *sessionTagsFlag) Set(value string) error {
+	tag, err := config.ParseSessionTag(value)
+	if err != nil {
+		return err
 	}
+	*f = append(*f, tag)
+	return nil
+}
+
+func (f *sessionTagsFlag) Type() string {
+	return "key=value"
+}
+
+// addInputFlag registers the flag that reads a saved JSON output instead of scanning
+func addInputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&inputPath, "input", "", "Read a previously saved JSON output instead of scanning (- for stdin)")
+}
+
+// addFilterFlags registers the flags that restrict the stacks by name, tags and timestamps
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&filterOpts.NameGlob, "name-glob", "", "Only include stacks whose name matches the glob (e.g. 'orders-*')")
+	cmd.Flags().StringVar(&filterOpts.NameRegex, "name-regex", "", "Only include stacks whose name matches the regular expression")
+	cmd.Flags().StringArrayVar(&filterOpts.Tags, "tag", nil, "Only include stacks with the tag: key=value, key!=value, key or !key (repeatable)")
+	cmd.Flags().StringVar(&filterOpts.CreatedBefore, "created-before", "", "Only include stacks created before the date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&filterOpts.CreatedAfter, "created-after", "", "Only include stacks created at or after the date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&filterOpts.UpdatedBefore, "updated-before", "", "Only include stacks last updated before the date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&filterOpts.UpdatedAfter, "updated-after", "", "Only include stacks last updated at or after the date (YYYY-MM-DD or RFC 3339)")
+	cmd.Flags().StringVar(&filterOpts.Where, "where", "", "Only include stacks matching the CEL expression (e.g. 'stage == \"dev\" && age > duration(\"720h\")')")
 }
 
 func runCommand(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Create configuration
+	cfg := newConfig()
+
+	// Validate configuration
+	if err := validateOutputConfig(cfg); err != nil {
+		return err
+	}
+
+	if err := prepareSource(&cfg); err != nil {
+		return err
+	}
+
+	// A filtered snapshot would make history queries report stacks as removed
+	if cfg.Filter != nil && cfg.HistoryPath != "" {
+		return fmt.Errorf("--record-history cannot be combined with stack filters")
+	}
+
+	if cfg.Summary {
+		return runSummary(ctx, cfg)
+	}
+
+	// Create the formatter before scanning so template, query and field errors are reported immediately
+	formatter, err := newFormatter(cfg)
+	if err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Output results
+	result, err := formatter.FormatDocument(*doc)
+	if err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	fmt.Print(result)
+
+	if cfg.WithSummary {
+		return writeSummary(os.Stderr, doc)
+	}
+	return nil
+}
+
+// writeSummary writes the statistics of the stacks as a table after the stack
+// list; stderr keeps the list on stdout a valid document in every format
+func writeSummary(w io.Writer, doc *models.StacksOutput) error {
+	report := summary.Compute(doc.Stacks, doc.Scan, time.Now().UTC())
+	result, err := (&summary.TableFormatter{}).Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format summary: %w", err)
+	}
+	fmt.Fprintf(w, "\n%s\n", result)
+	return nil
+}
+
+// prepareSource validates where the stacks are read from and sets up the stack filter
+func prepareSource(cfg *config.Config) error {
+	if cfg.InputPath == "" {
+		if err := validateAWSConfig(*cfg); err != nil {
+			return err
+		}
+	}
+
+	stackFilter, err := filter.New(filterOpts)
+	if err != nil {
+		return err
+	}
+	cfg.Filter = stackFilter
+	return nil
+}
+
+// runSummary prints aggregate statistics of the stacks instead of the stacks themselves
+func runSummary(ctx context.Context, cfg config.Config) error {
+	formatter, err := newSummaryFormatter(cfg)
+	if err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	report := summary.Compute(doc.Stacks, doc.Scan, time.Now().UTC())
+	result, err := formatter.Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format summary: %w", err)
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// newSummaryFormatter creates the summary formatter, rejecting options that only apply to the stack list
+func newSummaryFormatter(cfg config.Config) (summary.Formatter, error) {
+	if cfg.Query != "" || len(cfg.Columns) > 0 || cfg.Template != "" || cfg.TemplateFile != "" {
+		return nil, fmt.Errorf("--summary cannot be combined with --query, --columns or templates")
+	}
+
+	formatter, err := summary.FormatterFactory(cfg.OutputFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --output for --summary: %w", err)
+	}
+	return formatter, nil
+}
+
+// loadStacks reads the saved input file, or scans AWS and records the scan if configured
+func loadStacks(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+	if cfg.InputPath != "" {
+		saved, err := models.LoadStacksOutput(cfg.InputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load input: %w", err)
+		}
+		if cfg.Filter != nil {
+			// Ages of saved stacks are measured from when they were scanned
+			cfg.Filter.AsOf = scanTime(saved)
+			// A missing field would read as its zero value and silently change the result
+			if cfg.Filter.Where != nil {
+				if fields := cfg.Filter.Where.Reads(saved.MissingFields); len(fields) > 0 {
+					return nil, fmt.Errorf("--where: %w", &expr.MissingFieldsError{Fields: fields})
+				}
+			}
+		}
+		saved.Stacks = cfg.Filter.Apply(saved.Stacks)
+		// Saved documents are normalized when read, so they can be written in any version
+		saved.SchemaVersion = cfg.SchemaVersion
+		return saved, nil
+	}
+
+	if cfg.ProfileGlob != "" {
+		return scanProfiles(ctx, cfg)
+	}
+
+	// Create AWS client
+	client, err := createAWSClient(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
+	}
+
+	// Run detection
+	doc, err := scanOutput(ctx, client, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+
+	// Identify the account; the scan is still usable without it, so a failure is only reported
+	identity, identityErr := lookupCallerIdentity(ctx, cfg)
+	if identityErr != nil {
+		doc.Scan.Errors = append(doc.Scan.Errors, models.ScanError{
+			Region:    cfg.Region,
+			Operation: "GetCallerIdentity",
+			Message:   identityErr.Error(),
+		})
+	} else {
+		doc.Scan.AccountID = identity.Account
+		doc.Scan.CallerARN = identity.ARN
+	}
+
+	// Record the scan before output so a formatting error does not lose it
+	if cfg.HistoryPath != "" {
+		if identityErr != nil {
+			return nil, fmt.Errorf("failed to record scan history: %w", identityErr)
+		}
+		if err := recordScan(cfg, identity.Account, doc.Stacks, doc.Scan.StartedAt); err != nil {
+			return nil, fmt.Errorf("failed to record scan history: %w", err)
+		}
+	}
+
+	return doc, nil
+}
+
+// scanProfiles scans the accounts of the profiles matching cfg.ProfileGlob and records
+// a snapshot of each scanned account if configured
+func scanProfiles(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+	doc, err := newProfileScanner().Scan(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.HistoryPath != "" {
+		for _, account := range doc.Scan.Accounts {
+			var stacks []models.Stack
+			for _, stack := range doc.Stacks {
+				if stack.AccountID == account.AccountID {
+					stacks = append(stacks, stack)
+				}
+			}
+			if err := recordScan(cfg, account.AccountID, stacks, doc.Scan.StartedAt); err != nil {
+				return nil, fmt.Errorf("failed to record scan history: %w", err)
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// scanOutput runs the detection and describes the scan in the output document
+func scanOutput(ctx context.Context, client detector.AWSClient, cfg config.Config) (*models.StacksOutput, error) {
+	startedAt := time.Now().UTC()
+
+	d := newDetector(client, cfg)
+	stacks, err := d.DetectServerlessStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect serverless stacks: %w", err)
+	}
+
+	scanned := d.Scanned()
+	scan := &models.ScanMetadata{
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt).Round(time.Millisecond).Seconds(),
+		Profile:       cfg.Profile,
+		Partition:     regionPartition(cfg.Region),
+		Regions:       []string{cfg.Region},
+		ToolVersion:   version,
+		RulesApplied:  d.RuleNames(),
+		StacksScanned: &scanned,
+		Errors:        []models.ScanError{},
+	}
+	if cfg.AssumeRole != nil {
+		scan.RoleARN = cfg.AssumeRole.RoleARN
+	}
+
+	for _, err := range d.Errors() {
+		scanErr := models.ScanError{Region: cfg.Region, Message: err.Error()}
+
+		var detectionErr *detector.DetectionError
+		if errors.As(err, &detectionErr) {
+			scanErr.StackName = detectionErr.StackName
+			scanErr.Operation = detectionErr.Operation
+			scanErr.Message = detectionErr.Cause.Error()
+		}
+		scan.Errors = append(scan.Errors, scanErr)
+	}
+
+	return &models.StacksOutput{
+		SchemaVersion: cfg.SchemaVersion,
+		Scan:          scan,
+		Stacks:        stacks,
+	}, nil
+}
+
+// regionPartition returns the ID of the partition of the region, or "" for an unknown region
+func regionPartition(region string) string {
+	partition, _ := aws.PartitionOfRegion(region)
+	return partition.ID
+}
+
+// lookupCallerIdentity returns the account and principal behind the configured credentials
+func lookupCallerIdentity(ctx context.Context, cfg config.Config) (*aws.CallerIdentity, error) {
+	auth, err := newAuthConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	stsClient, err := aws.CreateSTSClient(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.GetCallerIdentity(ctx, stsClient)
+}
+
+// newConfig builds the application configuration from the command-line flags
+func newConfig() config.Config {
 	cfg := config.Config{
 		Profile:      profile,
 		Region:       region,
+		ProfileGlob:  profileGlob,
 		OutputFormat: outputFormat,
+		Template:     templateText,
+		TemplateFile: templateFile,
+		Query:        queryExpr,
+		Columns:      columns,
+		Fields:       fieldNames,
+		InputPath:    inputPath,
+		SortBy:       sortBy,
+		Reverse:      reverseSort,
+		Summary:      showSummary,
+		WithSummary:  withSummary,
+
+		SchemaVersion: schemaVersion,
+	}
+
+	if allProfiles {
+		cfg.ProfileGlob = "*"
 	}
 
 	// Add AssumeRole configuration if specified
@@ -69,17 +518,74 @@ func runCommand(cmd *cobra.Command, args []string) error {
 			SessionName: sessionName,
 			Duration:    duration,
 			ExternalID:  externalID,
+
+			SourceIdentity:    sourceIdentity,
+			SessionTags:       sessionTags,
+			TransitiveTagKeys: transitiveTagKeys,
+			PolicyFile:        sessionPolicyFile,
+			ReadOnlyPolicy:    readOnlySessionPolicy,
 		}
 	}
 
-	// Validate configuration
-	if !config.ValidateOutputFormat(cfg.OutputFormat) {
-		return fmt.Errorf("invalid output format '%s'. Supported formats: json, tsv", cfg.OutputFormat)
+	cfg.SessionFlags = setSessionFlags()
+
+	// Cache assumed-role credentials if requested
+	if cacheCredentials {
+		// Caching is skipped if no default is available
+		cfg.CredentialCacheDir, _ = aws.DefaultCredentialCacheDir()
+	}
+
+	// Enable history recording if requested
+	if recordHistory {
+		cfg.HistoryPath = historyDB
+		if cfg.HistoryPath == "" {
+			// Recording fails later with a clear error if no default is available
+			cfg.HistoryPath, _ = history.DefaultPath()
+		}
+	}
+
+	return cfg
+}
+
+// setSessionFlags returns the assumed role session flags that were set
+func setSessionFlags() []string {
+	var set []string
+	if sourceIdentity != "" {
+		set = append(set, "--source-identity")
 	}
+	if len(sessionTags) > 0 {
+		set = append(set, "--session-tag")
+	}
+	if len(transitiveTagKeys) > 0 {
+		set = append(set, "--transitive-tag-key")
+	}
+	if sessionPolicyFile != "" {
+		set = append(set, "--session-policy-file")
+	}
+	if readOnlySessionPolicy {
+		set = append(set, "--read-only-session-policy")
+	}
+	return set
+}
 
+// validateAWSConfig validates the settings needed to connect to AWS
+func validateAWSConfig(cfg config.Config) error {
 	if cfg.Region == "" {
 		return fmt.Errorf("region is required")
 	}
+	if err := aws.ValidateRegion(cfg.Region); err != nil {
+		return err
+	}
+
+	// Every profile would assume the same role and scan the same account
+	if cfg.ProfileGlob != "" && cfg.AssumeRole != nil {
+		return fmt.Errorf("--assume-role cannot be combined with --all-profiles or --profile-glob")
+	}
+
+	// Session flags would otherwise be silently ignored
+	if cfg.AssumeRole == nil && len(cfg.SessionFlags) > 0 {
+		return fmt.Errorf("%s requires --assume-role", cfg.SessionFlags[0])
+	}
 
 	// Validate AssumeRole configuration if present
 	if cfg.AssumeRole != nil {
@@ -88,28 +594,35 @@ func runCommand(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Create AWS client
+	return nil
+}
+
+// scanStacks connects to AWS and returns the detected Serverless Framework stacks
+func scanStacks(ctx context.Context, cfg config.Config) ([]models.Stack, error) {
+	if err := validateAWSConfig(cfg); err != nil {
+		return nil, err
+	}
+
 	client, err := createAWSClient(ctx, cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create AWS client: %w", err)
+		return nil, fmt.Errorf("failed to create AWS client: %w", err)
 	}
 
-	// Run detection
-	result, err := runDetection(ctx, client, cfg)
+	stacks, err := detectStacks(ctx, client, cfg)
 	if err != nil {
-		return fmt.Errorf("detection failed: %w", err)
+		return nil, fmt.Errorf("detection failed: %w", err)
 	}
 
-	// Output results
-	fmt.Print(result)
-	return nil
+	return stacks, nil
 }
 
-// createAWSClient creates and configures an AWS client
-func createAWSClient(ctx context.Context, cfg config.Config) (detector.AWSClient, error) {
+// newAuthConfig converts the application configuration to AWS authentication settings
+func newAuthConfig(cfg config.Config) (aws.AuthConfig, error) {
 	auth := aws.AuthConfig{
-		Profile: cfg.Profile,
-		Region:  cfg.Region,
+		Profile:            cfg.Profile,
+		ExplicitProfile:    cfg.ExplicitProfile,
+		Region:             cfg.Region,
+		CredentialCacheDir: cfg.CredentialCacheDir,
 	}
 
 	// Add AssumeRole configuration if present
@@ -119,7 +632,62 @@ func createAWSClient(ctx context.Context, cfg config.Config) (detector.AWSClient
 			SessionName: cfg.AssumeRole.SessionName,
 			Duration:    cfg.AssumeRole.Duration,
 			ExternalID:  cfg.AssumeRole.ExternalID,
+
+			SourceIdentity:    cfg.AssumeRole.SourceIdentity,
+			TransitiveTagKeys: cfg.AssumeRole.TransitiveTagKeys,
+		}
+		for _, tag := range cfg.AssumeRole.SessionTags {
+			auth.AssumeRole.Tags = append(auth.AssumeRole.Tags, aws.SessionTag{Key: tag.Key, Value: tag.Value})
+		}
+
+		policy, err := sessionPolicy(cfg.AssumeRole)
+		if err != nil {
+			return aws.AuthConfig{}, err
+		}
+		auth.AssumeRole.Policy = policy
+	}
+
+	return auth, nil
+}
+
+// maxSessionPolicyLength is the longest session policy STS accepts, in characters
+const maxSessionPolicyLength = 2048
+
+// sessionPolicy returns the compact JSON session policy of the AssumeRole configuration, or "" for none
+func sessionPolicy(arc *config.AssumeRoleConfig) (string, error) {
+	if arc.ReadOnlyPolicy {
+		// The policy ARNs use the partition of the role
+		partition, err := aws.PartitionOfARN(arc.RoleARN)
+		if err != nil {
+			return "", err
 		}
+		return iampolicy.ReadOnlySessionPolicy(partition.ID)
+	}
+
+	if arc.PolicyFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(arc.PolicyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read session policy: %w", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return "", fmt.Errorf("session policy %s is not valid JSON: %w", arc.PolicyFile, err)
+	}
+	if compact.Len() > maxSessionPolicyLength {
+		return "", fmt.Errorf("session policy %s is %d characters without whitespace; STS accepts at most %d", arc.PolicyFile, compact.Len(), maxSessionPolicyLength)
+	}
+	return compact.String(), nil
+}
+
+// createAWSClient creates and configures an AWS client
+func createAWSClient(ctx context.Context, cfg config.Config) (detector.AWSClient, error) {
+	auth, err := newAuthConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create AWS client
@@ -131,25 +699,98 @@ func createAWSClient(ctx context.Context, cfg config.Config) (detector.AWSClient
 	// Validate credentials by attempting a simple operation
 	_, err = client.ListActiveStacks(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("AWS credentials validation failed: %w", err)
+		return nil, fmt.Errorf("AWS credentials validation failed (run 'find_serverless_stacks doctor' to diagnose): %w", aws.ClassifyError(err, cfg.Region))
 	}
 
 	return client, nil
 }
 
-// runDetection executes the serverless stack detection
-func runDetection(ctx context.Context, client detector.AWSClient, cfg config.Config) (string, error) {
-	// Create detector
+// detectStacks executes the serverless stack detection
+func detectStacks(ctx context.Context, client detector.AWSClient, cfg config.Config) ([]models.Stack, error) {
+	// Detect serverless stacks
+	stacks, err := newDetector(client, cfg).DetectServerlessStacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect serverless stacks: %w", err)
+	}
+
+	return stacks, nil
+}
+
+// newDetector creates a detector for the configured region and filter
+func newDetector(client detector.AWSClient, cfg config.Config) *detector.Detector {
 	d := detector.NewDetector(client, cfg.Region)
+	d.SetFilter(cfg.Filter)
+	return d
+}
 
-	// Detect serverless stacks
-	stacks, err := d.DetectServerlessStacks(ctx)
+// supportedFormats lists the output formats for help and error messages
+func supportedFormats() string {
+	return strings.Join(output.SupportedFormats(), ", ")
+}
+
+// newFormatter creates the output pipeline for the configured format: sorting, the query and the formatter
+func newFormatter(cfg config.Config) (*output.Pipeline, error) {
+	text := cfg.Template
+	if cfg.TemplateFile != "" {
+		data, err := os.ReadFile(cfg.TemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		text = string(data)
+	}
+
+	if text != "" && cfg.OutputFormat != "template" {
+		return nil, fmt.Errorf("--template and --template-file require --output template")
+	}
+
+	if len(cfg.Columns) > 0 {
+		if !isTabularFormat(cfg.OutputFormat) {
+			return nil, fmt.Errorf("--columns requires --output tsv, csv, markdown or table")
+		}
+		if err := output.ValidateFieldPaths(cfg.Columns); err != nil {
+			return nil, fmt.Errorf("invalid --columns: %w", err)
+		}
+	}
+
+	order := output.SortOrder{By: cfg.SortBy, Reverse: cfg.Reverse}
+	if err := order.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid --sort-by: %w", err)
+	}
+
+	var q *query.Query
+	if cfg.Query != "" {
+		var err error
+		q, err = query.Compile(cfg.Query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	formatter, err := output.NewFormatter(cfg.OutputFormat, output.Options{Template: text, Columns: cfg.Columns})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create formatter: %w", err)
+	}
+
+	// Fields used as columns, sort keys or in the query are included even when not selected
+	paths := append([]string{cfg.SortBy}, cfg.Columns...)
+	if q != nil {
+		paths = append(paths, q.Identifiers()...)
+	}
+	selected, err := output.ExpandOptionalFields(cfg.Fields, paths)
 	if err != nil {
-		return "", fmt.Errorf("failed to detect serverless stacks: %w", err)
+		return nil, fmt.Errorf("invalid --fields: %w", err)
 	}
 
-	// Format output
-	return formatOutput(stacks, cfg.OutputFormat)
+	return &output.Pipeline{Formatter: formatter, Order: order, Query: q, Fields: selected}, nil
+}
+
+// isTabularFormat reports whether the output format writes one row per stack
+func isTabularFormat(format string) bool {
+	switch format {
+	case "tsv", "csv", "markdown", "table":
+		return true
+	}
+	return false
 }
 
 // formatOutput formats the detected stacks using the specified formatter
@@ -159,5 +800,23 @@ func formatOutput(stacks []models.Stack, format string) (string, error) {
 		return "", fmt.Errorf("failed to create formatter: %w", err)
 	}
 
-	return formatter.Format(stacks)
+	return (&output.Pipeline{Formatter: formatter}).Format(stacks)
+}
+
+// exitError makes the process exit with a specific status code.
+// A nil err exits silently, for commands that have already reported their result.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
 }
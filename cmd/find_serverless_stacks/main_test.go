@@ -1,8 +1,15 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,12 +33,12 @@ func TestRunCommand_ValidationOnly(t *testing.T) {
 			expectedErrMsg: "invalid output format 'xml'",
 		},
 		{
-			name:           "invalid output format - yaml",
+			name:           "invalid output format - toml",
 			profile:        "test-profile",
 			region:         "us-east-1",
-			outputFormat:   "yaml",
+			outputFormat:   "toml",
 			expectError:    true,
-			expectedErrMsg: "invalid output format 'yaml'",
+			expectedErrMsg: "invalid output format 'toml'",
 		},
 		{
 			name:           "case sensitive format validation",
@@ -89,9 +96,10 @@ func TestOutputFormatValidation_ConfigOnly(t *testing.T) {
 	}{
 		{"json", true},
 		{"tsv", true},
+		{"csv", true},
+		{"yaml", true},
+		{"markdown", true},
 		{"xml", false},
-		{"csv", false},
-		{"yaml", false},
 		{"", false},
 		{"JSON", false}, // Case sensitive
 		{"TSV", false},  // Case sensitive
@@ -255,3 +263,182 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantHint bool
+	}{
+		{
+			name:     "aws error",
+			err:      fmt.Errorf("failed to create AWS client: %w", &aws.Error{Type: aws.ErrorTypeAssumeRole, Message: "not allowed to assume the IAM role"}),
+			wantCode: 14,
+			wantHint: true,
+		},
+		{
+			name:     "unknown aws error",
+			err:      &aws.Error{Type: aws.ErrorTypeUnknown, Message: "unexpected AWS API error"},
+			wantCode: 1,
+		},
+		{
+			name:     "other error",
+			err:      errors.New("invalid output format"),
+			wantCode: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, hint := errorStatus(tt.err)
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantHint, hint != "")
+		})
+	}
+}
+
+func TestSessionPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		arc     config.AssumeRoleConfig
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no policy",
+			arc:  config.AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/Scanner"},
+		},
+		{
+			name: "policy file is compacted",
+			arc: config.AssumeRoleConfig{PolicyFile: writePolicy("policy.json", `{
+  "Version": "2012-10-17",
+  "Statement": [{"Effect": "Allow", "Action": "cloudformation:ListStacks", "Resource": "*"}]
+}`)},
+			want: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"cloudformation:ListStacks","Resource":"*"}]}`,
+		},
+		{
+			name:    "invalid JSON",
+			arc:     config.AssumeRoleConfig{PolicyFile: writePolicy("invalid.json", `{"Version":`)},
+			wantErr: "is not valid JSON",
+		},
+		{
+			name:    "too long",
+			arc:     config.AssumeRoleConfig{PolicyFile: writePolicy("long.json", `{"Sid":"`+strings.Repeat("a", 2048)+`"}`)},
+			wantErr: "STS accepts at most 2048",
+		},
+		{
+			name:    "missing file",
+			arc:     config.AssumeRoleConfig{PolicyFile: filepath.Join(dir, "missing.json")},
+			wantErr: "failed to read session policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sessionPolicy(&tt.arc)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSessionPolicy_ReadOnly(t *testing.T) {
+	got, err := sessionPolicy(&config.AssumeRoleConfig{RoleARN: "arn:aws-cn:iam::123456789012:role/Scanner", ReadOnlyPolicy: true})
+	require.NoError(t, err)
+	assert.Contains(t, got, "cloudformation:DescribeStackResources")
+	assert.Contains(t, got, "arn:aws-cn:cloudformation:")
+	assert.NotContains(t, got, "DeleteStack")
+}
+
+func TestSessionTagsFlag(t *testing.T) {
+	var tags sessionTagsFlag
+	require.NoError(t, tags.Set("team=platform"))
+	require.NoError(t, tags.Set("purpose=audit=2026"))
+	assert.Equal(t, sessionTagsFlag{{Key: "team", Value: "platform"}, {Key: "purpose", Value: "audit=2026"}}, tags)
+	assert.Equal(t, "[team=platform,purpose=audit=2026]", tags.String())
+
+	assert.Error(t, tags.Set("team"))
+	assert.Len(t, tags, 2)
+}
+
+func TestValidateAWSConfig_Region(t *testing.T) {
+	tests := []struct {
+		region  string
+		wantErr bool
+	}{
+		{region: "us-east-1"},
+		{region: "cn-north-1"},
+		{region: "cn-northwest-1"},
+		{region: "us-gov-west-1"},
+		{region: "us-gov-east-1"},
+		{region: "us-east", wantErr: true},
+		{region: "moon-base-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			err := validateAWSConfig(config.Config{Region: tt.region})
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			code, _ := errorStatus(err)
+			assert.Equal(t, aws.ErrorTypeInvalidRegion.ExitCode(), code)
+		})
+	}
+}
+
+func TestValidateAWSConfig_SessionFlags(t *testing.T) {
+	tests := []struct {
+		flags   []string
+		wantErr string
+	}{
+		{flags: []string{"--source-identity"}, wantErr: "--source-identity requires --assume-role"},
+		{flags: []string{"--session-tag", "--transitive-tag-key"}, wantErr: "--session-tag requires --assume-role"},
+		{flags: []string{"--session-policy-file"}, wantErr: "--session-policy-file requires --assume-role"},
+		{flags: []string{"--read-only-session-policy"}, wantErr: "--read-only-session-policy requires --assume-role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.flags[0], func(t *testing.T) {
+			err := validateAWSConfig(config.Config{Region: "us-east-1", SessionFlags: tt.flags})
+			assert.EqualError(t, err, tt.wantErr)
+
+			cfg := config.Config{
+				Region:       "us-east-1",
+				AssumeRole:   &config.AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/Scanner", SessionName: "scan", Duration: 3600},
+				SessionFlags: tt.flags,
+			}
+			assert.NoError(t, validateAWSConfig(cfg))
+		})
+	}
+}
+
+func TestNewConfig_SessionFlags(t *testing.T) {
+	t.Cleanup(func() {
+		sourceIdentity = ""
+		readOnlySessionPolicy = false
+	})
+
+	assert.Empty(t, newConfig().SessionFlags)
+
+	sourceIdentity = "alice"
+	readOnlySessionPolicy = true
+	cfg := newConfig()
+	assert.Nil(t, cfg.AssumeRole)
+	assert.Equal(t, []string{"--source-identity", "--read-only-session-policy"}, cfg.SessionFlags)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// failOnNone disables the non-zero exit status of policy
+const failOnNone = "none"
+
+var (
+	policyFormat string
+	policyFailOn string
+)
+
+// newPolicyCommand creates the policy subcommand
+func newPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy <policy.yaml>",
+		Short: "Evaluate policy rules against detected stacks",
+		Long: `policy evaluates the rules of a policy file against every detected stack and
+reports which stacks pass or fail each rule.
+
+Rules are CEL expressions over the stack. A rule applies to the stacks matching its
+optional "when" expression, and a stack passes when its "condition" is true:
+
+  rules:
+    - id: prod-termination-protection
+      description: Production stacks must have termination protection
+      severity: error
+      when: stage == "prod"
+      condition: terminationProtection
+    - id: approved-regions
+      condition: region in ["us-east-1", "eu-west-1"]
+
+All expressions are compiled and type-checked before the scan. The command exits
+with status 2 when a rule at least as severe as --fail-on fails or cannot be evaluated.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPolicy,
+	}
+
+	addAWSFlags(cmd)
+	addInputFlag(cmd)
+	addProfileScanFlags(cmd)
+	addFilterFlags(cmd)
+	cmd.Flags().StringVarP(&policyFormat, "output", "o", "table", "Output format (table, json, sarif)")
+	cmd.Flags().StringVar(&policyFailOn, "fail-on", policy.SeverityError, "Lowest severity that makes the command fail (error, warning, note, none)")
+
+	return cmd
+}
+
+func runPolicy(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	formatter, err := policy.FormatterFactory(policyFormat)
+	if err != nil {
+		return err
+	}
+
+	if policyFailOn != failOnNone {
+		if err := policy.ValidateSeverity(policyFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+
+	rules, err := policy.LoadPolicy(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig()
+	if err := prepareSource(&cfg); err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	report := policy.Evaluate(doc.Stacks, rules, scanTime(doc), doc.MissingFields)
+	result, err := formatter.Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format policy report: %w", err)
+	}
+	fmt.Println(result)
+
+	if policyFailOn != failOnNone && report.Violations(policyFailOn) > 0 {
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitError{code: exitCodeViolations}
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	allProfiles bool
+	profileGlob string
+)
+
+// maxProfileLookups bounds the concurrent GetCallerIdentity calls that resolve profiles to accounts
+const maxProfileLookups = 10
+
+// addProfileScanFlags registers the flags that scan the accounts of several profiles;
+// it must be called after addAWSFlags and addInputFlag
+func addProfileScanFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&allProfiles, "all-profiles", false, "Scan the account of every profile in the shared config and credentials files")
+	cmd.Flags().StringVar(&profileGlob, "profile-glob", "", "Scan the account of every profile matching the glob (e.g. 'prod-*')")
+
+	cmd.MarkFlagsMutuallyExclusive("profile", "all-profiles", "profile-glob")
+	cmd.MarkFlagsMutuallyExclusive("input", "all-profiles")
+	cmd.MarkFlagsMutuallyExclusive("input", "profile-glob")
+}
+
+// accountTarget is a distinct account behind the selected profiles
+type accountTarget struct {
+	identity aws.CallerIdentity
+	// profiles resolve to the account, in name order; the first that can scan it is used
+	profiles []string
+}
+
+// profileScanner scans each distinct account behind the profiles matching a glob once
+type profileScanner struct {
+	listProfiles func(glob string) ([]string, error)
+	identify     func(ctx context.Context, cfg config.Config) (*aws.CallerIdentity, error)
+	scan         func(ctx context.Context, cfg config.Config) (*models.StacksOutput, error)
+}
+
+// newProfileScanner creates a scanner backed by the shared config files and AWS
+func newProfileScanner() *profileScanner {
+	return &profileScanner{
+		listProfiles: aws.ListProfiles,
+		identify:     lookupCallerIdentity,
+		scan:         scanAccount,
+	}
+}
+
+// scanAccount scans the account of cfg.Profile
+func scanAccount(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+	client, err := createAWSClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return scanOutput(ctx, client, cfg)
+}
+
+// Scan resolves the profiles matching cfg.ProfileGlob to accounts and scans each
+// account once, attributing the stacks to their account and profile. Profiles and
+// accounts that fail are reported as scan errors; the scan fails only when no
+// profile can be resolved.
+func (s *profileScanner) Scan(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+	startedAt := time.Now().UTC()
+
+	profiles, err := s.listProfiles(cfg.ProfileGlob)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("no profiles in the shared config files match %q", cfg.ProfileGlob)
+	}
+
+	accounts, resolveErrs := s.resolveAccounts(ctx, cfg, profiles)
+	if len(accounts) == 0 {
+		// Every profile failed, so the first one has an error
+		return nil, fmt.Errorf("failed to resolve any of the %d profiles matching %q; profile %s: %w",
+			len(profiles), cfg.ProfileGlob, profiles[0], resolveErrs[0])
+	}
+
+	scan := &models.ScanMetadata{
+		StartedAt:    startedAt,
+		Partition:    regionPartition(cfg.Region),
+		Regions:      []string{cfg.Region},
+		ToolVersion:  version,
+		RulesApplied: []string{},
+		Errors:       []models.ScanError{},
+	}
+	for i, err := range resolveErrs {
+		if err != nil {
+			scan.Errors = append(scan.Errors, models.ScanError{
+				Profile:   profiles[i],
+				Region:    cfg.Region,
+				Operation: "GetCallerIdentity",
+				Message:   err.Error(),
+			})
+		}
+	}
+
+	stacks := []models.Stack{}
+	scanned := 0
+	for _, account := range accounts {
+		doc, profile, scanErrs := s.scanTarget(ctx, cfg, account)
+		if doc == nil {
+			scan.Errors = append(scan.Errors, scanErrs...)
+			continue
+		}
+
+		for _, stack := range doc.Stacks {
+			stack.AccountID = account.identity.Account
+			stack.Profile = profile
+			stacks = append(stacks, stack)
+		}
+		for _, scanErr := range doc.Scan.Errors {
+			scanErr.Profile = profile
+			scan.Errors = append(scan.Errors, scanErr)
+		}
+		scan.RulesApplied = doc.Scan.RulesApplied
+		if doc.Scan.StacksScanned != nil {
+			scanned += *doc.Scan.StacksScanned
+		}
+		scan.Accounts = append(scan.Accounts, models.AccountScan{
+			AccountID: account.identity.Account,
+			CallerARN: account.identity.ARN,
+			Profile:   profile,
+			Profiles:  account.profiles,
+		})
+	}
+	scan.StacksScanned = &scanned
+	scan.Duration = time.Since(startedAt).Round(time.Millisecond).Seconds()
+
+	return &models.StacksOutput{
+		SchemaVersion: cfg.SchemaVersion,
+		Scan:          scan,
+		Stacks:        stacks,
+	}, nil
+}
+
+// scanTarget scans an account with the first of its profiles that succeeds and
+// returns the profile used. When every profile fails, the document is nil and
+// the errors hold the failure of each profile.
+func (s *profileScanner) scanTarget(ctx context.Context, cfg config.Config, account accountTarget) (*models.StacksOutput, string, []models.ScanError) {
+	var scanErrs []models.ScanError
+	for _, profile := range account.profiles {
+		doc, err := s.scan(ctx, profileConfig(cfg, profile))
+		if err == nil {
+			return doc, profile, nil
+		}
+		scanErrs = append(scanErrs, models.ScanError{
+			Profile:   profile,
+			Region:    cfg.Region,
+			Operation: "ListStacks",
+			Message:   err.Error(),
+		})
+	}
+	return nil, "", scanErrs
+}
+
+// profileConfig returns the configuration of a single enumerated profile. The
+// profile is always read from the shared config files, so a "default" profile
+// is not replaced by AWS_PROFILE or environment credentials.
+func profileConfig(cfg config.Config, profile string) config.Config {
+	cfg.Profile = profile
+	cfg.ExplicitProfile = true
+	cfg.ProfileGlob = ""
+	return cfg
+}
+
+// resolveAccounts looks up the account of every profile concurrently and groups the
+// profiles by account. The errors are indexed like profiles, nil for resolved ones.
+func (s *profileScanner) resolveAccounts(ctx context.Context, cfg config.Config, profiles []string) ([]accountTarget, []error) {
+	identities := make([]*aws.CallerIdentity, len(profiles))
+	errs := make([]error, len(profiles))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxProfileLookups)
+	for i, profile := range profiles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			identities[i], errs[i] = s.identify(ctx, profileConfig(cfg, profile))
+		}()
+	}
+	wg.Wait()
+
+	var accounts []accountTarget
+	index := make(map[string]int)
+	for i, profile := range profiles {
+		if errs[i] != nil {
+			continue
+		}
+		account := identities[i].Account
+		if j, ok := index[account]; ok {
+			accounts[j].profiles = append(accounts[j].profiles, profile)
+			continue
+		}
+		index[account] = len(accounts)
+		accounts = append(accounts, accountTarget{identity: *identities[i], profiles: []string{profile}})
+	}
+	return accounts, errs
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProfileScanner returns a scanner over fixed profiles, accounts and scan results
+func fakeProfileScanner(profiles []string, accounts map[string]string, scanned *[]string) *profileScanner {
+	var mu sync.Mutex
+	stacksScanned := 3
+	return &profileScanner{
+		listProfiles: func(glob string) ([]string, error) {
+			return profiles, nil
+		},
+		identify: func(ctx context.Context, cfg config.Config) (*aws.CallerIdentity, error) {
+			account, ok := accounts[cfg.Profile]
+			if !ok {
+				return nil, &aws.Error{Type: aws.ErrorTypeSSOExpired, Message: "the SSO session has expired"}
+			}
+			return &aws.CallerIdentity{Account: account, ARN: "arn:aws:sts::" + account + ":assumed-role/Dev/" + cfg.Profile}, nil
+		},
+		scan: func(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+			mu.Lock()
+			*scanned = append(*scanned, cfg.Profile)
+			mu.Unlock()
+
+			if cfg.Profile == "broken" {
+				return nil, errors.New("access denied")
+			}
+			return &models.StacksOutput{
+				Scan: &models.ScanMetadata{
+					RulesApplied:  []string{"deployment-bucket"},
+					StacksScanned: &stacksScanned,
+					Errors:        []models.ScanError{{Region: cfg.Region, StackName: "half", Operation: "DescribeStackResources", Message: "throttled"}},
+				},
+				Stacks: []models.Stack{{StackName: "api-" + cfg.Profile, Region: cfg.Region}},
+			}, nil
+		},
+	}
+}
+
+func TestProfileScanner_Scan(t *testing.T) {
+	var scanned []string
+	scanner := fakeProfileScanner(
+		[]string{"broken", "dev", "expired", "prod-admin", "prod-read"},
+		map[string]string{"broken": "333333333333", "dev": "111111111111", "prod-admin": "222222222222", "prod-read": "222222222222"},
+		&scanned,
+	)
+
+	doc, err := scanner.Scan(context.Background(), config.Config{Region: "us-east-1", ProfileGlob: "*", SchemaVersion: 2})
+	require.NoError(t, err)
+
+	// prod-read shares the account of prod-admin, so it is not scanned
+	assert.ElementsMatch(t, []string{"broken", "dev", "prod-admin"}, scanned)
+
+	assert.Equal(t, 2, doc.SchemaVersion)
+	assert.Equal(t, []models.Stack{
+		{StackName: "api-dev", Region: "us-east-1", AccountID: "111111111111", Profile: "dev"},
+		{StackName: "api-prod-admin", Region: "us-east-1", AccountID: "222222222222", Profile: "prod-admin"},
+	}, doc.Stacks)
+
+	assert.Equal(t, []models.AccountScan{
+		{AccountID: "111111111111", CallerARN: "arn:aws:sts::111111111111:assumed-role/Dev/dev", Profile: "dev", Profiles: []string{"dev"}},
+		{AccountID: "222222222222", CallerARN: "arn:aws:sts::222222222222:assumed-role/Dev/prod-admin", Profile: "prod-admin", Profiles: []string{"prod-admin", "prod-read"}},
+	}, doc.Scan.Accounts)
+	require.NotNil(t, doc.Scan.StacksScanned)
+	assert.Equal(t, 6, *doc.Scan.StacksScanned)
+	assert.Equal(t, []string{"deployment-bucket"}, doc.Scan.RulesApplied)
+	assert.Equal(t, []string{"us-east-1"}, doc.Scan.Regions)
+
+	var errs []string
+	for _, scanErr := range doc.Scan.Errors {
+		errs = append(errs, scanErr.Profile+" "+scanErr.Operation)
+	}
+	assert.Equal(t, []string{
+		"expired GetCallerIdentity",
+		"broken ListStacks",
+		"dev DescribeStackResources",
+		"prod-admin DescribeStackResources",
+	}, errs)
+}
+
+func TestProfileScanner_ScanFallback(t *testing.T) {
+	var scanned []string
+	scanner := fakeProfileScanner(
+		[]string{"broken", "default", "prod-read"},
+		map[string]string{"broken": "222222222222", "default": "111111111111", "prod-read": "222222222222"},
+		&scanned,
+	)
+	// Enumerated profiles are read from the shared config files, "default" included
+	scan := scanner.scan
+	scanner.scan = func(ctx context.Context, cfg config.Config) (*models.StacksOutput, error) {
+		assert.True(t, cfg.ExplicitProfile, cfg.Profile)
+		assert.Empty(t, cfg.ProfileGlob)
+		return scan(ctx, cfg)
+	}
+
+	doc, err := scanner.Scan(context.Background(), config.Config{Region: "us-east-1", ProfileGlob: "*"})
+	require.NoError(t, err)
+
+	// The account of broken is scanned with prod-read instead
+	assert.Equal(t, []string{"broken", "prod-read", "default"}, scanned)
+	assert.Equal(t, []models.Stack{
+		{StackName: "api-prod-read", Region: "us-east-1", AccountID: "222222222222", Profile: "prod-read"},
+		{StackName: "api-default", Region: "us-east-1", AccountID: "111111111111", Profile: "default"},
+	}, doc.Stacks)
+	require.Len(t, doc.Scan.Accounts, 2)
+	assert.Equal(t, "prod-read", doc.Scan.Accounts[0].Profile)
+	assert.Equal(t, []string{"broken", "prod-read"}, doc.Scan.Accounts[0].Profiles)
+	for _, scanErr := range doc.Scan.Errors {
+		assert.NotEqual(t, "ListStacks", scanErr.Operation)
+	}
+
+	// The errors are recorded once every profile of the account has failed
+	scanned = nil
+	scanner = fakeProfileScanner([]string{"broken"}, map[string]string{"broken": "222222222222"}, &scanned)
+	doc, err = scanner.Scan(context.Background(), config.Config{Region: "us-east-1", ProfileGlob: "*"})
+	require.NoError(t, err)
+	assert.Empty(t, doc.Stacks)
+	assert.Empty(t, doc.Scan.Accounts)
+	assert.Equal(t, []models.ScanError{{Profile: "broken", Region: "us-east-1", Operation: "ListStacks", Message: "access denied"}}, doc.Scan.Errors)
+}
+
+func TestProfileScanner_ScanFailures(t *testing.T) {
+	var scanned []string
+
+	scanner := fakeProfileScanner(nil, nil, &scanned)
+	_, err := scanner.Scan(context.Background(), config.Config{Region: "us-east-1", ProfileGlob: "prod-*"})
+	assert.EqualError(t, err, `no profiles in the shared config files match "prod-*"`)
+
+	// When no profile resolves, the error keeps the AWS error type for the exit code
+	scanner = fakeProfileScanner([]string{"expired", "stale"}, nil, &scanned)
+	_, err = scanner.Scan(context.Background(), config.Config{Region: "us-east-1", ProfileGlob: "*"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to resolve any of the 2 profiles")
+	code, _ := errorStatus(err)
+	assert.Equal(t, aws.ErrorTypeSSOExpired.ExitCode(), code)
+
+	assert.Empty(t, scanned)
+}
+
+func TestProfileScanner_ResolveAccountsConcurrently(t *testing.T) {
+	// Each lookup waits until both are in progress, so serial lookups time out
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	scanner := &profileScanner{
+		identify: func(ctx context.Context, cfg config.Config) (*aws.CallerIdentity, error) {
+			arrived.Done()
+			done := make(chan struct{})
+			go func() {
+				arrived.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return &aws.CallerIdentity{Account: "111111111111", ARN: "arn:aws:sts::111111111111:assumed-role/Dev/" + cfg.Profile}, nil
+			case <-time.After(5 * time.Second):
+				return nil, errors.New("lookups did not run concurrently")
+			}
+		},
+	}
+
+	accounts, errs := scanner.resolveAccounts(context.Background(), config.Config{Region: "us-east-1"}, []string{"dev", "dev-admin"})
+	assert.Equal(t, []error{nil, nil}, errs)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, []string{"dev", "dev-admin"}, accounts[0].profiles)
+}
+
+func TestValidateAWSConfig_ProfileGlob(t *testing.T) {
+	cfg := config.Config{
+		Region:      "us-east-1",
+		ProfileGlob: "*",
+		AssumeRole:  &config.AssumeRoleConfig{RoleARN: "arn:aws:iam::123456789012:role/Scanner", SessionName: "scan", Duration: 3600},
+	}
+	assert.ErrorContains(t, validateAWSConfig(cfg), "--assume-role cannot be combined")
+
+	cfg.AssumeRole = nil
+	assert.NoError(t, validateAWSConfig(cfg))
+}
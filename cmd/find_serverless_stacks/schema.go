@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// newSchemaCommand creates the schema subcommand
+func newSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema of the JSON output",
+		Long: `schema prints the JSON Schema (draft 2020-12) describing the document written by
+--output json. The same schema is published in the repository as schema/stacks-output.schema.json.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := schema.Marshal(schema.StacksOutput())
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(data))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/stale"
+	"github.com/spf13/cobra"
+)
+
+var (
+	staleOlderThan       string
+	staleEphemeralStages []string
+	staleFormat          string
+)
+
+// newStaleCommand creates the stale subcommand
+func newStaleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List stacks that have not been deployed for a while",
+		Long: `stale lists detected stacks whose last deployment is older than --older-than,
+oldest first, to find abandoned stages for cleanup.
+
+Stages that look short-lived, such as pull request previews and feature branches,
+are marked as ephemeral. Add --ephemeral-stage patterns for your own conventions,
+for example the names developers use for their personal stages.`,
+		Args: cobra.NoArgs,
+		RunE: runStale,
+	}
+
+	addAWSFlags(cmd)
+	addInputFlag(cmd)
+	addProfileScanFlags(cmd)
+	addFilterFlags(cmd)
+	cmd.Flags().StringVar(&staleOlderThan, "older-than", "90d", "Minimum time since the last deployment (e.g. 90d, 12w, 36h)")
+	cmd.Flags().StringSliceVar(&staleEphemeralStages, "ephemeral-stage", stale.DefaultEphemeralStages, "Glob patterns of ephemeral stages (repeatable; replaces the defaults)")
+	cmd.Flags().StringVarP(&staleFormat, "output", "o", "table", "Output format (table, json, csv, markdown)")
+
+	return cmd
+}
+
+func runStale(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	olderThan, err := stale.ParseAge(staleOlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+
+	opts := stale.Options{OlderThan: olderThan, EphemeralStages: staleEphemeralStages}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	formatter, err := stale.FormatterFactory(staleFormat)
+	if err != nil {
+		return err
+	}
+
+	cfg := newConfig()
+	if err := prepareSource(&cfg); err != nil {
+		return err
+	}
+
+	doc, err := loadStacks(ctx, cfg)
+	if err != nil {
+		return err
+	}
+
+	report := stale.Find(doc.Stacks, scanTime(doc), opts)
+	result, err := formatter.Format(report)
+	if err != nil {
+		return fmt.Errorf("failed to format stale report: %w", err)
+	}
+	fmt.Println(result)
+	return nil
+}
+
+// scanTime returns when the stacks were scanned, or now for documents without scan metadata
+func scanTime(doc *models.StacksOutput) time.Time {
+	if doc.Scan != nil && !doc.Scan.StartedAt.IsZero() {
+		return doc.Scan.StartedAt
+	}
+	return time.Now().UTC()
+}
@@ -2,23 +2,22 @@ package aws
 
 import (
 	"context"
-	"fmt"
-	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
-	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // AuthConfig holds authentication configuration
 type AuthConfig struct {
 	Profile string
 	Region  string
+	// ExplicitProfile loads Profile from the shared config files even when it is "default"
+	ExplicitProfile bool
 
 	// AssumeRole configuration
 	AssumeRole *AssumeRoleCredentials
+
+	// CredentialCacheDir, when set, caches assumed-role credentials across runs
+	CredentialCacheDir string
 }
 
 // AssumeRoleCredentials holds AssumeRole-specific configuration
@@ -27,24 +26,30 @@ type AssumeRoleCredentials struct {
 	SessionName string
 	Duration    int32
 	ExternalID  string
+
+	// SourceIdentity is recorded in CloudTrail for every action of the session
+	SourceIdentity string
+	// Tags are the session tags; TransitiveTagKeys pass some of them on to chained roles
+	Tags              []SessionTag
+	TransitiveTagKeys []string
+	// Policy is an inline JSON session policy limiting the permissions of the session
+	Policy string
+}
+
+// SessionTag is a tag attached to an assumed-role session
+type SessionTag struct {
+	Key   string
+	Value string
 }
 
 // NewCloudFormationClient creates a new CloudFormation client with the specified configuration
+// and validates the credentials with a ListStacks call
 func NewCloudFormationClient(ctx context.Context, authConfig AuthConfig) (*Client, error) {
-	// Load base AWS configuration
-	awsConfig, err := loadBaseConfig(ctx, authConfig)
+	awsConfig, err := LoadConfig(ctx, authConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply AssumeRole if specified
-	if authConfig.AssumeRole != nil {
-		awsConfig, err = applyAssumeRole(ctx, awsConfig, authConfig.AssumeRole)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// Create CloudFormation client
 	cfClient := cloudformation.NewFromConfig(awsConfig)
 
@@ -56,50 +61,6 @@ func NewCloudFormationClient(ctx context.Context, authConfig AuthConfig) (*Clien
 	return NewClient(cfClient, authConfig.Region), nil
 }
 
-// loadBaseConfig loads the base AWS configuration without AssumeRole
-func loadBaseConfig(ctx context.Context, authConfig AuthConfig) (aws.Config, error) {
-	var cfg config.LoadOptionsFunc
-
-	// Configure profile if specified
-	if authConfig.Profile != "" && authConfig.Profile != "default" {
-		cfg = config.WithSharedConfigProfile(authConfig.Profile)
-	}
-
-	awsConfig, err := config.LoadDefaultConfig(ctx, cfg, config.WithRegion(authConfig.Region))
-	if err != nil {
-		return aws.Config{}, &Error{
-			Type:    ErrorTypePermission,
-			Message: fmt.Sprintf("failed to load AWS config for profile '%s' in region '%s'", authConfig.Profile, authConfig.Region),
-			Cause:   err,
-		}
-	}
-
-	return awsConfig, nil
-}
-
-// applyAssumeRole applies AssumeRole configuration to the AWS config
-func applyAssumeRole(ctx context.Context, awsConfig aws.Config, roleConfig *AssumeRoleCredentials) (aws.Config, error) {
-	// Create STS client for AssumeRole
-	stsClient := sts.NewFromConfig(awsConfig)
-
-	// Create AssumeRole provider
-	provider := stscreds.NewAssumeRoleProvider(stsClient, roleConfig.RoleARN, func(o *stscreds.AssumeRoleOptions) {
-		o.RoleSessionName = roleConfig.SessionName
-		o.Duration = time.Duration(roleConfig.Duration) * time.Second
-
-		// Add External ID if specified
-		if roleConfig.ExternalID != "" {
-			o.ExternalID = aws.String(roleConfig.ExternalID)
-		}
-	})
-
-	// Create new config with AssumeRole credentials
-	assumedConfig := awsConfig.Copy()
-	assumedConfig.Credentials = aws.NewCredentialsCache(provider)
-
-	return assumedConfig, nil
-}
-
 // validateAWSCredentials performs a minimal API call to validate credentials
 func validateAWSCredentials(ctx context.Context, client *cloudformation.Client) error {
 	return validateAWSCredentialsWithAPI(ctx, client)
@@ -112,7 +73,7 @@ func validateAWSCredentialsWithAPI(ctx context.Context, client CloudFormationAPI
 
 	if err != nil {
 		return &Error{
-			Type:    ErrorTypePermission,
+			Type:    classifyType(err, "", ErrorTypePermission),
 			Message: "failed to validate AWS credentials",
 			Cause:   err,
 		}
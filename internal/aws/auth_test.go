@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,7 +82,15 @@ func TestValidateAWSCredentials(t *testing.T) {
 				return nil, errors.New("AccessDenied: insufficient permissions")
 			},
 			expectError: true,
-			errorType:   ErrorTypePermission,
+			errorType:   ErrorTypePermission, // unclassified errors are reported as permission errors
+		},
+		{
+			name: "expired token",
+			mockFunc: func(ctx context.Context, params *cloudformation.ListStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListStacksOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "ExpiredToken"}
+			},
+			expectError: true,
+			errorType:   ErrorTypeCredentials,
 		},
 		{
 			name: "network error",
@@ -89,7 +98,7 @@ func TestValidateAWSCredentials(t *testing.T) {
 				return nil, errors.New("connection timeout")
 			},
 			expectError: true,
-			errorType:   ErrorTypePermission, // validateAWSCredentials treats all errors as permission errors
+			errorType:   ErrorTypeNetwork,
 		},
 	}
 
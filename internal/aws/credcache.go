@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// CredentialCacheExpiryMargin is how long before their expiry cached credentials stop being used
+const CredentialCacheExpiryMargin = 5 * time.Minute
+
+// DefaultCredentialCacheDir returns the default location of the assumed-role credential cache
+func DefaultCredentialCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".find-serverless-stacks", "cache", "credentials"), nil
+}
+
+// credentialCacheEntry is a cache file, in the layout of ~/.aws/cli/cache
+type credentialCacheEntry struct {
+	Credentials struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		SessionToken    string
+		Expiration      time.Time
+	}
+}
+
+// credentialCacheKey returns the cache file name of a role session; everything
+// that changes what the session may do is part of the key
+func credentialCacheKey(role AssumeRoleCredentials) string {
+	data, _ := json.Marshal(struct {
+		RoleArn           string
+		RoleSessionName   string
+		ExternalId        string       `json:",omitempty"`
+		SourceIdentity    string       `json:",omitempty"`
+		Tags              []SessionTag `json:",omitempty"`
+		TransitiveTagKeys []string     `json:",omitempty"`
+		Policy            string       `json:",omitempty"`
+	}{role.RoleARN, role.SessionName, role.ExternalID, role.SourceIdentity, role.Tags, role.TransitiveTagKeys, role.Policy})
+
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// fileCacheProvider serves assumed-role credentials from a cache file while they
+// are valid, and otherwise retrieves them from provider and stores them
+type fileCacheProvider struct {
+	provider aws.CredentialsProvider
+	dir      string
+	path     string
+	margin   time.Duration
+	now      func() time.Time
+}
+
+// newFileCacheProvider caches the credentials of a role session in dir
+func newFileCacheProvider(dir string, role AssumeRoleCredentials, provider aws.CredentialsProvider) *fileCacheProvider {
+	return &fileCacheProvider{
+		provider: provider,
+		dir:      dir,
+		path:     filepath.Join(dir, credentialCacheKey(role)),
+		margin:   CredentialCacheExpiryMargin,
+		now:      time.Now,
+	}
+}
+
+// Retrieve implements aws.CredentialsProvider. Unreadable or expired entries are
+// replaced; a failure to write the cache does not fail the retrieval.
+func (p *fileCacheProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if creds, ok := p.load(); ok {
+		return creds, nil
+	}
+
+	creds, err := p.provider.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	if creds.CanExpire {
+		_ = p.store(creds)
+	}
+	return creds, nil
+}
+
+// load returns the cached credentials when they are valid for longer than the margin
+func (p *fileCacheProvider) load() (aws.Credentials, bool) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return aws.Credentials{}, false
+	}
+
+	var entry credentialCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return aws.Credentials{}, false
+	}
+
+	c := entry.Credentials
+	if c.AccessKeyId == "" || !p.now().Add(p.margin).Before(c.Expiration) {
+		return aws.Credentials{}, false
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     c.AccessKeyId,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+		Source:          "find-serverless-stacks credential cache",
+		CanExpire:       true,
+		// Stop using the credentials in memory when they would no longer be read from the file
+		Expires: c.Expiration.Add(-p.margin),
+	}, true
+}
+
+// store writes the credentials to the cache file, readable by the owner only
+func (p *fileCacheProvider) store(creds aws.Credentials) error {
+	var entry credentialCacheEntry
+	entry.Credentials.AccessKeyId = creds.AccessKeyID
+	entry.Credentials.SecretAccessKey = creds.SecretAccessKey
+	entry.Credentials.SessionToken = creds.SessionToken
+	entry.Credentials.Expiration = creds.Expires.UTC()
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cached credentials: %w", err)
+	}
+
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create credential cache directory: %w", err)
+	}
+
+	// os.CreateTemp creates the file with mode 0600; renaming keeps readers from seeing partial writes
+	tmp, err := os.CreateTemp(p.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cached credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cached credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cached credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		return fmt.Errorf("failed to write cached credentials: %w", err)
+	}
+	return nil
+}
+
+// ClearCredentialCache removes every cached credential in dir and returns how many were removed
+func ClearCredentialCache(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read credential cache: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove cached credentials: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
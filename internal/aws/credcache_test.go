@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cacheTestRole = AssumeRoleCredentials{RoleARN: "arn:aws:iam::123456789012:role/scanner", SessionName: "scan", ExternalID: "ext"}
+
+// countingProvider returns credentials expiring at expires and counts the calls
+type countingProvider struct {
+	calls   int
+	expires time.Time
+	err     error
+}
+
+func (p *countingProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	p.calls++
+	if p.err != nil {
+		return aws.Credentials{}, p.err
+	}
+	return aws.Credentials{
+		AccessKeyID:     "ASIA-FRESH",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		CanExpire:       !p.expires.IsZero(),
+		Expires:         p.expires,
+	}, nil
+}
+
+func TestFileCacheProvider(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		cached    string
+		expires   time.Time
+		wantCalls int
+		wantKey   string
+	}{
+		{
+			name:      "empty cache",
+			expires:   now.Add(time.Hour),
+			wantCalls: 1,
+			wantKey:   "ASIA-FRESH",
+		},
+		{
+			name:      "valid entry",
+			cached:    `{"Credentials": {"AccessKeyId": "ASIA-CACHED", "SecretAccessKey": "s", "SessionToken": "t", "Expiration": "2026-06-01T13:00:00Z"}}`,
+			expires:   now.Add(time.Hour),
+			wantCalls: 0,
+			wantKey:   "ASIA-CACHED",
+		},
+		{
+			name:      "entry expiring within the margin",
+			cached:    `{"Credentials": {"AccessKeyId": "ASIA-CACHED", "SecretAccessKey": "s", "SessionToken": "t", "Expiration": "2026-06-01T12:04:00Z"}}`,
+			expires:   now.Add(time.Hour),
+			wantCalls: 1,
+			wantKey:   "ASIA-FRESH",
+		},
+		{
+			name:      "corrupt entry",
+			cached:    `{"Credentials":`,
+			expires:   now.Add(time.Hour),
+			wantCalls: 1,
+			wantKey:   "ASIA-FRESH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "cache")
+			source := &countingProvider{expires: tt.expires}
+			p := newFileCacheProvider(dir, cacheTestRole, source)
+			p.now = func() time.Time { return now }
+
+			if tt.cached != "" {
+				require.NoError(t, os.MkdirAll(dir, 0700))
+				require.NoError(t, os.WriteFile(p.path, []byte(tt.cached), 0600))
+			}
+
+			creds, err := p.Retrieve(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, creds.AccessKeyID)
+			assert.Equal(t, tt.wantCalls, source.calls)
+			assert.True(t, creds.CanExpire)
+
+			// The entry now holds valid credentials, so the next run reuses them
+			next := newFileCacheProvider(dir, cacheTestRole, source)
+			next.now = p.now
+			again, err := next.Retrieve(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantKey, again.AccessKeyID)
+			assert.Equal(t, tt.wantCalls, source.calls)
+		})
+	}
+}
+
+func TestFileCacheProvider_Permissions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	p := newFileCacheProvider(dir, cacheTestRole, &countingProvider{expires: time.Now().Add(time.Hour)})
+
+	_, err := p.Retrieve(context.Background())
+	require.NoError(t, err)
+
+	info, err := os.Stat(p.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	info, err = os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestFileCacheProvider_NotStored(t *testing.T) {
+	dir := t.TempDir()
+
+	// Credentials that never expire are not written
+	p := newFileCacheProvider(dir, cacheTestRole, &countingProvider{})
+	_, err := p.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.NoFileExists(t, p.path)
+
+	// Errors are returned and nothing is written
+	p = newFileCacheProvider(dir, cacheTestRole, &countingProvider{err: errors.New("access denied")})
+	_, err = p.Retrieve(context.Background())
+	assert.EqualError(t, err, "access denied")
+	assert.NoFileExists(t, p.path)
+}
+
+func TestCredentialCacheKey(t *testing.T) {
+	key := credentialCacheKey(cacheTestRole)
+	assert.Regexp(t, `^[0-9a-f]{40}\.json$`, key)
+	assert.Equal(t, key, credentialCacheKey(cacheTestRole))
+
+	others := []AssumeRoleCredentials{
+		{RoleARN: "arn:aws:iam::123456789012:role/other", SessionName: "scan", ExternalID: "ext"},
+		{RoleARN: cacheTestRole.RoleARN, SessionName: "other", ExternalID: "ext"},
+		{RoleARN: cacheTestRole.RoleARN, SessionName: "scan"},
+	}
+	for _, other := range others {
+		assert.NotEqual(t, key, credentialCacheKey(other))
+	}
+
+	// The duration does not change which session is cached
+	withDuration := cacheTestRole
+	withDuration.Duration = 900
+	assert.Equal(t, key, credentialCacheKey(withDuration))
+}
+
+func TestClearCredentialCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0600))
+	}
+
+	removed, err := ClearCredentialCache(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+
+	removed, err = ClearCredentialCache(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+}
+
+func TestCachingProvider_CredentialCache(t *testing.T) {
+	dir := t.TempDir()
+	var calls []stsCall
+	target := Target{Region: "us-east-1", RoleChain: []AssumeRoleCredentials{cacheTestRole}, CredentialCacheDir: dir}
+
+	// Each provider stands for a separate run of the tool
+	for run := 0; run < 2; run++ {
+		cfg, err := newTestProvider(&fakeLoader{}, &calls).Config(context.Background(), target)
+		require.NoError(t, err)
+
+		creds, err := cfg.Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "key-"+cacheTestRole.RoleARN, creds.AccessKeyID)
+	}
+	assert.Len(t, calls, 1, "the second run uses the cached credentials")
+
+	// Without a cache directory every run assumes the role
+	target.CredentialCacheDir = ""
+	cfg, err := newTestProvider(&fakeLoader{}, &calls).Config(context.Background(), target)
+	require.NoError(t, err)
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, calls, 2)
+}
@@ -0,0 +1,218 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// Target describes the credentials and region an AWS configuration is built for
+type Target struct {
+	// Profile is the shared config profile; "" and "default" use the SDK default
+	// chain, which honours AWS_PROFILE and environment credentials
+	Profile string
+	Region  string
+	// ExplicitProfile loads Profile from the shared config files even when it is "default"
+	ExplicitProfile bool
+	// WebIdentity, when set, replaces the profile credentials with web identity role credentials
+	WebIdentity *WebIdentityCredentials
+	// RoleChain is assumed in order, each role with the credentials of the previous one
+	RoleChain []AssumeRoleCredentials
+	// CredentialCacheDir, when set, keeps assumed-role credentials in files across runs
+	CredentialCacheDir string
+}
+
+// WebIdentityCredentials holds the configuration of AssumeRoleWithWebIdentity
+type WebIdentityCredentials struct {
+	RoleARN     string
+	TokenFile   string
+	SessionName string
+}
+
+// Target returns the configuration target of the authentication settings
+func (a AuthConfig) Target() Target {
+	target := Target{Profile: a.Profile, ExplicitProfile: a.ExplicitProfile, Region: a.Region, CredentialCacheDir: a.CredentialCacheDir}
+	if a.AssumeRole != nil {
+		target.RoleChain = []AssumeRoleCredentials{*a.AssumeRole}
+	}
+	return target
+}
+
+// key identifies the target in the provider cache
+func (t Target) key() string {
+	var b strings.Builder
+	profile := t.Profile
+	if profile == "default" && !t.ExplicitProfile {
+		profile = ""
+	}
+	fmt.Fprintf(&b, "profile=%q region=%q cache=%q", profile, t.Region, t.CredentialCacheDir)
+	if t.WebIdentity != nil {
+		fmt.Fprintf(&b, " web=%q,%q,%q", t.WebIdentity.RoleARN, t.WebIdentity.TokenFile, t.WebIdentity.SessionName)
+	}
+	for _, role := range t.RoleChain {
+		fmt.Fprintf(&b, " role=%q,%q,%d,%q,%q,%q,%q,%q", role.RoleARN, role.SessionName, role.Duration, role.ExternalID,
+			role.SourceIdentity, fmt.Sprint(role.Tags), fmt.Sprint(role.TransitiveTagKeys), role.Policy)
+	}
+	return b.String()
+}
+
+// ConfigProvider yields the AWS configuration every service client is built from
+type ConfigProvider interface {
+	Config(ctx context.Context, target Target) (aws.Config, error)
+}
+
+// stsCredentialsAPI is the STS client used by the credential providers
+type stsCredentialsAPI interface {
+	stscreds.AssumeRoleAPIClient
+	stscreds.AssumeRoleWithWebIdentityAPIClient
+}
+
+// CachingProvider resolves configurations with the SDK and caches them per target,
+// so clients built for the same target share one credentials cache
+type CachingProvider struct {
+	loadDefault  func(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error)
+	newSTSClient func(cfg aws.Config) stsCredentialsAPI
+
+	// mu guards the maps; each key has its own lock, held while its target resolves
+	mu      sync.Mutex
+	configs map[string]aws.Config
+	locks   map[string]*sync.Mutex
+}
+
+// NewCachingProvider creates a provider backed by the shared config files and STS
+func NewCachingProvider() *CachingProvider {
+	return &CachingProvider{
+		loadDefault: config.LoadDefaultConfig,
+		newSTSClient: func(cfg aws.Config) stsCredentialsAPI {
+			return sts.NewFromConfig(cfg)
+		},
+		configs: make(map[string]aws.Config),
+		locks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// DefaultProvider is the provider used by LoadConfig and the client constructors
+var DefaultProvider ConfigProvider = NewCachingProvider()
+
+// Config implements ConfigProvider; failures are not cached. Different targets
+// resolve concurrently, while concurrent calls for one target resolve it once.
+func (p *CachingProvider) Config(ctx context.Context, target Target) (aws.Config, error) {
+	key := target.key()
+
+	lock := p.keyLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	p.mu.Lock()
+	cfg, ok := p.configs[key]
+	p.mu.Unlock()
+	if ok {
+		return cfg, nil
+	}
+
+	cfg, err := p.resolve(ctx, target)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	p.mu.Lock()
+	p.configs[key] = cfg
+	p.mu.Unlock()
+	return cfg, nil
+}
+
+// keyLock returns the lock of a target key
+func (p *CachingProvider) keyLock(key string) *sync.Mutex {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	lock, ok := p.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		p.locks[key] = lock
+	}
+	return lock
+}
+
+// resolve builds the configuration of a target
+func (p *CachingProvider) resolve(ctx context.Context, target Target) (aws.Config, error) {
+	for _, role := range target.RoleChain {
+		if err := checkRolePartition(role, target.Region); err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	var opts []func(*config.LoadOptions) error
+	if target.Profile != "" && (target.Profile != "default" || target.ExplicitProfile) {
+		opts = append(opts, config.WithSharedConfigProfile(target.Profile))
+	}
+	if target.Region != "" {
+		opts = append(opts, config.WithRegion(target.Region))
+	}
+
+	cfg, err := p.loadDefault(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, &Error{
+			Type:    classifyType(err, target.Region, ErrorTypeConfig),
+			Message: "failed to load AWS configuration",
+			Cause:   err,
+		}
+	}
+
+	if web := target.WebIdentity; web != nil {
+		provider := stscreds.NewWebIdentityRoleProvider(p.newSTSClient(cfg), web.RoleARN, stscreds.IdentityTokenFile(web.TokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = web.SessionName
+		})
+		cfg = cfg.Copy()
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
+	for _, role := range target.RoleChain {
+		cfg = p.assumeRole(cfg, role, target.CredentialCacheDir)
+	}
+
+	return cfg, nil
+}
+
+// assumeRole returns a copy of cfg whose credentials are those of the role,
+// cached in cacheDir when it is set
+func (p *CachingProvider) assumeRole(cfg aws.Config, role AssumeRoleCredentials, cacheDir string) aws.Config {
+	var provider aws.CredentialsProvider = stscreds.NewAssumeRoleProvider(p.newSTSClient(cfg), role.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = role.SessionName
+		if role.Duration > 0 {
+			o.Duration = time.Duration(role.Duration) * time.Second
+		}
+
+		// Add External ID if specified
+		if role.ExternalID != "" {
+			o.ExternalID = aws.String(role.ExternalID)
+		}
+
+		if role.SourceIdentity != "" {
+			o.SourceIdentity = aws.String(role.SourceIdentity)
+		}
+		for _, tag := range role.Tags {
+			o.Tags = append(o.Tags, ststypes.Tag{Key: aws.String(tag.Key), Value: aws.String(tag.Value)})
+		}
+		o.TransitiveTagKeys = role.TransitiveTagKeys
+		if role.Policy != "" {
+			o.Policy = aws.String(role.Policy)
+		}
+	})
+
+	if cacheDir != "" {
+		provider = newFileCacheProvider(cacheDir, role, provider)
+	}
+
+	assumed := cfg.Copy()
+	assumed.Credentials = aws.NewCredentialsCache(provider)
+	return assumed
+}
@@ -0,0 +1,363 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stsCall records a call to fakeSTS and the access key it was made with
+type stsCall struct {
+	operation   string
+	roleARN     string
+	caller      string
+	sessionName string
+	externalID  string
+	duration    int32
+	token       string
+	// Session options of AssumeRole
+	sourceIdentity string
+	tags           []string
+	transitive     []string
+	policy         string
+}
+
+// fakeSTS issues credentials named after the role, after retrieving the
+// credentials of its configuration the way request signing would
+type fakeSTS struct {
+	cfg   aws.Config
+	calls *[]stsCall
+}
+
+func (f *fakeSTS) caller(ctx context.Context) string {
+	if f.cfg.Credentials == nil {
+		return ""
+	}
+	creds, err := f.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return creds.AccessKeyID
+}
+
+func roleCredentials(roleARN string) *ststypes.Credentials {
+	return &ststypes.Credentials{
+		AccessKeyId:     aws.String("key-" + roleARN),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}
+}
+
+func (f *fakeSTS) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	var tags []string
+	for _, tag := range params.Tags {
+		tags = append(tags, aws.ToString(tag.Key)+"="+aws.ToString(tag.Value))
+	}
+	*f.calls = append(*f.calls, stsCall{
+		operation:      "AssumeRole",
+		roleARN:        aws.ToString(params.RoleArn),
+		caller:         f.caller(ctx),
+		sessionName:    aws.ToString(params.RoleSessionName),
+		externalID:     aws.ToString(params.ExternalId),
+		duration:       aws.ToInt32(params.DurationSeconds),
+		sourceIdentity: aws.ToString(params.SourceIdentity),
+		tags:           tags,
+		transitive:     params.TransitiveTagKeys,
+		policy:         aws.ToString(params.Policy),
+	})
+	return &sts.AssumeRoleOutput{Credentials: roleCredentials(aws.ToString(params.RoleArn))}, nil
+}
+
+func (f *fakeSTS) AssumeRoleWithWebIdentity(ctx context.Context, params *sts.AssumeRoleWithWebIdentityInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleWithWebIdentityOutput, error) {
+	*f.calls = append(*f.calls, stsCall{
+		operation:   "AssumeRoleWithWebIdentity",
+		roleARN:     aws.ToString(params.RoleArn),
+		sessionName: aws.ToString(params.RoleSessionName),
+		token:       aws.ToString(params.WebIdentityToken),
+	})
+	return &sts.AssumeRoleWithWebIdentityOutput{Credentials: roleCredentials(aws.ToString(params.RoleArn))}, nil
+}
+
+// fakeLoader records the options of every load and returns static base credentials
+type fakeLoader struct {
+	mu    sync.Mutex
+	loads []config.LoadOptions
+	err   error
+	// wait, when set, is called by every load before it returns, like a slow network call
+	wait func() error
+}
+
+func (l *fakeLoader) load(ctx context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	var o config.LoadOptions
+	for _, fn := range optFns {
+		if err := fn(&o); err != nil {
+			return aws.Config{}, err
+		}
+	}
+	l.mu.Lock()
+	l.loads = append(l.loads, o)
+	l.mu.Unlock()
+	if l.wait != nil {
+		if err := l.wait(); err != nil {
+			return aws.Config{}, err
+		}
+	}
+	if l.err != nil {
+		return aws.Config{}, l.err
+	}
+
+	return aws.Config{
+		Region: o.Region,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "base", SecretAccessKey: "secret"}, nil
+		}),
+	}, nil
+}
+
+func newTestProvider(loader *fakeLoader, calls *[]stsCall) *CachingProvider {
+	p := NewCachingProvider()
+	p.loadDefault = loader.load
+	p.newSTSClient = func(cfg aws.Config) stsCredentialsAPI {
+		return &fakeSTS{cfg: cfg, calls: calls}
+	}
+	return p
+}
+
+func TestCachingProvider_Profile(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      Target
+		wantProfile string
+		wantRegion  string
+	}{
+		{name: "empty profile uses the default chain", target: Target{Region: "us-east-1"}, wantRegion: "us-east-1"},
+		{name: "default profile uses the default chain", target: Target{Profile: "default", Region: "us-east-1"}, wantRegion: "us-east-1"},
+		{name: "explicit default profile", target: Target{Profile: "default", ExplicitProfile: true, Region: "us-east-1"}, wantProfile: "default", wantRegion: "us-east-1"},
+		{name: "named profile", target: Target{Profile: "dev", Region: "eu-west-1"}, wantProfile: "dev", wantRegion: "eu-west-1"},
+		{name: "region from the profile", target: Target{Profile: "dev"}, wantProfile: "dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &fakeLoader{}
+			var calls []stsCall
+			cfg, err := newTestProvider(loader, &calls).Config(context.Background(), tt.target)
+			require.NoError(t, err)
+
+			require.Len(t, loader.loads, 1)
+			assert.Equal(t, tt.wantProfile, loader.loads[0].SharedConfigProfile)
+			assert.Equal(t, tt.wantRegion, loader.loads[0].Region)
+
+			creds, err := cfg.Credentials.Retrieve(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "base", creds.AccessKeyID)
+			assert.Empty(t, calls)
+		})
+	}
+}
+
+func TestCachingProvider_LoadError(t *testing.T) {
+	loader := &fakeLoader{err: config.SharedConfigProfileNotExistError{Profile: "missing"}}
+	var calls []stsCall
+	p := newTestProvider(loader, &calls)
+
+	_, err := p.Config(context.Background(), Target{Profile: "missing"})
+	require.Error(t, err)
+	var customErr *Error
+	require.True(t, errors.As(err, &customErr))
+	assert.Equal(t, ErrorTypeConfig, customErr.Type)
+
+	// Failures are not cached
+	_, err = p.Config(context.Background(), Target{Profile: "missing"})
+	require.Error(t, err)
+	assert.Len(t, loader.loads, 2)
+}
+
+func TestCachingProvider_Cache(t *testing.T) {
+	loader := &fakeLoader{}
+	var calls []stsCall
+	p := newTestProvider(loader, &calls)
+	ctx := context.Background()
+	role := AssumeRoleCredentials{RoleARN: "arn:aws:iam::123456789012:role/scanner", SessionName: "scan"}
+
+	targets := []Target{
+		{Profile: "default", Region: "us-east-1"},
+		{Region: "us-east-1"},
+		{Profile: "default", ExplicitProfile: true, Region: "us-east-1"},
+		{Region: "us-west-2"},
+		{Region: "us-east-1", RoleChain: []AssumeRoleCredentials{role}},
+		{Region: "us-east-1", RoleChain: []AssumeRoleCredentials{role}},
+	}
+	var configs []aws.Config
+	for _, target := range targets {
+		cfg, err := p.Config(ctx, target)
+		require.NoError(t, err)
+		configs = append(configs, cfg)
+	}
+
+	// "default" and "" share an entry, unlike the explicit default profile; the role target is loaded once
+	assert.Len(t, loader.loads, 4)
+
+	// Clients of one target share the credentials cache, so the role is assumed once
+	for _, cfg := range configs[4:] {
+		_, err := cfg.Credentials.Retrieve(ctx)
+		require.NoError(t, err)
+	}
+	assert.Len(t, calls, 1)
+}
+
+func TestCachingProvider_Concurrent(t *testing.T) {
+	// Each load waits until two loads are in progress, so serial loads time out
+	var arrived sync.WaitGroup
+	arrived.Add(2)
+	loader := &fakeLoader{wait: func() error {
+		arrived.Done()
+		done := make(chan struct{})
+		go func() {
+			arrived.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-time.After(5 * time.Second):
+			return errors.New("loads did not run concurrently")
+		}
+	}}
+	var calls []stsCall
+	p := newTestProvider(loader, &calls)
+
+	targets := []Target{{Profile: "dev", Region: "us-east-1"}, {Profile: "prod", Region: "us-east-1"}}
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = p.Config(context.Background(), target)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// Concurrent calls for one target resolve it once
+	loader = &fakeLoader{wait: func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	p = newTestProvider(loader, &calls)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p.Config(context.Background(), targets[0])
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, loader.loads, 1)
+}
+
+func TestCachingProvider_RoleChain(t *testing.T) {
+	loader := &fakeLoader{}
+	var calls []stsCall
+	p := newTestProvider(loader, &calls)
+
+	cfg, err := p.Config(context.Background(), Target{
+		Profile: "dev",
+		Region:  "us-east-1",
+		RoleChain: []AssumeRoleCredentials{
+			{RoleARN: "arn:aws:iam::111111111111:role/hub", SessionName: "hub-session", Duration: 1800},
+			{RoleARN: "arn:aws:iam::222222222222:role/scanner", SessionName: "scan-session", ExternalID: "ext"},
+		},
+	})
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-arn:aws:iam::222222222222:role/scanner", creds.AccessKeyID)
+
+	assert.Equal(t, []stsCall{
+		{operation: "AssumeRole", roleARN: "arn:aws:iam::111111111111:role/hub", caller: "base", sessionName: "hub-session", duration: 1800},
+		{operation: "AssumeRole", roleARN: "arn:aws:iam::222222222222:role/scanner", caller: "key-arn:aws:iam::111111111111:role/hub", sessionName: "scan-session", externalID: "ext", duration: 900},
+	}, calls, "each role is assumed with the credentials of the previous one")
+}
+
+func TestCachingProvider_WebIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("oidc-token"), 0600))
+
+	loader := &fakeLoader{}
+	var calls []stsCall
+	p := newTestProvider(loader, &calls)
+
+	cfg, err := p.Config(context.Background(), Target{
+		Region:      "us-east-1",
+		WebIdentity: &WebIdentityCredentials{RoleARN: "arn:aws:iam::111111111111:role/ci", TokenFile: tokenFile, SessionName: "ci"},
+		RoleChain:   []AssumeRoleCredentials{{RoleARN: "arn:aws:iam::222222222222:role/scanner", SessionName: "scan"}},
+	})
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-arn:aws:iam::222222222222:role/scanner", creds.AccessKeyID)
+
+	require.Len(t, calls, 2)
+	assert.Equal(t, stsCall{operation: "AssumeRoleWithWebIdentity", roleARN: "arn:aws:iam::111111111111:role/ci", sessionName: "ci", token: "oidc-token"}, calls[0])
+	assert.Equal(t, stsCall{operation: "AssumeRole", roleARN: "arn:aws:iam::222222222222:role/scanner", caller: "key-arn:aws:iam::111111111111:role/ci", sessionName: "scan", duration: 900}, calls[1])
+}
+
+func TestCachingProvider_SessionOptions(t *testing.T) {
+	var calls []stsCall
+	p := newTestProvider(&fakeLoader{}, &calls)
+
+	cfg, err := p.Config(context.Background(), Target{
+		Region: "us-east-1",
+		RoleChain: []AssumeRoleCredentials{{
+			RoleARN:           "arn:aws:iam::123456789012:role/scanner",
+			SessionName:       "scan",
+			SourceIdentity:    "alice",
+			Tags:              []SessionTag{{Key: "Team", Value: "platform"}, {Key: "Ticket", Value: "OPS-1"}},
+			TransitiveTagKeys: []string{"Team"},
+			Policy:            `{"Version":"2012-10-17","Statement":[]}`,
+		}},
+	})
+	require.NoError(t, err)
+	_, err = cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, calls, 1)
+	assert.Equal(t, "alice", calls[0].sourceIdentity)
+	assert.Equal(t, []string{"Team=platform", "Ticket=OPS-1"}, calls[0].tags)
+	assert.Equal(t, []string{"Team"}, calls[0].transitive)
+	assert.Equal(t, `{"Version":"2012-10-17","Statement":[]}`, calls[0].policy)
+
+	// Sessions with different options are cached separately
+	base := Target{Region: "us-east-1", RoleChain: []AssumeRoleCredentials{{RoleARN: "arn:aws:iam::123456789012:role/scanner", SessionName: "scan"}}}
+	tagged := Target{Region: "us-east-1", RoleChain: []AssumeRoleCredentials{{RoleARN: "arn:aws:iam::123456789012:role/scanner", SessionName: "scan", Tags: []SessionTag{{Key: "Team", Value: "platform"}}}}}
+	assert.NotEqual(t, base.key(), tagged.key())
+	assert.NotEqual(t, credentialCacheKey(base.RoleChain[0]), credentialCacheKey(tagged.RoleChain[0]))
+}
+
+func TestAuthConfig_Target(t *testing.T) {
+	assert.Equal(t, Target{Profile: "dev", Region: "us-east-1"}, AuthConfig{Profile: "dev", Region: "us-east-1"}.Target())
+	assert.Equal(t, Target{Profile: "default", ExplicitProfile: true}, AuthConfig{Profile: "default", ExplicitProfile: true}.Target())
+
+	role := &AssumeRoleCredentials{RoleARN: "arn:aws:iam::123456789012:role/scanner", SessionName: "scan", Duration: 3600, ExternalID: "ext"}
+	target := AuthConfig{Region: "us-east-1", AssumeRole: role}.Target()
+	assert.Equal(t, []AssumeRoleCredentials{*role}, target.RoleChain)
+}
@@ -2,32 +2,20 @@ package aws
 
 import (
 	"context"
-	"fmt"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 // CreateClient creates a real AWS CloudFormation client with authentication
 func CreateClient(ctx context.Context, auth AuthConfig) (*Client, error) {
-	// Load base AWS configuration
-	cfg, err := loadBaseAWSConfig(ctx, auth)
+	cfg, err := LoadConfig(ctx, auth)
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply AssumeRole if specified
-	if auth.AssumeRole != nil {
-		cfg, err = applyAssumeRoleToConfig(ctx, cfg, auth.AssumeRole)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// Create CloudFormation service client
 	cfClient := cloudformation.NewFromConfig(cfg)
 
@@ -37,47 +25,37 @@ func CreateClient(ctx context.Context, auth AuthConfig) (*Client, error) {
 	return client, nil
 }
 
-// loadBaseAWSConfig loads the base AWS configuration without AssumeRole
-func loadBaseAWSConfig(ctx context.Context, auth AuthConfig) (aws.Config, error) {
-	var opts []func(*config.LoadOptions) error
-
-	// Set profile if specified
-	if auth.Profile != "" {
-		opts = append(opts, config.WithSharedConfigProfile(auth.Profile))
+// CreateSTSClient creates an STS client using the same credentials as CreateClient
+func CreateSTSClient(ctx context.Context, auth AuthConfig) (*sts.Client, error) {
+	cfg, err := LoadConfig(ctx, auth)
+	if err != nil {
+		return nil, err
 	}
 
-	// Set region
-	if auth.Region != "" {
-		opts = append(opts, config.WithRegion(auth.Region))
-	}
+	return sts.NewFromConfig(cfg), nil
+}
 
-	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+// CreateCloudFormationClient creates a CloudFormation service client using the same credentials as CreateClient
+func CreateCloudFormationClient(ctx context.Context, auth AuthConfig) (*cloudformation.Client, error) {
+	cfg, err := LoadConfig(ctx, auth)
 	if err != nil {
-		return aws.Config{}, fmt.Errorf("failed to load AWS configuration: %w", err)
+		return nil, err
 	}
 
-	return cfg, nil
+	return cloudformation.NewFromConfig(cfg), nil
 }
 
-// applyAssumeRoleToConfig applies AssumeRole configuration to the AWS config
-func applyAssumeRoleToConfig(ctx context.Context, cfg aws.Config, roleConfig *AssumeRoleCredentials) (aws.Config, error) {
-	// Create STS client for AssumeRole
-	stsClient := sts.NewFromConfig(cfg)
-
-	// Create AssumeRole provider
-	provider := stscreds.NewAssumeRoleProvider(stsClient, roleConfig.RoleARN, func(o *stscreds.AssumeRoleOptions) {
-		o.RoleSessionName = roleConfig.SessionName
-		o.Duration = time.Duration(roleConfig.Duration) * time.Second
-
-		// Add External ID if specified
-		if roleConfig.ExternalID != "" {
-			o.ExternalID = aws.String(roleConfig.ExternalID)
-		}
-	})
+// CreateS3Client creates an S3 client using the same credentials as CreateClient
+func CreateS3Client(ctx context.Context, auth AuthConfig) (*s3.Client, error) {
+	cfg, err := LoadConfig(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create new config with AssumeRole credentials
-	assumedConfig := cfg.Copy()
-	assumedConfig.Credentials = aws.NewCredentialsCache(provider)
+	return s3.NewFromConfig(cfg), nil
+}
 
-	return assumedConfig, nil
+// LoadConfig loads the AWS configuration for the authentication settings from DefaultProvider
+func LoadConfig(ctx context.Context, auth AuthConfig) (aws.Config, error) {
+	return DefaultProvider.Config(ctx, auth.Target())
 }
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// STSAPI defines the interface for STS operations
+// This interface enables mocking for testing
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// CallerIdentity describes the AWS principal the tool runs as
+type CallerIdentity struct {
+	Account string
+	ARN     string
+	UserID  string
+}
+
+// GetCallerIdentity returns the account and principal behind the configured credentials
+func GetCallerIdentity(ctx context.Context, client STSAPI) (*CallerIdentity, error) {
+	output, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, &Error{
+			Type:    classifyType(err, "", ErrorTypePermission),
+			Message: "failed to get caller identity",
+			Cause:   err,
+		}
+	}
+
+	identity := &CallerIdentity{}
+	if output.Account != nil {
+		identity.Account = *output.Account
+	}
+	if output.Arn != nil {
+		identity.ARN = *output.Arn
+	}
+	if output.UserId != nil {
+		identity.UserID = *output.UserId
+	}
+
+	return identity, nil
+}
+
+// classifyType returns the type ClassifyError gives err, or fallback when
+// err cannot be classified
+func classifyType(err error, region string, fallback ErrorType) ErrorType {
+	var classified *Error
+	if errors.As(ClassifyError(err, region), &classified) && classified.Type != ErrorTypeUnknown {
+		return classified.Type
+	}
+	return fallback
+}
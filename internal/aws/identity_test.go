@@ -0,0 +1,74 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSTSAPI implements STSAPI interface for testing
+type mockSTSAPI struct {
+	getCallerIdentityFunc func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+func (m *mockSTSAPI) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.getCallerIdentityFunc != nil {
+		return m.getCallerIdentityFunc(ctx, params, optFns...)
+	}
+	return &sts.GetCallerIdentityOutput{}, nil
+}
+
+func TestGetCallerIdentity(t *testing.T) {
+	t.Run("successful call", func(t *testing.T) {
+		mock := &mockSTSAPI{
+			getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+				return &sts.GetCallerIdentityOutput{
+					Account: aws.String("123456789012"),
+					Arn:     aws.String("arn:aws:iam::123456789012:user/alice"),
+					UserId:  aws.String("AIDAEXAMPLE"),
+				}, nil
+			},
+		}
+
+		identity, err := GetCallerIdentity(context.Background(), mock)
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012", identity.Account)
+		assert.Equal(t, "arn:aws:iam::123456789012:user/alice", identity.ARN)
+		assert.Equal(t, "AIDAEXAMPLE", identity.UserID)
+	})
+
+	t.Run("API error", func(t *testing.T) {
+		mock := &mockSTSAPI{
+			getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+				return nil, errors.New("ExpiredToken")
+			},
+		}
+
+		identity, err := GetCallerIdentity(context.Background(), mock)
+		require.Error(t, err)
+		assert.Nil(t, identity)
+
+		var customErr *Error
+		require.True(t, errors.As(err, &customErr))
+		assert.Equal(t, ErrorTypePermission, customErr.Type)
+	})
+
+	t.Run("expired credentials", func(t *testing.T) {
+		mock := &mockSTSAPI{
+			getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "ExpiredToken", Message: "The security token included in the request is expired"}
+			},
+		}
+
+		_, err := GetCallerIdentity(context.Background(), mock)
+		var customErr *Error
+		require.True(t, errors.As(err, &customErr))
+		assert.Equal(t, ErrorTypeCredentials, customErr.Type)
+	})
+}
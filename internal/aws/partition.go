@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// Partition is a group of AWS regions with its own ARNs, endpoints and credentials
+type Partition struct {
+	ID        string
+	Name      string
+	DNSSuffix string
+	// Regions are the known regions of the partition
+	Regions []string
+	// regionPattern matches region names of the partition, including regions newer than the table
+	regionPattern *regexp.Regexp
+}
+
+// Partitions is the partition table, taken from the endpoint rules of the AWS SDK
+var Partitions = []Partition{
+	{
+		ID:        "aws",
+		Name:      "AWS Standard",
+		DNSSuffix: "amazonaws.com",
+		Regions: []string{
+			"af-south-1", "ap-east-1", "ap-east-2", "ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
+			"ap-south-1", "ap-south-2", "ap-southeast-1", "ap-southeast-2", "ap-southeast-3", "ap-southeast-4",
+			"ap-southeast-5", "ap-southeast-7", "ca-central-1", "ca-west-1", "eu-central-1", "eu-central-2",
+			"eu-north-1", "eu-south-1", "eu-south-2", "eu-west-1", "eu-west-2", "eu-west-3", "il-central-1",
+			"me-central-1", "me-south-1", "mx-central-1", "sa-east-1", "us-east-1", "us-east-2", "us-west-1", "us-west-2",
+		},
+		regionPattern: regexp.MustCompile(`^(us|eu|ap|sa|ca|me|af|il|mx)-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-cn",
+		Name:          "AWS China",
+		DNSSuffix:     "amazonaws.com.cn",
+		Regions:       []string{"cn-north-1", "cn-northwest-1"},
+		regionPattern: regexp.MustCompile(`^cn-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-us-gov",
+		Name:          "AWS GovCloud (US)",
+		DNSSuffix:     "amazonaws.com",
+		Regions:       []string{"us-gov-east-1", "us-gov-west-1"},
+		regionPattern: regexp.MustCompile(`^us-gov-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-iso",
+		Name:          "AWS ISO (US)",
+		DNSSuffix:     "c2s.ic.gov",
+		Regions:       []string{"us-iso-east-1", "us-iso-west-1"},
+		regionPattern: regexp.MustCompile(`^us-iso-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-iso-b",
+		Name:          "AWS ISOB (US)",
+		DNSSuffix:     "sc2s.sgov.gov",
+		Regions:       []string{"us-isob-east-1"},
+		regionPattern: regexp.MustCompile(`^us-isob-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-iso-e",
+		Name:          "AWS ISOE (Europe)",
+		DNSSuffix:     "cloud.adc-e.uk",
+		Regions:       []string{"eu-isoe-west-1"},
+		regionPattern: regexp.MustCompile(`^eu-isoe-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-iso-f",
+		Name:          "AWS ISOF",
+		DNSSuffix:     "csp.hci.ic.gov",
+		Regions:       []string{"us-isof-east-1", "us-isof-south-1"},
+		regionPattern: regexp.MustCompile(`^us-isof-\w+-\d+$`),
+	},
+	{
+		ID:            "aws-eusc",
+		Name:          "AWS European Sovereign Cloud",
+		DNSSuffix:     "amazonaws.eu",
+		Regions:       []string{"eusc-de-east-1"},
+		regionPattern: regexp.MustCompile(`^eusc-(de)-\w+-\d+$`),
+	},
+}
+
+// LookupPartition returns the partition with the ID, such as "aws-cn"
+func LookupPartition(id string) (Partition, bool) {
+	for _, p := range Partitions {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Partition{}, false
+}
+
+// PartitionOfRegion returns the partition of a region. Known regions are matched
+// first, then the region name patterns, so regions newer than the table are found too.
+func PartitionOfRegion(region string) (Partition, bool) {
+	for _, p := range Partitions {
+		if slices.Contains(p.Regions, region) {
+			return p, true
+		}
+	}
+	for _, p := range Partitions {
+		if p.regionPattern.MatchString(region) {
+			return p, true
+		}
+	}
+	return Partition{}, false
+}
+
+// ValidateRegion checks that the region belongs to a partition
+func ValidateRegion(region string) error {
+	if _, ok := PartitionOfRegion(region); !ok {
+		return &Error{
+			Type:    ErrorTypeInvalidRegion,
+			Message: fmt.Sprintf("%q is not a region of any AWS partition", region),
+		}
+	}
+	return nil
+}
+
+// PartitionOfARN returns the partition of an ARN such as arn:aws-cn:iam::123456789012:role/Scanner
+func PartitionOfARN(s string) (Partition, error) {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return Partition{}, fmt.Errorf("invalid ARN %q: %w", s, err)
+	}
+
+	p, ok := LookupPartition(parsed.Partition)
+	if !ok {
+		return Partition{}, fmt.Errorf("ARN %q is in the unknown partition %q", s, parsed.Partition)
+	}
+	return p, nil
+}
+
+// RegionOfARN returns the region of an ARN such as a stack ID, or "" when it has none or is not an ARN
+func RegionOfARN(s string) string {
+	parsed, err := arn.Parse(s)
+	if err != nil {
+		return ""
+	}
+	return parsed.Region
+}
+
+// checkRolePartition checks that a role can be assumed for a region: STS is
+// called at the regional endpoint, which only issues credentials of its partition
+func checkRolePartition(role AssumeRoleCredentials, region string) error {
+	if region == "" {
+		return nil
+	}
+
+	rolePartition, err := PartitionOfARN(role.RoleARN)
+	if err != nil {
+		return &Error{Type: ErrorTypeConfig, Message: "invalid role ARN", Cause: err}
+	}
+
+	regionPartition, ok := PartitionOfRegion(region)
+	if ok && regionPartition.ID != rolePartition.ID {
+		return &Error{
+			Type:    ErrorTypeConfig,
+			Message: fmt.Sprintf("role %s is in partition %s, but region %s is in partition %s", role.RoleARN, rolePartition.ID, region, regionPartition.ID),
+		}
+	}
+	return nil
+}
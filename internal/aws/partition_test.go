@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionOfRegion(t *testing.T) {
+	tests := []struct {
+		region        string
+		wantPartition string
+	}{
+		{region: "us-east-1", wantPartition: "aws"},
+		{region: "ap-northeast-1", wantPartition: "aws"},
+		{region: "ap-northeast-9", wantPartition: "aws"}, // newer than the table
+		{region: "cn-north-1", wantPartition: "aws-cn"},
+		{region: "cn-northwest-1", wantPartition: "aws-cn"},
+		{region: "us-gov-west-1", wantPartition: "aws-us-gov"},
+		{region: "us-gov-east-1", wantPartition: "aws-us-gov"},
+		{region: "us-iso-east-1", wantPartition: "aws-iso"},
+		{region: "us-isob-east-1", wantPartition: "aws-iso-b"},
+		{region: "eusc-de-east-1", wantPartition: "aws-eusc"},
+		{region: "moon-base-1"},
+		{region: "us-east"},
+		{region: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			p, ok := PartitionOfRegion(tt.region)
+			assert.Equal(t, tt.wantPartition != "", ok)
+			assert.Equal(t, tt.wantPartition, p.ID)
+
+			err := ValidateRegion(tt.region)
+			if tt.wantPartition != "" {
+				assert.NoError(t, err)
+				return
+			}
+			var awsErr *Error
+			require.True(t, errors.As(err, &awsErr))
+			assert.Equal(t, ErrorTypeInvalidRegion, awsErr.Type)
+		})
+	}
+}
+
+func TestPartitionOfARN(t *testing.T) {
+	tests := []struct {
+		arn           string
+		wantPartition string
+		wantErr       string
+	}{
+		{arn: "arn:aws:iam::123456789012:role/Scanner", wantPartition: "aws"},
+		{arn: "arn:aws-cn:iam::123456789012:role/Scanner", wantPartition: "aws-cn"},
+		{arn: "arn:aws-us-gov:iam::123456789012:role/Scanner", wantPartition: "aws-us-gov"},
+		{arn: "arn:aws-cn:cloudformation:cn-north-1:123456789012:stack/api-dev/0f1e2d3c", wantPartition: "aws-cn"},
+		{arn: "arn:aws-mars:iam::123456789012:role/Scanner", wantErr: "unknown partition"},
+		{arn: "Scanner", wantErr: "invalid ARN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			p, err := PartitionOfARN(tt.arn)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPartition, p.ID)
+		})
+	}
+}
+
+func TestRegionOfARN(t *testing.T) {
+	assert.Equal(t, "cn-north-1", RegionOfARN("arn:aws-cn:cloudformation:cn-north-1:123456789012:stack/api-dev/0f1e2d3c"))
+	assert.Equal(t, "us-gov-west-1", RegionOfARN("arn:aws-us-gov:cloudformation:us-gov-west-1:123456789012:stack/api-dev/0f1e2d3c"))
+	assert.Empty(t, RegionOfARN("arn:aws:iam::123456789012:role/Scanner"))
+	assert.Empty(t, RegionOfARN("api-dev"))
+}
+
+func TestCachingProvider_RolePartition(t *testing.T) {
+	tests := []struct {
+		name    string
+		roleARN string
+		region  string
+		wantErr string
+	}{
+		{name: "commercial", roleARN: "arn:aws:iam::123456789012:role/Scanner", region: "us-east-1"},
+		{name: "china", roleARN: "arn:aws-cn:iam::123456789012:role/Scanner", region: "cn-north-1"},
+		{name: "govcloud", roleARN: "arn:aws-us-gov:iam::123456789012:role/Scanner", region: "us-gov-west-1"},
+		{name: "region from the profile", roleARN: "arn:aws-cn:iam::123456789012:role/Scanner"},
+		{
+			name:    "china role in a commercial region",
+			roleARN: "arn:aws-cn:iam::123456789012:role/Scanner",
+			region:  "us-east-1",
+			wantErr: "role arn:aws-cn:iam::123456789012:role/Scanner is in partition aws-cn, but region us-east-1 is in partition aws",
+		},
+		{name: "invalid role ARN", roleARN: "Scanner", region: "us-east-1", wantErr: "invalid role ARN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &fakeLoader{}
+			var calls []stsCall
+			_, err := newTestProvider(loader, &calls).Config(context.Background(), Target{
+				Region:    tt.region,
+				RoleChain: []AssumeRoleCredentials{{RoleARN: tt.roleARN, SessionName: "scan"}},
+			})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.wantErr)
+			var awsErr *Error
+			require.True(t, errors.As(err, &awsErr))
+			assert.Equal(t, ErrorTypeConfig, awsErr.Type)
+			assert.Empty(t, loader.loads, "nothing is loaded for a target that cannot work")
+		})
+	}
+}
+
+func TestCachingProvider_STSEndpoint(t *testing.T) {
+	tests := []struct {
+		region string
+		want   string
+	}{
+		{region: "us-east-1", want: "https://sts.us-east-1.amazonaws.com"},
+		{region: "cn-north-1", want: "https://sts.cn-north-1.amazonaws.com.cn"},
+		{region: "cn-northwest-1", want: "https://sts.cn-northwest-1.amazonaws.com.cn"},
+		{region: "us-gov-west-1", want: "https://sts.us-gov-west-1.amazonaws.com"},
+	}
+
+	p := NewCachingProvider()
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			// AssumeRole calls the regional endpoint of the scanned region, in its partition
+			client, ok := p.newSTSClient(aws.Config{Region: tt.region}).(*sts.Client)
+			require.True(t, ok)
+
+			options := client.Options()
+			endpoint, err := options.EndpointResolverV2.ResolveEndpoint(context.Background(), sts.EndpointParameters{
+				Region:            aws.String(options.Region),
+				UseGlobalEndpoint: aws.Bool(false),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, endpoint.URI.String())
+
+			partition, ok := PartitionOfRegion(tt.region)
+			require.True(t, ok)
+			assert.Contains(t, endpoint.URI.Host, partition.DNSSuffix)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package aws
+
+// Feature names a group of AWS API calls made for one feature of the tool
+type Feature string
+
+const (
+	// FeatureScan covers the calls of every scan
+	FeatureScan Feature = "scan"
+	// FeatureAssumeRole covers the calls made on a role by --assume-role
+	FeatureAssumeRole Feature = "assume-role"
+	// FeatureDelete covers the calls of the delete subcommand
+	FeatureDelete Feature = "delete"
+	// FeatureDoctor covers the calls of the doctor subcommand beyond those of a scan
+	FeatureDoctor Feature = "doctor"
+)
+
+// ResourceScope describes which resources an IAM action is granted on
+type ResourceScope string
+
+const (
+	// ResourceAny is for actions without resource-level permissions
+	ResourceAny ResourceScope = "any"
+	// ResourceStack is for actions on CloudFormation stacks
+	ResourceStack ResourceScope = "stack"
+	// ResourceDeploymentBucket is for actions on Serverless deployment buckets
+	ResourceDeploymentBucket ResourceScope = "deployment-bucket"
+	// ResourceDeploymentObject is for actions on the objects of Serverless deployment buckets
+	ResourceDeploymentObject ResourceScope = "deployment-object"
+	// ResourceAccount is for actions on the account itself
+	ResourceAccount ResourceScope = "account"
+	// ResourceRole is for actions on the assumed role
+	ResourceRole ResourceScope = "role"
+)
+
+// Actions the trust policy of a role must allow besides sts:AssumeRole when the
+// session is assumed with session tags or a source identity
+const (
+	ActionTagSession        = "sts:TagSession"
+	ActionSetSourceIdentity = "sts:SetSourceIdentity"
+)
+
+// APICall is an AWS API operation called by the tool and the IAM actions it requires
+type APICall struct {
+	// Service is the SDK service package, such as "cloudformation"
+	Service string
+	// Operation is the API operation, such as "ListStacks"
+	Operation string
+	// Actions are the IAM actions the operation requires; empty when it needs no permission
+	Actions  []string
+	Resource ResourceScope
+	Feature  Feature
+}
+
+// APICalls is the registry of every AWS API call the tool makes. IAM policies
+// and permission checks are derived from it, and tests keep it in sync with
+// the client interfaces.
+var APICalls = []APICall{
+	{Service: "cloudformation", Operation: "ListStacks", Actions: []string{"cloudformation:ListStacks"}, Resource: ResourceAny, Feature: FeatureScan},
+	{Service: "cloudformation", Operation: "DescribeStacks", Actions: []string{"cloudformation:DescribeStacks"}, Resource: ResourceStack, Feature: FeatureScan},
+	{Service: "cloudformation", Operation: "DescribeStackResources", Actions: []string{"cloudformation:DescribeStackResources"}, Resource: ResourceStack, Feature: FeatureScan},
+	// GetCallerIdentity works even when a policy denies it
+	{Service: "sts", Operation: "GetCallerIdentity", Resource: ResourceAny, Feature: FeatureScan},
+
+	{Service: "sts", Operation: "AssumeRole", Actions: []string{"sts:AssumeRole"}, Resource: ResourceRole, Feature: FeatureAssumeRole},
+	// AssumeRoleWithWebIdentity is authorized by the trust policy of the role alone
+	{Service: "sts", Operation: "AssumeRoleWithWebIdentity", Resource: ResourceRole, Feature: FeatureAssumeRole},
+
+	{Service: "cloudformation", Operation: "DeleteStack", Actions: []string{"cloudformation:DeleteStack"}, Resource: ResourceStack, Feature: FeatureDelete},
+	{Service: "s3", Operation: "ListObjectVersions", Actions: []string{"s3:ListBucketVersions"}, Resource: ResourceDeploymentBucket, Feature: FeatureDelete},
+	{Service: "s3", Operation: "DeleteObjects", Actions: []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}, Resource: ResourceDeploymentObject, Feature: FeatureDelete},
+
+	{Service: "account", Operation: "GetRegionOptStatus", Actions: []string{"account:GetRegionOptStatus"}, Resource: ResourceAccount, Feature: FeatureDoctor},
+}
+
+// LookupAPICall returns the registry entry of an operation
+func LookupAPICall(service, operation string) (APICall, bool) {
+	for _, call := range APICalls {
+		if call.Service == service && call.Operation == operation {
+			return call, true
+		}
+	}
+	return APICall{}, false
+}
+
+// CallsOf returns the registered calls of a feature, in registry order
+func CallsOf(feature Feature) []APICall {
+	var calls []APICall
+	for _, call := range APICalls {
+		if call.Feature == feature {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
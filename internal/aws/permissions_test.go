@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertRegistered checks that every method of a client interface is in the registry
+func assertRegistered(t *testing.T, service string, api reflect.Type) {
+	t.Helper()
+	for i := 0; i < api.NumMethod(); i++ {
+		operation := api.Method(i).Name
+		_, ok := LookupAPICall(service, operation)
+		assert.True(t, ok, "%s:%s is called but missing from APICalls", service, operation)
+	}
+}
+
+func TestAPICalls_CoverClients(t *testing.T) {
+	assertRegistered(t, "cloudformation", reflect.TypeOf((*CloudFormationAPI)(nil)).Elem())
+	assertRegistered(t, "sts", reflect.TypeOf((*STSAPI)(nil)).Elem())
+	assertRegistered(t, "sts", reflect.TypeOf((*stsCredentialsAPI)(nil)).Elem())
+}
+
+func TestAPICalls(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, call := range APICalls {
+		key := call.Service + ":" + call.Operation
+		assert.False(t, seen[key], "%s is registered twice", key)
+		seen[key] = true
+
+		assert.NotEmpty(t, call.Resource, key)
+		assert.NotEmpty(t, call.Feature, key)
+	}
+
+	call, ok := LookupAPICall("s3", "DeleteObjects")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}, call.Actions)
+
+	_, ok = LookupAPICall("cloudformation", "CreateStack")
+	assert.False(t, ok)
+
+	scan := CallsOf(FeatureScan)
+	assert.Len(t, scan, 4)
+	assert.Equal(t, "ListStacks", scan[0].Operation)
+}
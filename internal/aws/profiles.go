@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// SharedConfigFiles returns the shared config and credentials files the SDK
+// default chain reads, honouring AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE
+func SharedConfigFiles() (configFiles, credentialsFiles []string) {
+	configFiles, credentialsFiles = config.DefaultSharedConfigFiles, config.DefaultSharedCredentialsFiles
+	if file := os.Getenv("AWS_CONFIG_FILE"); file != "" {
+		configFiles = []string{file}
+	}
+	if file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); file != "" {
+		credentialsFiles = []string{file}
+	}
+	return configFiles, credentialsFiles
+}
+
+// ListProfiles returns the sorted names of the profiles in the shared config
+// and credentials files that match glob; "*" matches every profile
+func ListProfiles(glob string) ([]string, error) {
+	if _, err := path.Match(glob, ""); err != nil {
+		return nil, fmt.Errorf("invalid profile glob %q: %w", glob, err)
+	}
+
+	configFiles, credentialsFiles := SharedConfigFiles()
+	names, err := profileNames(configFiles, credentialsFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []string
+	for _, name := range names {
+		if ok, _ := path.Match(glob, name); ok {
+			profiles = append(profiles, name)
+		}
+	}
+	return profiles, nil
+}
+
+// profileNames returns the sorted, distinct profile names of the files. As in
+// the SDK, config file sections other than "default" need the "profile "
+// prefix, and credentials file sections must not have it; missing files are skipped.
+func profileNames(configFiles, credentialsFiles []string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	for _, file := range configFiles {
+		sections, err := iniSections(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range sections {
+			if name, ok := strings.CutPrefix(section, "profile "); ok {
+				seen[strings.TrimSpace(name)] = true
+			} else if section == "default" {
+				seen[section] = true
+			}
+		}
+	}
+
+	for _, file := range credentialsFiles {
+		sections, err := iniSections(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range sections {
+			if !strings.HasPrefix(section, "profile ") {
+				seen[section] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// iniSections returns the section names of an INI file, or none if it does not exist
+func iniSections(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared config file: %w", err)
+	}
+	defer f.Close()
+
+	var sections []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, strings.TrimSpace(line[1:len(line)-1]))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shared config file %s: %w", file, err)
+	}
+	return sections, nil
+}
@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProfiles(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	credentialsFile := filepath.Join(dir, "credentials")
+
+	require.NoError(t, os.WriteFile(configFile, []byte(`[default]
+region = us-east-1
+
+[profile prod-orders]
+sso_session = corp
+
+[profile  prod-billing ]
+sso_session = corp
+
+[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+
+[services local]
+
+# not a profile: config file profiles need the prefix
+[dev-legacy]
+region = us-west-2
+`), 0600))
+	require.NoError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = AKIA
+
+[ci]
+aws_access_key_id = AKIA
+
+; not a profile: credentials file profiles must not have the prefix
+[profile ignored]
+`), 0600))
+
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+
+	tests := []struct {
+		glob    string
+		want    []string
+		wantErr bool
+	}{
+		{glob: "*", want: []string{"ci", "default", "prod-billing", "prod-orders"}},
+		{glob: "prod-*", want: []string{"prod-billing", "prod-orders"}},
+		{glob: "staging-*"},
+		{glob: "[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			got, err := ListProfiles(tt.glob)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestListProfiles_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	got, err := ListProfiles("*")
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
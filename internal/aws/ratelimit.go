@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/smithy-go"
 	"golang.org/x/time/rate"
@@ -75,31 +77,87 @@ func (r *RateLimitedClient) DescribeStackResources(ctx context.Context, params *
 
 // handleError converts AWS errors to our custom error types
 func (r *RateLimitedClient) handleError(err error) error {
+	return ClassifyError(err, r.region)
+}
+
+// ClassifyError converts an AWS error to an *Error with an ErrorType.
+// Errors that are already classified are returned unchanged.
+func ClassifyError(err error, region string) error {
 	if err == nil {
 		return nil
 	}
 
+	var customErr *Error
+	if errors.As(err, &customErr) {
+		return err
+	}
+
+	if isConfigError(err) {
+		return &Error{
+			Type:    ErrorTypeConfig,
+			Message: "invalid AWS configuration",
+			Cause:   err,
+		}
+	}
+
+	var invalidToken *ssocreds.InvalidTokenError
+	if errors.As(err, &invalidToken) {
+		return &Error{
+			Type:    ErrorTypeSSOExpired,
+			Message: "AWS SSO session has expired",
+			Cause:   err,
+		}
+	}
+
 	// Handle AWS service errors
 	var awsErr smithy.APIError
 	if errors.As(err, &awsErr) {
 		switch awsErr.ErrorCode() {
-		case "AccessDenied", "UnauthorizedOperation":
+		case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+			if failedOperation(err, "STS", "AssumeRole") {
+				return &Error{
+					Type:    ErrorTypeAssumeRole,
+					Message: "not allowed to assume the IAM role",
+					Cause:   err,
+				}
+			}
 			return &Error{
 				Type:    ErrorTypePermission,
 				Message: "insufficient AWS permissions",
 				Cause:   err,
 			}
+		case "UnauthorizedException":
+			if failedOperation(err, "SSO", "") {
+				return &Error{
+					Type:    ErrorTypeSSOExpired,
+					Message: "AWS SSO session has expired",
+					Cause:   err,
+				}
+			}
+		case "ExpiredToken", "ExpiredTokenException", "InvalidClientTokenId", "UnrecognizedClientException", "SignatureDoesNotMatch":
+			return &Error{
+				Type:    ErrorTypeCredentials,
+				Message: "AWS credentials are invalid or expired",
+				Cause:   err,
+			}
 		case "Throttling", "RequestLimitExceeded", "TooManyRequestsException":
 			return &Error{
 				Type:    ErrorTypeRateLimit,
 				Message: "AWS API rate limit exceeded",
 				Cause:   err,
 			}
-		case "InvalidParameterValue":
-			if strings.Contains(awsErr.ErrorMessage(), "region") {
+		case "ValidationError", "InvalidParameterValue":
+			if isRegionNotEnabledMessage(awsErr.ErrorMessage()) {
+				return &Error{
+					Type:    ErrorTypeRegionNotEnabled,
+					Message: "AWS region is not enabled for the account: " + region,
+					Cause:   err,
+				}
+			}
+			if awsErr.ErrorCode() == "InvalidParameterValue" && strings.Contains(awsErr.ErrorMessage(), "region") {
 				return &Error{
 					Type:    ErrorTypeInvalidRegion,
-					Message: "invalid AWS region: " + r.region,
+					Message: "invalid AWS region: " + region,
 					Cause:   err,
 				}
 			}
@@ -127,12 +185,6 @@ func (r *RateLimitedClient) handleError(err error) error {
 		}
 	}
 
-	// Check if it's already our custom error type
-	var customErr *Error
-	if errors.As(err, &customErr) {
-		return err
-	}
-
 	// Default to unknown error
 	return &Error{
 		Type:    ErrorTypeUnknown,
@@ -141,6 +193,42 @@ func (r *RateLimitedClient) handleError(err error) error {
 	}
 }
 
+// isConfigError reports whether err comes from reading the shared config files
+func isConfigError(err error) bool {
+	var (
+		notExist    config.SharedConfigProfileNotExistError
+		loadErr     config.SharedConfigLoadError
+		assumeErr   config.SharedConfigAssumeRoleError
+		requiresARN config.CredentialRequiresARNError
+	)
+	return errors.As(err, &notExist) || errors.As(err, &loadErr) ||
+		errors.As(err, &assumeErr) || errors.As(err, &requiresARN)
+}
+
+// failedOperation reports whether any operation in the error chain is the given
+// one; an empty operation matches every operation of the service. Credential
+// providers nest their failures inside the error of the call that needed credentials.
+func failedOperation(err error, service, operation string) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var opErr *smithy.OperationError
+		if !errors.As(err, &opErr) {
+			return false
+		}
+		if opErr.ServiceID == service && (operation == "" || opErr.OperationName == operation) {
+			return true
+		}
+		err = opErr
+	}
+	return false
+}
+
+// isRegionNotEnabledMessage reports whether an error message says the region is not enabled
+func isRegionNotEnabledMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "region") &&
+		(strings.Contains(message, "not enabled") || strings.Contains(message, "opt-in") || strings.Contains(message, "opted in") || strings.Contains(message, "disabled"))
+}
+
 // RetryableClient wraps RateLimitedClient with exponential backoff retry logic
 type RetryableClient struct {
 	client     *RateLimitedClient
@@ -3,11 +3,16 @@ package aws
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRateLimitedClient_RateLimiting(t *testing.T) {
@@ -127,6 +132,52 @@ func TestRetryableClient_NonRetryableError(t *testing.T) {
 	assert.Equal(t, ErrorTypePermission, customErr.Type)
 }
 
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedType ErrorType
+	}{
+		{name: "access denied", err: &smithy.GenericAPIError{Code: "AccessDenied"}, expectedType: ErrorTypePermission},
+		{name: "access denied exception", err: &smithy.GenericAPIError{Code: "AccessDeniedException"}, expectedType: ErrorTypePermission},
+		{name: "expired token", err: &smithy.GenericAPIError{Code: "ExpiredToken"}, expectedType: ErrorTypeCredentials},
+		{name: "invalid client token", err: &smithy.GenericAPIError{Code: "InvalidClientTokenId"}, expectedType: ErrorTypeCredentials},
+		{name: "throttling", err: &smithy.GenericAPIError{Code: "Throttling"}, expectedType: ErrorTypeRateLimit},
+		{name: "region", err: &smithy.GenericAPIError{Code: "InvalidParameterValue", Message: "invalid region"}, expectedType: ErrorTypeInvalidRegion},
+		{name: "network", err: errors.New("dial tcp: lookup cloudformation.xx-east-1.amazonaws.com: no such host"), expectedType: ErrorTypeNetwork},
+		{name: "unknown", err: errors.New("boom"), expectedType: ErrorTypeUnknown},
+		{name: "profile not found", err: fmt.Errorf("load: %w", config.SharedConfigProfileNotExistError{Profile: "dev"}), expectedType: ErrorTypeConfig},
+		{name: "sso token expired", err: &ssocreds.InvalidTokenError{}, expectedType: ErrorTypeSSOExpired},
+		{name: "sso unauthorized", err: &smithy.OperationError{ServiceID: "SSO", OperationName: "GetRoleCredentials", Err: &smithy.GenericAPIError{Code: "UnauthorizedException"}}, expectedType: ErrorTypeSSOExpired},
+		{name: "unauthorized outside sso", err: &smithy.GenericAPIError{Code: "UnauthorizedException"}, expectedType: ErrorTypeUnknown},
+		{
+			name: "assume role denied",
+			err: &smithy.OperationError{
+				ServiceID: "CloudFormation", OperationName: "ListStacks",
+				Err: fmt.Errorf("failed to retrieve credentials: %w", &smithy.OperationError{
+					ServiceID: "STS", OperationName: "AssumeRole",
+					Err: &smithy.GenericAPIError{Code: "AccessDenied"},
+				}),
+			},
+			expectedType: ErrorTypeAssumeRole,
+		},
+		{name: "access denied on other operation", err: &smithy.OperationError{ServiceID: "STS", OperationName: "GetCallerIdentity", Err: &smithy.GenericAPIError{Code: "AccessDenied"}}, expectedType: ErrorTypePermission},
+		{name: "region not enabled", err: &smithy.GenericAPIError{Code: "ValidationError", Message: "The region ap-east-1 is not enabled for this account"}, expectedType: ErrorTypeRegionNotEnabled},
+		{name: "other validation error", err: &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack with id x does not exist"}, expectedType: ErrorTypeUnknown},
+		{name: "already classified", err: &Error{Type: ErrorTypeNetwork, Cause: &smithy.GenericAPIError{Code: "AccessDenied"}}, expectedType: ErrorTypeNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var customErr *Error
+			require.True(t, errors.As(ClassifyError(tt.err, "us-east-1"), &customErr))
+			assert.Equal(t, tt.expectedType, customErr.Type)
+		})
+	}
+
+	assert.NoError(t, ClassifyError(nil, "us-east-1"))
+}
+
 func TestIsRetryableError(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -29,12 +29,52 @@ func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// Hint returns what the user can do about the error
+func (e *Error) Hint() string {
+	return e.Type.Hint()
+}
+
 type ErrorType string
 
 const (
-	ErrorTypePermission    ErrorType = "PERMISSION_DENIED"
-	ErrorTypeInvalidRegion ErrorType = "INVALID_REGION"
-	ErrorTypeRateLimit     ErrorType = "RATE_LIMIT"
-	ErrorTypeNetwork       ErrorType = "NETWORK_ERROR"
-	ErrorTypeUnknown       ErrorType = "UNKNOWN_ERROR"
+	ErrorTypeConfig           ErrorType = "CONFIG_ERROR"
+	ErrorTypeCredentials      ErrorType = "INVALID_CREDENTIALS"
+	ErrorTypeSSOExpired       ErrorType = "SSO_TOKEN_EXPIRED"
+	ErrorTypePermission       ErrorType = "PERMISSION_DENIED"
+	ErrorTypeAssumeRole       ErrorType = "ASSUME_ROLE_DENIED"
+	ErrorTypeInvalidRegion    ErrorType = "INVALID_REGION"
+	ErrorTypeRegionNotEnabled ErrorType = "REGION_NOT_ENABLED"
+	ErrorTypeRateLimit        ErrorType = "RATE_LIMIT"
+	ErrorTypeNetwork          ErrorType = "NETWORK_ERROR"
+	ErrorTypeUnknown          ErrorType = "UNKNOWN_ERROR"
 )
+
+// errorTypeInfo holds the hint and process exit code of an error type.
+// Exit codes are part of the CLI contract: never renumber them.
+var errorTypeInfo = map[ErrorType]struct {
+	hint     string
+	exitCode int
+}{
+	ErrorTypeConfig:           {"Check the profile name with 'aws configure list-profiles' and the shared config files", 10},
+	ErrorTypeCredentials:      {"The credentials were rejected or have expired; refresh them or rotate the access keys", 11},
+	ErrorTypeSSOExpired:       {"The SSO session has expired; run 'aws sso login' for the profile", 12},
+	ErrorTypePermission:       {"Grant the missing permissions; 'find_serverless_stacks iam-policy' prints the policy the tool needs", 13},
+	ErrorTypeAssumeRole:       {"Allow sts:AssumeRole on the role for the caller, and check the trust policy and external ID of the role", 14},
+	ErrorTypeInvalidRegion:    {"Pass a region name such as us-east-1 or eu-west-1", 15},
+	ErrorTypeRegionNotEnabled: {"Enable the opt-in region for the account in the AWS console, or scan another region", 16},
+	ErrorTypeRateLimit:        {"The API is throttling requests; wait and try again", 17},
+	ErrorTypeNetwork:          {"Check the network connection and any HTTPS_PROXY settings", 18},
+}
+
+// Hint returns what the user can do about errors of this type, or "" when there is no advice
+func (t ErrorType) Hint() string {
+	return errorTypeInfo[t].hint
+}
+
+// ExitCode returns the process exit code for errors of this type; unknown errors exit with 1
+func (t ErrorType) ExitCode() int {
+	if info, ok := errorTypeInfo[t]; ok {
+		return info.exitCode
+	}
+	return 1
+}
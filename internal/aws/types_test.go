@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorType_ExitCode(t *testing.T) {
+	tests := []struct {
+		errorType ErrorType
+		expected  int
+	}{
+		{ErrorTypeConfig, 10},
+		{ErrorTypeCredentials, 11},
+		{ErrorTypeSSOExpired, 12},
+		{ErrorTypePermission, 13},
+		{ErrorTypeAssumeRole, 14},
+		{ErrorTypeInvalidRegion, 15},
+		{ErrorTypeRegionNotEnabled, 16},
+		{ErrorTypeRateLimit, 17},
+		{ErrorTypeNetwork, 18},
+		{ErrorTypeUnknown, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.errorType), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.errorType.ExitCode())
+			if tt.errorType == ErrorTypeUnknown {
+				assert.Empty(t, tt.errorType.Hint())
+			} else {
+				assert.NotEmpty(t, tt.errorType.Hint())
+			}
+		})
+	}
+}
+
+func TestError_Hint(t *testing.T) {
+	err := &Error{Type: ErrorTypeSSOExpired, Message: "AWS SSO session has expired", Cause: errors.New("token expired")}
+	assert.Contains(t, err.Hint(), "aws sso login")
+	assert.Equal(t, "AWS SSO session has expired: token expired", err.Error())
+}
@@ -1,6 +1,14 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
 
 // Config holds the application configuration
 type Config struct {
@@ -8,8 +16,56 @@ type Config struct {
 	Region       string
 	OutputFormat string
 
+	// ExplicitProfile loads Profile from the shared config files even when it is
+	// "default", rather than from AWS_PROFILE or environment credentials
+	ExplicitProfile bool
+
+	// ProfileGlob scans the account of every shared config profile matching the
+	// glob instead of Profile; "*" selects every profile
+	ProfileGlob string
+
+	// Template output configuration; Template takes the text inline, TemplateFile reads it from a file
+	Template     string
+	TemplateFile string
+
+	// Query is a JMESPath expression applied to the stacks output document before formatting
+	Query string
+
+	// Columns selects the fields written by the tabular formats
+	Columns []string
+
+	// Fields selects the optional stack fields to include; "all" selects every one
+	Fields []string
+
+	// SchemaVersion is the output document version to write
+	SchemaVersion int
+
+	// SortBy is the field to sort by before the default order; Reverse inverts the order
+	SortBy  string
+	Reverse bool
+
+	// Summary prints aggregate statistics instead of the stacks
+	Summary bool
+	// WithSummary prints aggregate statistics to stderr after the stacks
+	WithSummary bool
+
+	// Filter restricts the stacks by name, tags and timestamps; nil matches every stack
+	Filter *filter.Filter
+
+	// InputPath reads a previously saved JSON output instead of scanning; "-" reads stdin
+	InputPath string
+
 	// AssumeRole configuration
 	AssumeRole *AssumeRoleConfig
+	// SessionFlags lists the assumed role session flags that were set, such as
+	// --source-identity; they have no effect without AssumeRole
+	SessionFlags []string
+
+	// CredentialCacheDir caches assumed-role credentials across runs; empty disables caching
+	CredentialCacheDir string
+
+	// HistoryPath is the scan history database to record into; empty disables recording
+	HistoryPath string
 }
 
 // AssumeRoleConfig holds AssumeRole-specific configuration
@@ -19,16 +75,50 @@ type AssumeRoleConfig struct {
 	Duration    int32  `json:"duration"`
 
 	ExternalID string `json:"externalId,omitempty"`
+
+	// SourceIdentity is recorded in CloudTrail for every action of the session
+	SourceIdentity string `json:"sourceIdentity,omitempty"`
+	// SessionTags are attached to the session, in order
+	SessionTags []SessionTag `json:"sessionTags,omitempty"`
+	// TransitiveTagKeys are the session tags passed on to roles assumed by the session
+	TransitiveTagKeys []string `json:"transitiveTagKeys,omitempty"`
+
+	// PolicyFile is a JSON session policy limiting the permissions of the session
+	PolicyFile string `json:"policyFile,omitempty"`
+	// ReadOnlyPolicy limits the session to the read actions of a scan
+	ReadOnlyPolicy bool `json:"readOnlyPolicy,omitempty"`
+}
+
+// SessionTag is a tag attached to an assumed-role session
+type SessionTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// STS limits on session tags and source identities
+const (
+	maxSessionTags        = 50
+	maxSessionTagKey      = 128
+	maxSessionTagValue    = 256
+	minSourceIdentity     = 2
+	maxSourceIdentity     = 64
+	sourceIdentityPattern = `^[\w+=,.@-]+$`
+)
+
+var sourceIdentityRegexp = regexp.MustCompile(sourceIdentityPattern)
+
+// ParseSessionTag parses a session tag written as key=value
+func ParseSessionTag(s string) (SessionTag, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return SessionTag{}, fmt.Errorf("invalid session tag %q: expected key=value", s)
+	}
+	return SessionTag{Key: key, Value: value}, nil
 }
 
 // ValidateOutputFormat checks if the output format is supported
 func ValidateOutputFormat(format string) bool {
-	switch format {
-	case "json", "tsv":
-		return true
-	default:
-		return false
-	}
+	return output.IsSupportedFormat(format)
 }
 
 // Validate validates the AssumeRole configuration
@@ -37,6 +127,12 @@ func (arc *AssumeRoleConfig) Validate() error {
 		return fmt.Errorf("role ARN cannot be empty when using AssumeRole")
 	}
 
+	// The partition is kept as written: arn:aws-cn and arn:aws-us-gov roles are valid
+	roleARN, err := arn.Parse(arc.RoleARN)
+	if err != nil || roleARN.Service != "iam" || !strings.HasPrefix(roleARN.Resource, "role/") {
+		return fmt.Errorf("role ARN must look like arn:<partition>:iam::<account>:role/<name>, got %q", arc.RoleARN)
+	}
+
 	if arc.Duration < 900 || arc.Duration > 43200 {
 		return fmt.Errorf("session duration must be between 900 and 43200 seconds, got %d", arc.Duration)
 	}
@@ -45,5 +141,50 @@ func (arc *AssumeRoleConfig) Validate() error {
 		return fmt.Errorf("session name cannot be empty")
 	}
 
+	if arc.SourceIdentity != "" {
+		if len(arc.SourceIdentity) < minSourceIdentity || len(arc.SourceIdentity) > maxSourceIdentity || !sourceIdentityRegexp.MatchString(arc.SourceIdentity) {
+			return fmt.Errorf("source identity must be %d-%d characters of letters, digits and +=,.@_-, got %q", minSourceIdentity, maxSourceIdentity, arc.SourceIdentity)
+		}
+	}
+
+	if err := arc.validateSessionTags(); err != nil {
+		return err
+	}
+
+	if arc.PolicyFile != "" && arc.ReadOnlyPolicy {
+		return fmt.Errorf("a session policy file and the read-only session policy cannot be used together")
+	}
+
+	return nil
+}
+
+// validateSessionTags checks the session tags and transitive tag keys against the STS limits
+func (arc *AssumeRoleConfig) validateSessionTags() error {
+	if len(arc.SessionTags) > maxSessionTags {
+		return fmt.Errorf("at most %d session tags are allowed, got %d", maxSessionTags, len(arc.SessionTags))
+	}
+
+	// Tag keys are case-insensitive in STS
+	keys := make(map[string]bool)
+	for _, tag := range arc.SessionTags {
+		if len(tag.Key) > maxSessionTagKey {
+			return fmt.Errorf("session tag key %q is longer than %d characters", tag.Key, maxSessionTagKey)
+		}
+		if len(tag.Value) > maxSessionTagValue {
+			return fmt.Errorf("value of session tag %q is longer than %d characters", tag.Key, maxSessionTagValue)
+		}
+		key := strings.ToLower(tag.Key)
+		if keys[key] {
+			return fmt.Errorf("duplicate session tag key %q", tag.Key)
+		}
+		keys[key] = true
+	}
+
+	for _, key := range arc.TransitiveTagKeys {
+		if !keys[strings.ToLower(key)] {
+			return fmt.Errorf("transitive tag key %q is not a session tag", key)
+		}
+	}
+
 	return nil
 }
@@ -28,8 +28,18 @@ func TestValidateOutputFormat(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:     "yaml format is invalid",
+			name:     "yaml format is valid",
 			format:   "yaml",
+			expected: true,
+		},
+		{
+			name:     "markdown format is valid",
+			format:   "markdown",
+			expected: true,
+		},
+		{
+			name:     "toml format is invalid",
+			format:   "toml",
 			expected: false,
 		},
 		{
@@ -48,9 +58,9 @@ func TestValidateOutputFormat(t *testing.T) {
 			expected: false,
 		},
 		{
-			name:     "csv format is invalid",
+			name:     "csv format is valid",
 			format:   "csv",
-			expected: false,
+			expected: true,
 		},
 	}
 
@@ -139,6 +149,44 @@ func TestAssumeRoleConfig_Validate(t *testing.T) {
 			expectError: true,
 			errorText:   "role ARN cannot be empty",
 		},
+		{
+			name: "china role",
+			config: AssumeRoleConfig{
+				RoleARN:     "arn:aws-cn:iam::123456789012:role/TestRole",
+				SessionName: "test-session",
+				Duration:    3600,
+			},
+			expectError: false,
+		},
+		{
+			name: "govcloud role",
+			config: AssumeRoleConfig{
+				RoleARN:     "arn:aws-us-gov:iam::123456789012:role/TestRole",
+				SessionName: "test-session",
+				Duration:    3600,
+			},
+			expectError: false,
+		},
+		{
+			name: "role name instead of ARN",
+			config: AssumeRoleConfig{
+				RoleARN:     "TestRole",
+				SessionName: "test-session",
+				Duration:    3600,
+			},
+			expectError: true,
+			errorText:   "role ARN must look like",
+		},
+		{
+			name: "user ARN",
+			config: AssumeRoleConfig{
+				RoleARN:     "arn:aws:iam::123456789012:user/TestUser",
+				SessionName: "test-session",
+				Duration:    3600,
+			},
+			expectError: true,
+			errorText:   "role ARN must look like",
+		},
 		{
 			name: "empty session name",
 			config: AssumeRoleConfig{
@@ -207,6 +255,64 @@ func TestAssumeRoleConfig_Validate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid session tags and source identity",
+			config: AssumeRoleConfig{
+				RoleARN:           "arn:aws:iam::123456789012:role/TestRole",
+				SessionName:       "test-session",
+				Duration:          3600,
+				SourceIdentity:    "alice@example.com",
+				SessionTags:       []SessionTag{{Key: "Team", Value: "platform"}, {Key: "Ticket", Value: ""}},
+				TransitiveTagKeys: []string{"team"},
+			},
+			expectError: false,
+		},
+		{
+			name: "invalid source identity",
+			config: AssumeRoleConfig{
+				RoleARN:        "arn:aws:iam::123456789012:role/TestRole",
+				SessionName:    "test-session",
+				Duration:       3600,
+				SourceIdentity: "alice smith",
+			},
+			expectError: true,
+			errorText:   "source identity must be",
+		},
+		{
+			name: "duplicate session tag keys",
+			config: AssumeRoleConfig{
+				RoleARN:     "arn:aws:iam::123456789012:role/TestRole",
+				SessionName: "test-session",
+				Duration:    3600,
+				SessionTags: []SessionTag{{Key: "Team", Value: "a"}, {Key: "team", Value: "b"}},
+			},
+			expectError: true,
+			errorText:   "duplicate session tag key",
+		},
+		{
+			name: "transitive key without tag",
+			config: AssumeRoleConfig{
+				RoleARN:           "arn:aws:iam::123456789012:role/TestRole",
+				SessionName:       "test-session",
+				Duration:          3600,
+				SessionTags:       []SessionTag{{Key: "Team", Value: "a"}},
+				TransitiveTagKeys: []string{"Project"},
+			},
+			expectError: true,
+			errorText:   "is not a session tag",
+		},
+		{
+			name: "policy file with read-only policy",
+			config: AssumeRoleConfig{
+				RoleARN:        "arn:aws:iam::123456789012:role/TestRole",
+				SessionName:    "test-session",
+				Duration:       3600,
+				PolicyFile:     "policy.json",
+				ReadOnlyPolicy: true,
+			},
+			expectError: true,
+			errorText:   "cannot be used together",
+		},
 	}
 
 	for _, tt := range tests {
@@ -280,3 +386,29 @@ func TestConfig_WithAssumeRole(t *testing.T) {
 		assert.True(t, ValidateOutputFormat(config.OutputFormat))
 	})
 }
+
+func TestParseSessionTag(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    SessionTag
+		expectError bool
+	}{
+		{input: "Team=platform", expected: SessionTag{Key: "Team", Value: "platform"}},
+		{input: "Ticket=", expected: SessionTag{Key: "Ticket"}},
+		{input: "Expr=a=b", expected: SessionTag{Key: "Expr", Value: "a=b"}},
+		{input: "Team", expectError: true},
+		{input: "=platform", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tag, err := ParseSessionTag(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tag)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cftypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// deploymentBucketLogicalID is the logical ID of the bucket Serverless Framework deploys through
+const deploymentBucketLogicalID = "ServerlessDeploymentBucket"
+
+// deleteBatchSize is the maximum number of keys DeleteObjects accepts
+const deleteBatchSize = 1000
+
+// CloudFormationAPI defines the CloudFormation operations needed to delete stacks
+// This interface enables mocking for testing
+type CloudFormationAPI interface {
+	DescribeStacks(ctx context.Context, params *cloudformation.DescribeStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStacksOutput, error)
+	DescribeStackResources(ctx context.Context, params *cloudformation.DescribeStackResourcesInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStackResourcesOutput, error)
+	DeleteStack(ctx context.Context, params *cloudformation.DeleteStackInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DeleteStackOutput, error)
+}
+
+// S3API defines the S3 operations needed to empty a deployment bucket
+// This interface enables mocking for testing
+type S3API interface {
+	ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+}
+
+// Clients holds the service clients for one region
+type Clients struct {
+	CloudFormation CloudFormationAPI
+	S3             S3API
+}
+
+// ClientFactory creates the service clients for a region
+type ClientFactory func(ctx context.Context, region string) (Clients, error)
+
+// Target identifies a stack to delete
+type Target struct {
+	StackName string `json:"stackName"`
+	// StackID pins the target to the stack that was reported; empty accepts any stack with the name
+	StackID string `json:"stackId"`
+	Region  string `json:"region"`
+}
+
+// PlannedStack describes what deleting a stack involves
+type PlannedStack struct {
+	Target
+	// Bucket is the deployment bucket emptied before the stack is deleted; empty when there is none
+	Bucket string `json:"bucket,omitempty"`
+	// ObjectVersions counts the object versions and delete markers in Bucket
+	ObjectVersions int `json:"objectVersions"`
+	// Refused explains why the stack will not be deleted; empty when it will be
+	Refused string `json:"refused,omitempty"`
+}
+
+// Plan lists the stacks considered for deletion
+type Plan struct {
+	Stacks []PlannedStack `json:"stacks"`
+}
+
+// Deletable returns the planned stacks that are not refused
+func (p *Plan) Deletable() []PlannedStack {
+	var deletable []PlannedStack
+	for _, stack := range p.Stacks {
+		if stack.Refused == "" {
+			deletable = append(deletable, stack)
+		}
+	}
+	return deletable
+}
+
+// Result records the outcome of deleting one stack
+type Result struct {
+	Stack          PlannedStack
+	DeletedObjects int
+	Err            error
+}
+
+// Deleter empties deployment buckets and deletes stacks
+type Deleter struct {
+	factory ClientFactory
+	// WaitTimeout is the longest to wait for one stack to be deleted
+	WaitTimeout time.Duration
+	// waitOptions adjust the stack deletion waiter; tests use them to avoid delays
+	waitOptions []func(*cloudformation.StackDeleteCompleteWaiterOptions)
+
+	mu      sync.Mutex
+	clients map[string]Clients
+}
+
+// NewDeleter creates a deleter that creates clients per region with factory
+func NewDeleter(factory ClientFactory) *Deleter {
+	return &Deleter{
+		factory:     factory,
+		WaitTimeout: 30 * time.Minute,
+		clients:     make(map[string]Clients),
+	}
+}
+
+// clientsFor returns the clients for region, creating them on first use
+func (d *Deleter) clientsFor(ctx context.Context, region string) (Clients, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if clients, ok := d.clients[region]; ok {
+		return clients, nil
+	}
+
+	clients, err := d.factory(ctx, region)
+	if err != nil {
+		return Clients{}, fmt.Errorf("failed to create clients for %s: %w", region, err)
+	}
+	d.clients[region] = clients
+	return clients, nil
+}
+
+// Plan checks each target against its current state and counts what would be removed.
+// Stacks that no longer match the target, are protected or nested are refused.
+func (d *Deleter) Plan(ctx context.Context, targets []Target) (*Plan, error) {
+	plan := &Plan{Stacks: make([]PlannedStack, 0, len(targets))}
+
+	for _, target := range targets {
+		planned, err := d.planStack(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan deletion of %s: %w", target.StackName, err)
+		}
+		plan.Stacks = append(plan.Stacks, planned)
+	}
+
+	return plan, nil
+}
+
+// planStack describes the deletion of one stack
+func (d *Deleter) planStack(ctx context.Context, target Target) (PlannedStack, error) {
+	planned := PlannedStack{Target: target}
+
+	clients, err := d.clientsFor(ctx, target.Region)
+	if err != nil {
+		return planned, err
+	}
+
+	stack, err := describeStack(ctx, clients.CloudFormation, target.StackName)
+	if err != nil {
+		return planned, err
+	}
+	if stack == nil {
+		planned.Refused = "stack does not exist"
+		return planned, nil
+	}
+
+	if refused := refusal(target, stack); refused != "" {
+		planned.Refused = refused
+		return planned, nil
+	}
+	// Pin the plan to this stack so a stack recreated under the same name is not deleted
+	planned.StackID = aws.ToString(stack.StackId)
+
+	planned.Bucket, err = deploymentBucket(ctx, clients.CloudFormation, planned.StackID)
+	if err != nil {
+		return planned, err
+	}
+
+	if planned.Bucket != "" {
+		versions, err := listObjectVersions(ctx, clients.S3, planned.Bucket)
+		if err != nil {
+			return planned, err
+		}
+		planned.ObjectVersions = len(versions)
+	}
+
+	return planned, nil
+}
+
+// refusal returns why the stack must not be deleted, or "" if it may be
+func refusal(target Target, stack *cftypes.Stack) string {
+	switch {
+	case target.StackID != "" && aws.ToString(stack.StackId) != target.StackID:
+		return "stack ID differs from the report; the stack was recreated"
+	case aws.ToBool(stack.EnableTerminationProtection):
+		return "termination protection is enabled"
+	case stack.ParentId != nil:
+		return "nested stack; delete its root stack instead"
+	case isInProgress(stack.StackStatus):
+		return fmt.Sprintf("stack is %s", stack.StackStatus)
+	default:
+		return ""
+	}
+}
+
+// isInProgress reports whether the stack is in the middle of an operation
+func isInProgress(status cftypes.StackStatus) bool {
+	switch status {
+	case cftypes.StackStatusCreateInProgress,
+		cftypes.StackStatusDeleteInProgress,
+		cftypes.StackStatusUpdateInProgress,
+		cftypes.StackStatusUpdateCompleteCleanupInProgress,
+		cftypes.StackStatusUpdateRollbackInProgress,
+		cftypes.StackStatusUpdateRollbackCompleteCleanupInProgress,
+		cftypes.StackStatusRollbackInProgress,
+		cftypes.StackStatusReviewInProgress,
+		cftypes.StackStatusImportInProgress,
+		cftypes.StackStatusImportRollbackInProgress:
+		return true
+	default:
+		return false
+	}
+}
+
+// Execute deletes the deletable stacks of the plan one by one: it empties the
+// deployment bucket, deletes the stack and waits until the deletion completes.
+// A failure is recorded and the remaining stacks are still processed.
+func (d *Deleter) Execute(ctx context.Context, plan *Plan, progress func(Result)) []Result {
+	var results []Result
+
+	for _, stack := range plan.Deletable() {
+		result := d.deleteStack(ctx, stack)
+		if progress != nil {
+			progress(result)
+		}
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// deleteStack empties the bucket of one stack and deletes the stack
+func (d *Deleter) deleteStack(ctx context.Context, stack PlannedStack) Result {
+	result := Result{Stack: stack}
+
+	clients, err := d.clientsFor(ctx, stack.Region)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	// CloudFormation cannot delete a bucket that still holds objects
+	if stack.Bucket != "" {
+		result.DeletedObjects, err = emptyBucket(ctx, clients.S3, stack.Bucket)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to empty bucket %s: %w", stack.Bucket, err)
+			return result
+		}
+	}
+
+	_, err = clients.CloudFormation.DeleteStack(ctx, &cloudformation.DeleteStackInput{StackName: aws.String(stack.StackID)})
+	if err != nil {
+		result.Err = fmt.Errorf("failed to delete stack: %w", err)
+		return result
+	}
+
+	// Describe by stack ID, which keeps working after the stack is deleted
+	waiter := cloudformation.NewStackDeleteCompleteWaiter(clients.CloudFormation, d.waitOptions...)
+	err = waiter.Wait(ctx, &cloudformation.DescribeStacksInput{StackName: aws.String(stack.StackID)}, d.WaitTimeout)
+	if err != nil {
+		result.Err = fmt.Errorf("stack deletion did not complete: %w", err)
+	}
+
+	return result
+}
+
+// describeStack returns the current state of the stack, or nil if it does not exist
+func describeStack(ctx context.Context, client CloudFormationAPI, stackName string) (*cftypes.Stack, error) {
+	output, err := client.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{StackName: aws.String(stackName)})
+	if err != nil {
+		// DescribeStacks reports a missing stack as a validation error
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationError" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe stack: %w", err)
+	}
+
+	for i := range output.Stacks {
+		if output.Stacks[i].StackStatus != cftypes.StackStatusDeleteComplete {
+			return &output.Stacks[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// deploymentBucket returns the physical name of the ServerlessDeploymentBucket, or "" if the stack has none
+func deploymentBucket(ctx context.Context, client CloudFormationAPI, stackID string) (string, error) {
+	output, err := client.DescribeStackResources(ctx, &cloudformation.DescribeStackResourcesInput{StackName: aws.String(stackID)})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe stack resources: %w", err)
+	}
+
+	for _, resource := range output.StackResources {
+		if aws.ToString(resource.LogicalResourceId) == deploymentBucketLogicalID && resource.PhysicalResourceId != nil {
+			return *resource.PhysicalResourceId, nil
+		}
+	}
+	return "", nil
+}
+
+// listObjectVersions lists every object version and delete marker in the bucket.
+// A bucket that no longer exists is reported as empty.
+func listObjectVersions(ctx context.Context, client S3API, bucket string) ([]s3types.ObjectIdentifier, error) {
+	var objects []s3types.ObjectIdentifier
+
+	paginator := s3.NewListObjectVersionsPaginator(client, &s3.ListObjectVersionsInput{Bucket: aws.String(bucket)})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchBucket" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list object versions of %s: %w", bucket, err)
+		}
+
+		for _, version := range output.Versions {
+			objects = append(objects, s3types.ObjectIdentifier{Key: version.Key, VersionId: version.VersionId})
+		}
+		for _, marker := range output.DeleteMarkers {
+			objects = append(objects, s3types.ObjectIdentifier{Key: marker.Key, VersionId: marker.VersionId})
+		}
+	}
+
+	return objects, nil
+}
+
+// emptyBucket deletes every object version and delete marker in the bucket
+func emptyBucket(ctx context.Context, client S3API, bucket string) (int, error) {
+	objects, err := listObjectVersions(ctx, client, bucket)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for start := 0; start < len(objects); start += deleteBatchSize {
+		end := min(start+deleteBatchSize, len(objects))
+
+		output, err := client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3types.Delete{Objects: objects[start:end], Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, err
+		}
+		if len(output.Errors) > 0 {
+			first := output.Errors[0]
+			return deleted, fmt.Errorf("failed to delete %d objects, first %s: %s", len(output.Errors), aws.ToString(first.Key), aws.ToString(first.Message))
+		}
+		deleted += end - start
+	}
+
+	return deleted, nil
+}
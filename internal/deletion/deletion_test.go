@@ -0,0 +1,336 @@
+package deletion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cftypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	internalaws "github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCloudFormation keeps stacks by name and deletes them synchronously
+type mockCloudFormation struct {
+	stacks    map[string]*cftypes.Stack
+	buckets   map[string]string
+	deleted   []string
+	deleteErr error
+}
+
+func (m *mockCloudFormation) find(nameOrID string) *cftypes.Stack {
+	for name, stack := range m.stacks {
+		if name == nameOrID || aws.ToString(stack.StackId) == nameOrID {
+			return stack
+		}
+	}
+	return nil
+}
+
+func (m *mockCloudFormation) DescribeStacks(ctx context.Context, params *cloudformation.DescribeStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStacksOutput, error) {
+	stack := m.find(aws.ToString(params.StackName))
+	// Deleted stacks can only be described by stack ID
+	if stack == nil || (stack.StackStatus == cftypes.StackStatusDeleteComplete && aws.ToString(stack.StackId) != aws.ToString(params.StackName)) {
+		return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack does not exist"}
+	}
+	return &cloudformation.DescribeStacksOutput{Stacks: []cftypes.Stack{*stack}}, nil
+}
+
+func (m *mockCloudFormation) DescribeStackResources(ctx context.Context, params *cloudformation.DescribeStackResourcesInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStackResourcesOutput, error) {
+	stack := m.find(aws.ToString(params.StackName))
+	if stack == nil {
+		return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack does not exist"}
+	}
+
+	output := &cloudformation.DescribeStackResourcesOutput{}
+	if bucket, ok := m.buckets[aws.ToString(stack.StackName)]; ok {
+		output.StackResources = []cftypes.StackResource{
+			{LogicalResourceId: aws.String("ServerlessDeploymentBucket"), PhysicalResourceId: aws.String(bucket)},
+		}
+	}
+	return output, nil
+}
+
+func (m *mockCloudFormation) DeleteStack(ctx context.Context, params *cloudformation.DeleteStackInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DeleteStackOutput, error) {
+	if m.deleteErr != nil {
+		return nil, m.deleteErr
+	}
+	stack := m.find(aws.ToString(params.StackName))
+	if stack == nil {
+		return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack does not exist"}
+	}
+	stack.StackStatus = cftypes.StackStatusDeleteComplete
+	m.deleted = append(m.deleted, aws.ToString(params.StackName))
+	return &cloudformation.DeleteStackOutput{}, nil
+}
+
+// mockS3 keeps object versions by bucket
+type mockS3 struct {
+	objects      map[string][]s3types.ObjectIdentifier
+	deleteCalls  int
+	deleteErrors []s3types.Error
+}
+
+func (m *mockS3) ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	objects, ok := m.objects[aws.ToString(params.Bucket)]
+	if !ok {
+		return nil, &smithy.GenericAPIError{Code: "NoSuchBucket", Message: "The specified bucket does not exist"}
+	}
+
+	output := &s3.ListObjectVersionsOutput{}
+	for _, object := range objects {
+		// Delete markers are listed separately from versions
+		if aws.ToString(object.VersionId) == "marker" {
+			output.DeleteMarkers = append(output.DeleteMarkers, s3types.DeleteMarkerEntry{Key: object.Key, VersionId: object.VersionId})
+			continue
+		}
+		output.Versions = append(output.Versions, s3types.ObjectVersion{Key: object.Key, VersionId: object.VersionId})
+	}
+	return output, nil
+}
+
+func (m *mockS3) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	m.deleteCalls++
+	if len(m.deleteErrors) > 0 {
+		return &s3.DeleteObjectsOutput{Errors: m.deleteErrors}, nil
+	}
+
+	bucket := aws.ToString(params.Bucket)
+	remaining := m.objects[bucket][:0]
+	for _, object := range m.objects[bucket] {
+		keep := true
+		for _, deleted := range params.Delete.Objects {
+			if aws.ToString(deleted.Key) == aws.ToString(object.Key) && aws.ToString(deleted.VersionId) == aws.ToString(object.VersionId) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			remaining = append(remaining, object)
+		}
+	}
+	m.objects[bucket] = remaining
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func stackID(name string) string {
+	return fmt.Sprintf("arn:aws:cloudformation:us-east-1:123456789012:stack/%s/1", name)
+}
+
+func testStack(name string) *cftypes.Stack {
+	return &cftypes.Stack{StackName: aws.String(name), StackId: aws.String(stackID(name)), StackStatus: cftypes.StackStatusUpdateComplete}
+}
+
+func objectVersions(count int) []s3types.ObjectIdentifier {
+	objects := make([]s3types.ObjectIdentifier, count)
+	for i := range objects {
+		objects[i] = s3types.ObjectIdentifier{Key: aws.String(fmt.Sprintf("serverless/app/%d.zip", i)), VersionId: aws.String("v1")}
+	}
+	return objects
+}
+
+func newTestDeleter(cf CloudFormationAPI, s3Client S3API) *Deleter {
+	deleter := NewDeleter(func(ctx context.Context, region string) (Clients, error) {
+		return Clients{CloudFormation: cf, S3: s3Client}, nil
+	})
+	deleter.WaitTimeout = time.Second
+	deleter.waitOptions = []func(*cloudformation.StackDeleteCompleteWaiterOptions){
+		func(o *cloudformation.StackDeleteCompleteWaiterOptions) {
+			o.MinDelay = time.Millisecond
+			o.MaxDelay = time.Millisecond
+		},
+	}
+	return deleter
+}
+
+func TestDeleter_Plan(t *testing.T) {
+	protected := testStack("protected-dev")
+	protected.EnableTerminationProtection = aws.Bool(true)
+	nested := testStack("nested-dev")
+	nested.ParentId = aws.String(stackID("parent-dev"))
+	updating := testStack("updating-dev")
+	updating.StackStatus = cftypes.StackStatusUpdateInProgress
+
+	cf := &mockCloudFormation{
+		stacks: map[string]*cftypes.Stack{
+			"orders-dev":    testStack("orders-dev"),
+			"no-bucket-dev": testStack("no-bucket-dev"),
+			"protected-dev": protected,
+			"nested-dev":    nested,
+			"updating-dev":  updating,
+			"recreated-dev": testStack("recreated-dev"),
+		},
+		buckets: map[string]string{"orders-dev": "orders-dev-deploymentbucket"},
+	}
+	s3Client := &mockS3{objects: map[string][]s3types.ObjectIdentifier{
+		"orders-dev-deploymentbucket": append(objectVersions(2), s3types.ObjectIdentifier{Key: aws.String("serverless/app/0.zip"), VersionId: aws.String("marker")}),
+	}}
+
+	plan, err := newTestDeleter(cf, s3Client).Plan(context.Background(), []Target{
+		{StackName: "orders-dev", StackID: stackID("orders-dev"), Region: "us-east-1"},
+		{StackName: "no-bucket-dev", Region: "us-east-1"},
+		{StackName: "protected-dev", Region: "us-east-1"},
+		{StackName: "nested-dev", Region: "us-east-1"},
+		{StackName: "updating-dev", Region: "us-east-1"},
+		{StackName: "recreated-dev", StackID: "arn:aws:cloudformation:us-east-1:123456789012:stack/recreated-dev/0", Region: "us-east-1"},
+		{StackName: "gone-dev", Region: "us-east-1"},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Stacks, 7)
+
+	assert.Equal(t, PlannedStack{
+		Target:         Target{StackName: "orders-dev", StackID: stackID("orders-dev"), Region: "us-east-1"},
+		Bucket:         "orders-dev-deploymentbucket",
+		ObjectVersions: 3,
+	}, plan.Stacks[0])
+
+	// The plan is pinned to the current stack ID
+	assert.Equal(t, stackID("no-bucket-dev"), plan.Stacks[1].StackID)
+	assert.Empty(t, plan.Stacks[1].Bucket)
+
+	assert.Equal(t, "termination protection is enabled", plan.Stacks[2].Refused)
+	assert.Contains(t, plan.Stacks[3].Refused, "nested stack")
+	assert.Equal(t, "stack is UPDATE_IN_PROGRESS", plan.Stacks[4].Refused)
+	assert.Contains(t, plan.Stacks[5].Refused, "stack ID differs")
+	assert.Equal(t, "stack does not exist", plan.Stacks[6].Refused)
+
+	require.Len(t, plan.Deletable(), 2)
+
+	// Planning deletes nothing
+	assert.Empty(t, cf.deleted)
+	assert.Zero(t, s3Client.deleteCalls)
+}
+
+func TestDeleter_Execute(t *testing.T) {
+	cf := &mockCloudFormation{
+		stacks: map[string]*cftypes.Stack{
+			"orders-dev":    testStack("orders-dev"),
+			"protected-dev": testStack("protected-dev"),
+		},
+		buckets: map[string]string{"orders-dev": "orders-dev-deploymentbucket"},
+	}
+	cf.stacks["protected-dev"].EnableTerminationProtection = aws.Bool(true)
+	s3Client := &mockS3{objects: map[string][]s3types.ObjectIdentifier{
+		"orders-dev-deploymentbucket": objectVersions(1500),
+	}}
+	deleter := newTestDeleter(cf, s3Client)
+
+	plan, err := deleter.Plan(context.Background(), []Target{
+		{StackName: "orders-dev", Region: "us-east-1"},
+		{StackName: "protected-dev", Region: "us-east-1"},
+	})
+	require.NoError(t, err)
+
+	var reported []Result
+	results := deleter.Execute(context.Background(), plan, func(result Result) {
+		reported = append(reported, result)
+	})
+
+	require.Len(t, results, 1)
+	assert.Equal(t, results, reported)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, 1500, results[0].DeletedObjects)
+
+	// The bucket is emptied in batches of 1000 before the stack is deleted
+	assert.Equal(t, 2, s3Client.deleteCalls)
+	assert.Empty(t, s3Client.objects["orders-dev-deploymentbucket"])
+
+	// Only the unprotected stack is deleted, by stack ID
+	assert.Equal(t, []string{stackID("orders-dev")}, cf.deleted)
+	assert.Equal(t, cftypes.StackStatusUpdateComplete, cf.stacks["protected-dev"].StackStatus)
+}
+
+func TestDeleter_ExecuteFailures(t *testing.T) {
+	t.Run("objects that cannot be deleted keep the stack", func(t *testing.T) {
+		cf := &mockCloudFormation{
+			stacks:  map[string]*cftypes.Stack{"orders-dev": testStack("orders-dev")},
+			buckets: map[string]string{"orders-dev": "orders-dev-deploymentbucket"},
+		}
+		s3Client := &mockS3{
+			objects:      map[string][]s3types.ObjectIdentifier{"orders-dev-deploymentbucket": objectVersions(1)},
+			deleteErrors: []s3types.Error{{Key: aws.String("serverless/app/0.zip"), Message: aws.String("Access Denied")}},
+		}
+		deleter := newTestDeleter(cf, s3Client)
+
+		plan, err := deleter.Plan(context.Background(), []Target{{StackName: "orders-dev", Region: "us-east-1"}})
+		require.NoError(t, err)
+
+		results := deleter.Execute(context.Background(), plan, nil)
+		require.Len(t, results, 1)
+		require.Error(t, results[0].Err)
+		assert.Contains(t, results[0].Err.Error(), "Access Denied")
+		assert.Empty(t, cf.deleted)
+	})
+
+	t.Run("delete stack error", func(t *testing.T) {
+		cf := &mockCloudFormation{
+			stacks:    map[string]*cftypes.Stack{"orders-dev": testStack("orders-dev")},
+			deleteErr: errors.New("AccessDenied"),
+		}
+		deleter := newTestDeleter(cf, &mockS3{})
+
+		plan, err := deleter.Plan(context.Background(), []Target{{StackName: "orders-dev", Region: "us-east-1"}})
+		require.NoError(t, err)
+
+		results := deleter.Execute(context.Background(), plan, nil)
+		require.Len(t, results, 1)
+		require.Error(t, results[0].Err)
+		assert.Contains(t, results[0].Err.Error(), "failed to delete stack")
+	})
+
+	t.Run("deletion that fails is reported", func(t *testing.T) {
+		cf := &mockCloudFormation{stacks: map[string]*cftypes.Stack{"orders-dev": testStack("orders-dev")}}
+		deleter := newTestDeleter(&deleteFailedCloudFormation{cf}, &mockS3{})
+
+		plan, err := deleter.Plan(context.Background(), []Target{{StackName: "orders-dev", Region: "us-east-1"}})
+		require.NoError(t, err)
+
+		results := deleter.Execute(context.Background(), plan, nil)
+		require.Len(t, results, 1)
+		require.Error(t, results[0].Err)
+		assert.Contains(t, results[0].Err.Error(), "stack deletion did not complete")
+	})
+}
+
+// deleteFailedCloudFormation leaves deleted stacks in DELETE_FAILED
+type deleteFailedCloudFormation struct {
+	*mockCloudFormation
+}
+
+func (m *deleteFailedCloudFormation) DeleteStack(ctx context.Context, params *cloudformation.DeleteStackInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DeleteStackOutput, error) {
+	m.find(aws.ToString(params.StackName)).StackStatus = cftypes.StackStatusDeleteFailed
+	return &cloudformation.DeleteStackOutput{}, nil
+}
+
+func TestDeleter_ClientFactoryError(t *testing.T) {
+	deleter := NewDeleter(func(ctx context.Context, region string) (Clients, error) {
+		return Clients{}, errors.New("no credentials")
+	})
+
+	_, err := deleter.Plan(context.Background(), []Target{{StackName: "orders-dev", Region: "us-east-1"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create clients for us-east-1")
+}
+
+func TestClientInterfaces_Registered(t *testing.T) {
+	apis := map[string]reflect.Type{
+		"cloudformation": reflect.TypeOf((*CloudFormationAPI)(nil)).Elem(),
+		"s3":             reflect.TypeOf((*S3API)(nil)).Elem(),
+	}
+	for service, api := range apis {
+		for i := 0; i < api.NumMethod(); i++ {
+			operation := api.Method(i).Name
+			_, ok := internalaws.LookupAPICall(service, operation)
+			assert.True(t, ok, "%s:%s is called but missing from the API call registry", service, operation)
+		}
+	}
+}
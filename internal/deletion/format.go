@@ -0,0 +1,53 @@
+package deletion
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatPlan describes exactly what the plan would remove and which stacks it refuses
+func FormatPlan(plan *Plan) string {
+	var out strings.Builder
+
+	deletable := plan.Deletable()
+	if len(deletable) > 0 {
+		out.WriteString("Stacks to delete:\n")
+		for _, stack := range deletable {
+			out.WriteString(fmt.Sprintf("  - %s (%s) %s\n", stack.StackName, stack.Region, stack.StackID))
+			if stack.Bucket != "" {
+				out.WriteString(fmt.Sprintf("      empty bucket %s (%d object versions)\n", stack.Bucket, stack.ObjectVersions))
+			}
+		}
+	}
+
+	refused := len(plan.Stacks) - len(deletable)
+	if refused > 0 {
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("Refused:\n")
+		for _, stack := range plan.Stacks {
+			if stack.Refused != "" {
+				out.WriteString(fmt.Sprintf("  - %s (%s): %s\n", stack.StackName, stack.Region, stack.Refused))
+			}
+		}
+	}
+
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+	out.WriteString(fmt.Sprintf("%d stacks to delete, %d refused", len(deletable), refused))
+
+	return out.String()
+}
+
+// FormatResult describes the outcome of deleting one stack
+func FormatResult(result Result) string {
+	if result.Err != nil {
+		return fmt.Sprintf("FAILED  %s (%s): %v", result.Stack.StackName, result.Stack.Region, result.Err)
+	}
+	if result.Stack.Bucket != "" {
+		return fmt.Sprintf("deleted %s (%s), removed %d object versions from %s", result.Stack.StackName, result.Stack.Region, result.DeletedObjects, result.Stack.Bucket)
+	}
+	return fmt.Sprintf("deleted %s (%s)", result.Stack.StackName, result.Stack.Region)
+}
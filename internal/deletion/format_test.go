@@ -0,0 +1,67 @@
+package deletion
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPlan(t *testing.T) {
+	plan := &Plan{Stacks: []PlannedStack{
+		{
+			Target:         Target{StackName: "orders-dev", StackID: "arn:orders-dev", Region: "us-east-1"},
+			Bucket:         "orders-dev-deploymentbucket",
+			ObjectVersions: 12,
+		},
+		{Target: Target{StackName: "users-dev", StackID: "arn:users-dev", Region: "us-east-1"}},
+		{Target: Target{StackName: "prod-api", Region: "us-east-1"}, Refused: "termination protection is enabled"},
+	}}
+
+	expected := `Stacks to delete:
+  - orders-dev (us-east-1) arn:orders-dev
+      empty bucket orders-dev-deploymentbucket (12 object versions)
+  - users-dev (us-east-1) arn:users-dev
+
+Refused:
+  - prod-api (us-east-1): termination protection is enabled
+
+2 stacks to delete, 1 refused`
+	assert.Equal(t, expected, FormatPlan(plan))
+
+	assert.Equal(t, "0 stacks to delete, 0 refused", FormatPlan(&Plan{}))
+}
+
+func TestFormatResult(t *testing.T) {
+	stack := PlannedStack{Target: Target{StackName: "orders-dev", Region: "us-east-1"}}
+	withBucket := stack
+	withBucket.Bucket = "orders-dev-deploymentbucket"
+
+	tests := []struct {
+		name     string
+		result   Result
+		expected string
+	}{
+		{
+			name:     "deleted",
+			result:   Result{Stack: stack},
+			expected: "deleted orders-dev (us-east-1)",
+		},
+		{
+			name:     "deleted with bucket",
+			result:   Result{Stack: withBucket, DeletedObjects: 3},
+			expected: "deleted orders-dev (us-east-1), removed 3 object versions from orders-dev-deploymentbucket",
+		},
+		{
+			name:     "failed",
+			result:   Result{Stack: stack, Err: errors.New("access denied")},
+			expected: "FAILED  orders-dev (us-east-1): access denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FormatResult(tt.result))
+		})
+	}
+}
@@ -3,9 +3,9 @@ package detector
 import (
 	"context"
 	"sync"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
 	"github.com/hassaku63/find-serverless-stacks/internal/models"
 )
 
@@ -22,6 +22,11 @@ type Detector struct {
 	region     string
 	ruleEngine *RuleEngine
 	maxWorkers int
+	filter     *filter.Filter
+
+	mu      sync.Mutex
+	errors  []error
+	scanned int
 }
 
 // NewDetector creates a new stack detector
@@ -34,14 +39,68 @@ func NewDetector(client AWSClient, region string) *Detector {
 	}
 }
 
+// SetFilter restricts detection to stacks matching f; nil disables filtering
+func (d *Detector) SetFilter(f *filter.Filter) {
+	d.filter = f
+}
+
+// RuleNames returns the names of the detection rules in evaluation order
+func (d *Detector) RuleNames() []string {
+	return d.ruleEngine.RuleNames()
+}
+
+// Errors returns the per-stack failures of the last detection. Stacks whose
+// resources could not be read are skipped, so a non-empty result means the
+// detection is incomplete.
+func (d *Detector) Errors() []error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]error(nil), d.errors...)
+}
+
+// Scanned returns the number of active stacks listed by the last detection,
+// including stacks excluded by the filter
+func (d *Detector) Scanned() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.scanned
+}
+
+// recordError stores a per-stack failure; workers call it concurrently
+func (d *Detector) recordError(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errors = append(d.errors, err)
+}
+
 // DetectServerlessStacks identifies all stacks deployed by Serverless Framework v3
 func (d *Detector) DetectServerlessStacks(ctx context.Context) ([]models.Stack, error) {
+	d.mu.Lock()
+	d.errors = nil
+	d.scanned = 0
+	d.mu.Unlock()
+
 	// Get all active stacks
 	summaries, err := d.client.ListActiveStacks(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	d.mu.Lock()
+	d.scanned = len(summaries)
+	d.mu.Unlock()
+
+	// Drop stacks excluded by name or timestamps before any per-stack API calls
+	if d.filter != nil {
+		matched := make([]types.StackSummary, 0, len(summaries))
+		for _, summary := range summaries {
+			if d.filter.MatchSummary(summary) {
+				matched = append(matched, summary)
+			}
+		}
+		summaries = matched
+	}
+
 	return d.processStacksConcurrently(ctx, summaries)
 }
 
@@ -107,7 +166,8 @@ func (d *Detector) processStack(ctx context.Context, summary types.StackSummary)
 	// Get stack resources
 	resources, err := d.client.GetStackResources(ctx, stackName)
 	if err != nil {
-		// Log error but continue processing
+		// Record the error but continue processing the other stacks
+		d.recordError(NewDetectionError(stackName, "DescribeStackResources", err))
 		return nil
 	}
 
@@ -115,6 +175,7 @@ func (d *Detector) processStack(ctx context.Context, summary types.StackSummary)
 	details, err := d.client.GetStackDetails(ctx, stackName)
 	if err != nil {
 		// Continue with basic information if details cannot be retrieved
+		d.recordError(NewDetectionError(stackName, "DescribeStacks", err))
 		details = nil
 	}
 
@@ -122,12 +183,64 @@ func (d *Detector) processStack(ctx context.Context, summary types.StackSummary)
 	isServerless, reasons := d.ruleEngine.Evaluate(resources, details)
 	if isServerless {
 		stack := d.convertToModel(summary, details, reasons)
+		// Tag conditions can only be checked once the details are known
+		if !d.filter.MatchStack(stack) {
+			return nil
+		}
 		return &stack
 	}
 
 	return nil
 }
 
+// noEchoValue is what DescribeStacks returns for NoEcho parameters
+const noEchoValue = "****"
+
+// addStackDetails copies the status, nesting, outputs and parameters of the stack
+func addStackDetails(stack *models.Stack, details *types.Stack) {
+	stack.StackStatus = string(details.StackStatus)
+	// A missing flag means protection is off, which is still written when selected
+	protected := details.EnableTerminationProtection != nil && *details.EnableTerminationProtection
+	stack.TerminationProtection = &protected
+	if details.RoleARN != nil {
+		stack.RoleARN = *details.RoleARN
+	}
+	stack.NotificationARNs = details.NotificationARNs
+	if details.DriftInformation != nil {
+		stack.DriftStatus = string(details.DriftInformation.StackDriftStatus)
+	}
+	if details.ParentId != nil {
+		stack.ParentID = *details.ParentId
+	}
+	if details.RootId != nil {
+		stack.RootID = *details.RootId
+	}
+	for _, capability := range details.Capabilities {
+		stack.Capabilities = append(stack.Capabilities, string(capability))
+	}
+
+	for _, output := range details.Outputs {
+		if output.OutputKey == nil || output.OutputValue == nil {
+			continue
+		}
+		if stack.Outputs == nil {
+			stack.Outputs = make(map[string]string)
+		}
+		stack.Outputs[*output.OutputKey] = *output.OutputValue
+	}
+
+	for _, parameter := range details.Parameters {
+		// Secrets are masked by CloudFormation and are left out rather than reported as "****"
+		if parameter.ParameterKey == nil || parameter.ParameterValue == nil || *parameter.ParameterValue == noEchoValue {
+			continue
+		}
+		if stack.Parameters == nil {
+			stack.Parameters = make(map[string]string)
+		}
+		stack.Parameters[*parameter.ParameterKey] = *parameter.ParameterValue
+	}
+}
+
 // hasServerlessDeploymentBucket checks if the stack contains the ServerlessDeploymentBucket resource
 func hasServerlessDeploymentBucket(resources []types.StackResource) bool {
 	for _, resource := range resources {
@@ -156,7 +269,16 @@ func (d *Detector) convertToModel(summary types.StackSummary, details *types.Sta
 		stack.StackID = *summary.StackId
 	}
 
+	// Timestamps from the summary, overridden below by the details when present
+	if summary.CreationTime != nil {
+		stack.CreatedAt = *summary.CreationTime
+	}
+	if summary.LastUpdatedTime != nil {
+		stack.UpdatedAt = *summary.LastUpdatedTime
+	}
+
 	// Add detailed information if available
+	stack.StackTags = make(map[string]string)
 	if details != nil {
 		if details.Description != nil {
 			stack.Description = *details.Description
@@ -169,30 +291,21 @@ func (d *Detector) convertToModel(summary types.StackSummary, details *types.Sta
 		}
 
 		// Convert tags
-		stack.StackTags = make(map[string]string)
 		for _, tag := range details.Tags {
 			if tag.Key != nil && tag.Value != nil {
 				stack.StackTags[*tag.Key] = *tag.Value
 			}
 		}
+
+		addStackDetails(&stack, details)
 	} else {
-		// Use summary information if details are not available
-		if summary.CreationTime != nil {
-			stack.CreatedAt = *summary.CreationTime
-		}
-		if summary.LastUpdatedTime != nil {
-			stack.UpdatedAt = *summary.LastUpdatedTime
-		}
-		stack.StackTags = make(map[string]string)
+		stack.StackStatus = string(summary.StackStatus)
 	}
 
-	// Ensure timestamps are not zero if not set
-	if stack.CreatedAt.IsZero() {
-		stack.CreatedAt = time.Now().UTC()
-	}
-	if stack.UpdatedAt.IsZero() {
-		stack.UpdatedAt = stack.CreatedAt
-	}
+	// CloudFormation omits LastUpdatedTime for stacks that were never updated.
+	// Unknown timestamps stay zero rather than being filled with made-up values,
+	// and a stack without a known creation time is not known to be never updated.
+	stack.NeverUpdated = !stack.CreatedAt.IsZero() && stack.UpdatedAt.IsZero()
 
 	return stack
 }
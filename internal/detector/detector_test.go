@@ -286,6 +286,116 @@ func TestHasServerlessDeploymentBucket(t *testing.T) {
 	}
 }
 
+func TestDetector_ConvertToModel_Timestamps(t *testing.T) {
+	detector := NewDetector(&mockAWSClient{}, "us-east-1")
+	created := mustParseTime("2023-01-15T10:30:00Z")
+	updated := mustParseTime("2023-02-01T08:00:00Z")
+
+	tests := []struct {
+		name              string
+		summary           types.StackSummary
+		details           *types.Stack
+		expectCreated     time.Time
+		expectUpdated     time.Time
+		expectNeverUpdate bool
+	}{
+		{
+			name:          "updated stack",
+			summary:       types.StackSummary{StackName: aws.String("a")},
+			details:       &types.Stack{CreationTime: aws.Time(created), LastUpdatedTime: aws.Time(updated)},
+			expectCreated: created,
+			expectUpdated: updated,
+		},
+		{
+			name:              "never updated stack",
+			summary:           types.StackSummary{StackName: aws.String("a")},
+			details:           &types.Stack{CreationTime: aws.Time(created)},
+			expectCreated:     created,
+			expectNeverUpdate: true,
+		},
+		{
+			name:          "details missing times fall back to the summary",
+			summary:       types.StackSummary{StackName: aws.String("a"), CreationTime: aws.Time(created), LastUpdatedTime: aws.Time(updated)},
+			details:       &types.Stack{},
+			expectCreated: created,
+			expectUpdated: updated,
+		},
+		{
+			// Unknown is not the same as never updated
+			name:    "unknown times are not fabricated",
+			summary: types.StackSummary{StackName: aws.String("a")},
+		},
+		{
+			name:    "no timestamps in the summary or the details",
+			summary: types.StackSummary{StackName: aws.String("a")},
+			details: &types.Stack{},
+		},
+		{
+			name:          "updated stack with unknown creation time",
+			summary:       types.StackSummary{StackName: aws.String("a")},
+			details:       &types.Stack{LastUpdatedTime: aws.Time(updated)},
+			expectUpdated: updated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack := detector.convertToModel(tt.summary, tt.details, nil)
+			assert.Equal(t, tt.expectCreated, stack.CreatedAt)
+			assert.Equal(t, tt.expectUpdated, stack.UpdatedAt)
+			assert.Equal(t, tt.expectNeverUpdate, stack.NeverUpdated)
+		})
+	}
+}
+
+func TestDetector_ConvertToModel_Details(t *testing.T) {
+	detector := NewDetector(&mockAWSClient{}, "us-east-1")
+
+	details := &types.Stack{
+		StackName:                   aws.String("orders-api-dev"),
+		StackStatus:                 types.StackStatusUpdateComplete,
+		EnableTerminationProtection: aws.Bool(true),
+		RoleARN:                     aws.String("arn:aws:iam::123456789012:role/cfn-exec"),
+		NotificationARNs:            []string{"arn:aws:sns:us-east-1:123456789012:cfn-events"},
+		DriftInformation:            &types.StackDriftInformation{StackDriftStatus: types.StackDriftStatusDrifted},
+		ParentId:                    aws.String("arn:aws:cloudformation:us-east-1:123456789012:stack/parent/1"),
+		RootId:                      aws.String("arn:aws:cloudformation:us-east-1:123456789012:stack/root/1"),
+		Capabilities:                []types.Capability{types.CapabilityCapabilityIam, types.CapabilityCapabilityNamedIam},
+		Outputs: []types.Output{
+			{OutputKey: aws.String("ServiceEndpoint"), OutputValue: aws.String("https://abc.execute-api.us-east-1.amazonaws.com/dev")},
+			{OutputKey: aws.String("ServerlessDeploymentBucketName"), OutputValue: aws.String("orders-api-dev-deploymentbucket")},
+		},
+		Parameters: []types.Parameter{
+			{ParameterKey: aws.String("Stage"), ParameterValue: aws.String("dev")},
+			{ParameterKey: aws.String("DbPassword"), ParameterValue: aws.String("****")},
+		},
+	}
+
+	stack := detector.convertToModel(types.StackSummary{StackName: aws.String("orders-api-dev")}, details, nil)
+
+	assert.Equal(t, "UPDATE_COMPLETE", stack.StackStatus)
+	require.NotNil(t, stack.TerminationProtection)
+	assert.True(t, *stack.TerminationProtection)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/cfn-exec", stack.RoleARN)
+	assert.Equal(t, []string{"arn:aws:sns:us-east-1:123456789012:cfn-events"}, stack.NotificationARNs)
+	assert.Equal(t, "DRIFTED", stack.DriftStatus)
+	assert.Equal(t, "arn:aws:cloudformation:us-east-1:123456789012:stack/parent/1", stack.ParentID)
+	assert.Equal(t, "arn:aws:cloudformation:us-east-1:123456789012:stack/root/1", stack.RootID)
+	assert.Equal(t, []string{"CAPABILITY_IAM", "CAPABILITY_NAMED_IAM"}, stack.Capabilities)
+	assert.Equal(t, map[string]string{
+		"ServiceEndpoint":                "https://abc.execute-api.us-east-1.amazonaws.com/dev",
+		"ServerlessDeploymentBucketName": "orders-api-dev-deploymentbucket",
+	}, stack.Outputs)
+	// NoEcho parameters are left out
+	assert.Equal(t, map[string]string{"Stage": "dev"}, stack.Parameters)
+
+	// Without details only the summary status is known
+	stack = detector.convertToModel(types.StackSummary{StackName: aws.String("a"), StackStatus: types.StackStatusCreateComplete}, nil, nil)
+	assert.Equal(t, "CREATE_COMPLETE", stack.StackStatus)
+	assert.Nil(t, stack.Outputs)
+	assert.Nil(t, stack.Parameters)
+}
+
 // Helper function to parse time for tests
 func mustParseTime(s string) time.Time {
 	t, err := time.Parse("2006-01-02T15:04:05Z", s)
@@ -121,6 +121,14 @@ func TestDetector_GetResourcesError(t *testing.T) {
 	// Should not fail completely, just skip problematic stacks
 	require.NoError(t, err)
 	assert.Empty(t, stacks) // No stacks detected due to resource access failure
+
+	// The skipped stack is reported
+	detectionErrors := detector.Errors()
+	require.Len(t, detectionErrors, 1)
+	var detectionErr *DetectionError
+	require.ErrorAs(t, detectionErrors[0], &detectionErr)
+	assert.Equal(t, "test-stack", detectionErr.StackName)
+	assert.Equal(t, "DescribeStackResources", detectionErr.Operation)
 }
 
 func TestDetector_GetDetailsError(t *testing.T) {
@@ -143,6 +151,10 @@ func TestDetector_GetDetailsError(t *testing.T) {
 	assert.Equal(t, "test-stack", stack.StackName)
 	assert.Equal(t, "us-east-1", stack.Region)
 	assert.Contains(t, stack.Reasons, "Contains resource with logical ID 'ServerlessDeploymentBucket'")
+
+	// The partial failure is reported
+	require.Len(t, detector.Errors(), 1)
+	assert.Contains(t, detector.Errors()[0].Error(), "DescribeStacks")
 }
 
 func TestDetector_InconsistentBehavior(t *testing.T) {
@@ -0,0 +1,77 @@
+package detector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/hassaku63/find-serverless-stacks/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func filterTestClient() *mockSlowAWSClient {
+	bucket := []types.StackResource{
+		{
+			LogicalResourceId: aws.String("ServerlessDeploymentBucket"),
+			ResourceType:      aws.String("AWS::S3::Bucket"),
+		},
+	}
+
+	return &mockSlowAWSClient{
+		stacks: []types.StackSummary{
+			{StackName: aws.String("orders-api-dev"), CreationTime: aws.Time(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))},
+			{StackName: aws.String("orders-api-prod"), CreationTime: aws.Time(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+			{StackName: aws.String("billing-prod"), CreationTime: aws.Time(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+		},
+		resources: map[string][]types.StackResource{
+			"orders-api-dev":  bucket,
+			"orders-api-prod": bucket,
+			"billing-prod":    bucket,
+		},
+		details: map[string]*types.Stack{
+			"orders-api-prod": {Tags: []types.Tag{{Key: aws.String("Owner"), Value: aws.String("team-a")}}},
+			"billing-prod":    {Tags: []types.Tag{{Key: aws.String("Owner"), Value: aws.String("team-b")}}},
+		},
+	}
+}
+
+func TestDetector_SummaryFilterSkipsAPICalls(t *testing.T) {
+	client := filterTestClient()
+	f, err := filter.New(filter.Options{NameGlob: "orders-*", CreatedAfter: "2023-06-01"})
+	require.NoError(t, err)
+
+	detector := NewDetector(client, "us-east-1")
+	detector.SetFilter(f)
+
+	stacks, err := detector.DetectServerlessStacks(context.Background())
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	assert.Equal(t, "orders-api-prod", stacks[0].StackName)
+
+	// One ListStacks call plus resources and details for the single remaining stack
+	assert.Equal(t, 3, client.getCallCount())
+
+	// Stacks excluded by the filter still count as scanned
+	assert.Equal(t, 3, detector.Scanned())
+}
+
+func TestDetector_TagFilter(t *testing.T) {
+	client := filterTestClient()
+	f, err := filter.New(filter.Options{Tags: []string{"Owner!=team-b"}})
+	require.NoError(t, err)
+
+	detector := NewDetector(client, "us-east-1")
+	detector.SetFilter(f)
+
+	stacks, err := detector.DetectServerlessStacks(context.Background())
+	require.NoError(t, err)
+
+	var names []string
+	for _, stack := range stacks {
+		names = append(names, stack.StackName)
+	}
+	assert.ElementsMatch(t, []string{"orders-api-dev", "orders-api-prod"}, names)
+}
@@ -41,6 +41,15 @@ func (re *RuleEngine) AddRule(rule DetectionRule) {
 	re.rules = append(re.rules, rule)
 }
 
+// RuleNames returns the names of the registered rules in evaluation order
+func (re *RuleEngine) RuleNames() []string {
+	names := make([]string, len(re.rules))
+	for i, rule := range re.rules {
+		names[i] = rule.Name()
+	}
+	return names
+}
+
 // Evaluate runs all rules against the given stack data
 func (re *RuleEngine) Evaluate(resources []types.StackResource, details *types.Stack) (bool, []string) {
 	var reasons []string
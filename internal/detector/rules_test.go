@@ -81,6 +81,7 @@ func TestRuleEngine_AddRule(t *testing.T) {
 
 	engine.AddRule(mockRule)
 	assert.Len(t, engine.rules, initialCount+1)
+	assert.Equal(t, []string{"ServerlessDeploymentBucket", "MockRule"}, engine.RuleNames())
 }
 
 func TestRuleEngine_Evaluate(t *testing.T) {
@@ -0,0 +1,220 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// ChangeType identifies the kind of difference found for a stack
+type ChangeType string
+
+const (
+	ChangeAdded        ChangeType = "added"
+	ChangeRemoved      ChangeType = "removed"
+	ChangeUpdated      ChangeType = "updated"
+	ChangeTagsChanged  ChangeType = "tagsChanged"
+	ChangeReclassified ChangeType = "reclassified"
+)
+
+// TagChange describes a single tag that differs between two scans
+type TagChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// StackChange describes all differences found for one stack, keyed by stack ID
+type StackChange struct {
+	StackID    string        `json:"stackId"`
+	StackName  string        `json:"stackName"`
+	Region     string        `json:"region"`
+	Changes    []ChangeType  `json:"changes"`
+	TagChanges []TagChange   `json:"tagChanges,omitempty"`
+	Before     *models.Stack `json:"before,omitempty"`
+	After      *models.Stack `json:"after,omitempty"`
+}
+
+// Has reports whether the stack change includes the given change type
+func (c StackChange) Has(changeType ChangeType) bool {
+	for _, change := range c.Changes {
+		if change == changeType {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary counts the stacks affected by each change type
+type Summary struct {
+	Added        int `json:"added"`
+	Removed      int `json:"removed"`
+	Updated      int `json:"updated"`
+	TagsChanged  int `json:"tagsChanged"`
+	Reclassified int `json:"reclassified"`
+}
+
+// Result holds the outcome of comparing two scans
+type Result struct {
+	Summary Summary       `json:"summary"`
+	Changes []StackChange `json:"changes"`
+}
+
+// HasDifferences reports whether any stack differs between the two scans
+func (r *Result) HasDifferences() bool {
+	return len(r.Changes) > 0
+}
+
+// Compare compares two sets of stacks, matching them by stack ID
+func Compare(before, after []models.Stack) *Result {
+	beforeByID := indexByID(before)
+	afterByID := indexByID(after)
+
+	result := &Result{
+		Changes: []StackChange{},
+	}
+
+	for id, oldStack := range beforeByID {
+		newStack, exists := afterByID[id]
+		if !exists {
+			result.Changes = append(result.Changes, newStackChange(oldStack, nil, ChangeRemoved))
+			result.Summary.Removed++
+			continue
+		}
+
+		if change, changed := compareStack(oldStack, newStack); changed {
+			result.Changes = append(result.Changes, change)
+			result.Summary.add(change)
+		}
+	}
+
+	for id, newStack := range afterByID {
+		if _, exists := beforeByID[id]; !exists {
+			result.Changes = append(result.Changes, newStackChange(nil, newStack, ChangeAdded))
+			result.Summary.Added++
+		}
+	}
+
+	// Sort for stable output regardless of map iteration order
+	sort.Slice(result.Changes, func(i, j int) bool {
+		a, b := result.Changes[i], result.Changes[j]
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.StackName != b.StackName {
+			return a.StackName < b.StackName
+		}
+		return a.StackID < b.StackID
+	})
+
+	return result
+}
+
+// add counts a modified stack in every category it belongs to
+func (s *Summary) add(change StackChange) {
+	for _, changeType := range change.Changes {
+		switch changeType {
+		case ChangeUpdated:
+			s.Updated++
+		case ChangeTagsChanged:
+			s.TagsChanged++
+		case ChangeReclassified:
+			s.Reclassified++
+		}
+	}
+}
+
+// indexByID maps stacks by stack ID
+func indexByID(stacks []models.Stack) map[string]*models.Stack {
+	index := make(map[string]*models.Stack, len(stacks))
+	for i := range stacks {
+		index[stacks[i].StackID] = &stacks[i]
+	}
+	return index
+}
+
+// newStackChange creates a change entry from the stack on either side
+func newStackChange(before, after *models.Stack, changes ...ChangeType) StackChange {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	return StackChange{
+		StackID:   current.StackID,
+		StackName: current.StackName,
+		Region:    current.Region,
+		Changes:   changes,
+		Before:    before,
+		After:     after,
+	}
+}
+
+// compareStack finds the differences between two versions of the same stack
+func compareStack(before, after *models.Stack) (StackChange, bool) {
+	var changes []ChangeType
+
+	if !before.UpdatedAt.Equal(after.UpdatedAt) {
+		changes = append(changes, ChangeUpdated)
+	}
+
+	tagChanges := compareTags(before.StackTags, after.StackTags)
+	if len(tagChanges) > 0 {
+		changes = append(changes, ChangeTagsChanged)
+	}
+
+	if !sameReasons(before.Reasons, after.Reasons) {
+		changes = append(changes, ChangeReclassified)
+	}
+
+	if len(changes) == 0 {
+		return StackChange{}, false
+	}
+
+	change := newStackChange(before, after, changes...)
+	change.TagChanges = tagChanges
+	return change, true
+}
+
+// compareTags lists added, removed and modified tags sorted by key
+func compareTags(before, after map[string]string) []TagChange {
+	var changes []TagChange
+
+	for key, oldValue := range before {
+		newValue, exists := after[key]
+		if !exists || newValue != oldValue {
+			changes = append(changes, TagChange{Key: key, Before: oldValue, After: newValue})
+		}
+	}
+
+	for key, newValue := range after {
+		if _, exists := before[key]; !exists {
+			changes = append(changes, TagChange{Key: key, After: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// sameReasons compares detection reasons ignoring order
+func sameReasons(before, after []string) bool {
+	if len(before) != len(after) {
+		return false
+	}
+
+	a := append([]string(nil), before...)
+	b := append([]string(nil), after...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package diff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverlessReason = "Contains resource with logical ID 'ServerlessDeploymentBucket'"
+
+func testStack(name string, updatedAt time.Time, tags map[string]string) models.Stack {
+	return models.Stack{
+		StackName: name,
+		StackID:   "arn:aws:cloudformation:us-east-1:123456789012:stack/" + name + "/abc123",
+		Region:    "us-east-1",
+		CreatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: updatedAt,
+		StackTags: tags,
+		Reasons:   []string{serverlessReason},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+	t2 := time.Date(2023, 2, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		before          []models.Stack
+		after           []models.Stack
+		expectedSummary Summary
+		expectedChanges map[string][]ChangeType
+	}{
+		{
+			name:            "identical scans",
+			before:          []models.Stack{testStack("api-dev", t1, nil)},
+			after:           []models.Stack{testStack("api-dev", t1, nil)},
+			expectedSummary: Summary{},
+			expectedChanges: map[string][]ChangeType{},
+		},
+		{
+			name:            "stack added",
+			before:          []models.Stack{},
+			after:           []models.Stack{testStack("api-dev", t1, nil)},
+			expectedSummary: Summary{Added: 1},
+			expectedChanges: map[string][]ChangeType{"api-dev": {ChangeAdded}},
+		},
+		{
+			name:            "stack removed",
+			before:          []models.Stack{testStack("api-dev", t1, nil)},
+			after:           nil,
+			expectedSummary: Summary{Removed: 1},
+			expectedChanges: map[string][]ChangeType{"api-dev": {ChangeRemoved}},
+		},
+		{
+			name:            "stack updated",
+			before:          []models.Stack{testStack("api-dev", t1, nil)},
+			after:           []models.Stack{testStack("api-dev", t2, nil)},
+			expectedSummary: Summary{Updated: 1},
+			expectedChanges: map[string][]ChangeType{"api-dev": {ChangeUpdated}},
+		},
+		{
+			name:            "tags changed",
+			before:          []models.Stack{testStack("api-dev", t1, map[string]string{"Owner": "team-a"})},
+			after:           []models.Stack{testStack("api-dev", t1, map[string]string{"Owner": "team-b"})},
+			expectedSummary: Summary{TagsChanged: 1},
+			expectedChanges: map[string][]ChangeType{"api-dev": {ChangeTagsChanged}},
+		},
+		{
+			name:   "updated and tags changed",
+			before: []models.Stack{testStack("api-dev", t1, nil)},
+			after: []models.Stack{
+				testStack("api-dev", t2, map[string]string{"Owner": "team-a"}),
+			},
+			expectedSummary: Summary{Updated: 1, TagsChanged: 1},
+			expectedChanges: map[string][]ChangeType{"api-dev": {ChangeUpdated, ChangeTagsChanged}},
+		},
+		{
+			name:            "nil and empty tags are equivalent",
+			before:          []models.Stack{testStack("api-dev", t1, nil)},
+			after:           []models.Stack{testStack("api-dev", t1, map[string]string{})},
+			expectedSummary: Summary{},
+			expectedChanges: map[string][]ChangeType{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Compare(tt.before, tt.after)
+
+			assert.Equal(t, tt.expectedSummary, result.Summary)
+			assert.Equal(t, len(tt.expectedChanges) > 0, result.HasDifferences())
+			require.Len(t, result.Changes, len(tt.expectedChanges))
+
+			for _, change := range result.Changes {
+				assert.Equal(t, tt.expectedChanges[change.StackName], change.Changes)
+			}
+		})
+	}
+}
+
+func TestCompare_Reclassified(t *testing.T) {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	before := testStack("api-dev", t1, nil)
+	after := testStack("api-dev", t1, nil)
+	after.Reasons = []string{serverlessReason, "Template description mentions Serverless Framework"}
+
+	result := Compare([]models.Stack{before}, []models.Stack{after})
+
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, []ChangeType{ChangeReclassified}, result.Changes[0].Changes)
+	assert.Equal(t, 1, result.Summary.Reclassified)
+
+	// Reordered reasons are not a reclassification
+	reordered := after
+	reordered.Reasons = []string{after.Reasons[1], after.Reasons[0]}
+	result = Compare([]models.Stack{after}, []models.Stack{reordered})
+	assert.False(t, result.HasDifferences())
+}
+
+func TestCompare_TagChanges(t *testing.T) {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	before := testStack("api-dev", t1, map[string]string{"Owner": "team-a", "Stage": "dev"})
+	after := testStack("api-dev", t1, map[string]string{"Owner": "team-b", "CostCenter": "1234"})
+
+	result := Compare([]models.Stack{before}, []models.Stack{after})
+
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, []TagChange{
+		{Key: "CostCenter", After: "1234"},
+		{Key: "Owner", Before: "team-a", After: "team-b"},
+		{Key: "Stage", Before: "dev"},
+	}, result.Changes[0].TagChanges)
+}
+
+func TestCompare_KeyedByStackID(t *testing.T) {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	// A stack recreated under the same name gets a new stack ID
+	before := testStack("api-dev", t1, nil)
+	after := testStack("api-dev", t1, nil)
+	after.StackID = "arn:aws:cloudformation:us-east-1:123456789012:stack/api-dev/def456"
+
+	result := Compare([]models.Stack{before}, []models.Stack{after})
+
+	assert.Equal(t, Summary{Added: 1, Removed: 1}, result.Summary)
+	require.Len(t, result.Changes, 2)
+}
+
+func TestCompare_SortedOutput(t *testing.T) {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	west := testStack("b-stack", t1, nil)
+	west.Region = "us-west-2"
+	west.StackID = "west-id"
+
+	after := []models.Stack{west, testStack("z-stack", t1, nil), testStack("a-stack", t1, nil)}
+	result := Compare(nil, after)
+
+	require.Len(t, result.Changes, 3)
+	assert.Equal(t, "a-stack", result.Changes[0].StackName)
+	assert.Equal(t, "z-stack", result.Changes[1].StackName)
+	assert.Equal(t, "b-stack", result.Changes[2].StackName)
+}
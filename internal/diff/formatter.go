@@ -0,0 +1,143 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats diffs
+type Formatter = output.ReportFormatter[*Result]
+
+// TextFormatter formats a diff as human-readable text
+type TextFormatter struct{}
+
+// Format implements the Formatter interface for text output
+func (f *TextFormatter) Format(result *Result) (string, error) {
+	if !result.HasDifferences() {
+		return "No differences found.", nil
+	}
+
+	var out strings.Builder
+	for _, change := range result.Changes {
+		out.WriteString(fmt.Sprintf("%s %s (%s) %s\n", changeSymbol(change), change.StackName, change.Region, joinChanges(change.Changes)))
+		for _, detail := range changeDetails(change) {
+			out.WriteString("    " + detail + "\n")
+		}
+	}
+	out.WriteString("\n")
+	out.WriteString(summaryLine(result.Summary))
+
+	return out.String(), nil
+}
+
+// MarkdownFormatter formats a diff as a GitHub-flavoured Markdown report
+type MarkdownFormatter struct{}
+
+// Format implements the Formatter interface for Markdown output
+func (f *MarkdownFormatter) Format(result *Result) (string, error) {
+	var out strings.Builder
+
+	out.WriteString("## Serverless stack changes\n\n")
+	if !result.HasDifferences() {
+		out.WriteString("No differences found.")
+		return out.String(), nil
+	}
+
+	out.WriteString(summaryLine(result.Summary) + "\n\n")
+	out.WriteString("| Change | Stack | Region | Details |\n")
+	out.WriteString("|--------|-------|--------|---------|\n")
+	for _, change := range result.Changes {
+		out.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			escapeMarkdown(joinChanges(change.Changes)),
+			escapeMarkdown(change.StackName),
+			escapeMarkdown(change.Region),
+			escapeMarkdown(strings.Join(changeDetails(change), "<br>")),
+		))
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// formats lists the diff formats in display order
+var formats = output.ReportFormats[*Result]{
+	{Name: "text", New: func() Formatter { return &TextFormatter{} }},
+	{Name: "json", New: func() Formatter { return output.JSON[*Result]{} }},
+	{Name: "markdown", New: func() Formatter { return &MarkdownFormatter{} }},
+}
+
+// FormatterFactory creates a diff formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("diff", format)
+}
+
+// changeSymbol returns the diff-style marker for a stack change
+func changeSymbol(change StackChange) string {
+	switch {
+	case change.Has(ChangeAdded):
+		return "+"
+	case change.Has(ChangeRemoved):
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// joinChanges renders change types as a comma-separated list
+func joinChanges(changes []ChangeType) string {
+	names := make([]string, len(changes))
+	for i, change := range changes {
+		names[i] = string(change)
+	}
+	return strings.Join(names, ", ")
+}
+
+// changeDetails describes what changed for a modified stack
+func changeDetails(change StackChange) []string {
+	var details []string
+
+	if change.Has(ChangeUpdated) {
+		details = append(details, fmt.Sprintf("updatedAt: %s -> %s", formatTime(change.Before.UpdatedAt), formatTime(change.After.UpdatedAt)))
+	}
+
+	for _, tag := range change.TagChanges {
+		details = append(details, fmt.Sprintf("tag %s: %s -> %s", tag.Key, quoteOrNone(tag.Before), quoteOrNone(tag.After)))
+	}
+
+	if change.Has(ChangeReclassified) {
+		details = append(details, fmt.Sprintf("reasons: %s -> %s", strings.Join(change.Before.Reasons, "; "), strings.Join(change.After.Reasons, "; ")))
+	}
+
+	return details
+}
+
+// summaryLine renders the per-category counts
+func summaryLine(summary Summary) string {
+	return fmt.Sprintf("Summary: %d added, %d removed, %d updated, %d tags changed, %d reclassified",
+		summary.Added, summary.Removed, summary.Updated, summary.TagsChanged, summary.Reclassified)
+}
+
+// formatTime formats time to RFC3339 string
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "(none)"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// quoteOrNone quotes a tag value, marking absent values explicitly
+func quoteOrNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// escapeMarkdown escapes characters that would break a Markdown table cell
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\n", " ")
+	return value
+}
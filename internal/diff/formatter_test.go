@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleResult() *Result {
+	t1 := time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC)
+	t2 := time.Date(2023, 2, 15, 10, 30, 0, 0, time.UTC)
+
+	before := []models.Stack{
+		testStack("api-dev", t1, map[string]string{"Owner": "team-a"}),
+		testStack("old-stack", t1, nil),
+	}
+	after := []models.Stack{
+		testStack("api-dev", t2, map[string]string{"Owner": "team|b"}),
+		testStack("new-stack", t2, nil),
+	}
+
+	return Compare(before, after)
+}
+
+func TestTextFormatter_Format(t *testing.T) {
+	formatter := &TextFormatter{}
+
+	output, err := formatter.Format(sampleResult())
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "~ api-dev (us-east-1) updated, tagsChanged")
+	assert.Contains(t, output, "updatedAt: 2023-01-15T10:30:00Z -> 2023-02-15T10:30:00Z")
+	assert.Contains(t, output, `tag Owner: "team-a" -> "team|b"`)
+	assert.Contains(t, output, "+ new-stack (us-east-1) added")
+	assert.Contains(t, output, "- old-stack (us-east-1) removed")
+	assert.Contains(t, output, "Summary: 1 added, 1 removed, 1 updated, 1 tags changed, 0 reclassified")
+
+	output, err = formatter.Format(Compare(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "No differences found.", output)
+}
+
+func TestJSONFormatter_Format(t *testing.T) {
+	formatter := output.JSON[*Result]{}
+
+	output, err := formatter.Format(sampleResult())
+	require.NoError(t, err)
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, Summary{Added: 1, Removed: 1, Updated: 1, TagsChanged: 1}, decoded.Summary)
+	assert.Len(t, decoded.Changes, 3)
+
+	output, err = formatter.Format(Compare(nil, nil))
+	require.NoError(t, err)
+	assert.Contains(t, output, `"changes":[]`)
+}
+
+func TestMarkdownFormatter_Format(t *testing.T) {
+	formatter := &MarkdownFormatter{}
+
+	output, err := formatter.Format(sampleResult())
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	assert.Equal(t, "## Serverless stack changes", lines[0])
+	assert.Contains(t, output, "| Change | Stack | Region | Details |")
+	assert.Contains(t, output, "| added | new-stack | us-east-1 |  |")
+
+	// Pipes inside values must not break the table
+	assert.Contains(t, output, `"team\|b"`)
+
+	output, err = formatter.Format(Compare(nil, nil))
+	require.NoError(t, err)
+	assert.Contains(t, output, "No differences found.")
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"text", "json", "markdown"}, formats.Names())
+}
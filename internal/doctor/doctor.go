@@ -0,0 +1,409 @@
+// Package doctor diagnoses the credential, region and permission problems
+// that keep the tool from scanning an account.
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+)
+
+// Statuses of a check
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+	// StatusSkip means the check was not run because an earlier check failed
+	StatusSkip = "skip"
+)
+
+// probeStackName is a stack name that does not exist, used to test the
+// permissions of stack-level actions without reading a real stack
+const probeStackName = "find-serverless-stacks-doctor-probe"
+
+// expiryWarning is how close to expiry credentials are reported as a warning
+const expiryWarning = 15 * time.Minute
+
+// Profile describes how a profile is configured in the shared config files
+type Profile struct {
+	Name string
+	// Kind describes where the credentials of the profile come from, such as "SSO"
+	Kind string
+}
+
+// Env gives the checks access to the configuration and to AWS
+type Env interface {
+	// LoadProfile reads the profile from the shared config files
+	LoadProfile(ctx context.Context) (*Profile, error)
+	// RetrieveCredentials retrieves the credentials of the profile, before any AssumeRole
+	RetrieveCredentials(ctx context.Context) (sdkaws.Credentials, error)
+	// CallerIdentity calls sts:GetCallerIdentity with the profile credentials
+	CallerIdentity(ctx context.Context) (*aws.CallerIdentity, error)
+	// RegionOptStatus returns the opt-in status of the region for the account
+	RegionOptStatus(ctx context.Context) (string, error)
+	// AssumedIdentity assumes the role and calls sts:GetCallerIdentity as the role
+	AssumedIdentity(ctx context.Context) (*aws.CallerIdentity, error)
+	// CloudFormation returns the client the scan would use
+	CloudFormation(ctx context.Context) (aws.CloudFormationAPI, error)
+}
+
+// Options describes the configuration being diagnosed
+type Options struct {
+	Profile    string
+	Region     string
+	RoleARN    string
+	ExternalID string
+	// Now is used to report how long temporary credentials remain valid
+	Now time.Time
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	// Remediation suggests how to fix a failure or warning
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report lists the results of the checks in the order they ran
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Failed counts the failed checks
+func (r *Report) Failed() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			count++
+		}
+	}
+	return count
+}
+
+// requiredActions lists the CloudFormation actions a scan needs
+var requiredActions = scanActions()
+
+// scanActions returns the CloudFormation actions registered for scanning
+func scanActions() []string {
+	var actions []string
+	for _, call := range aws.CallsOf(aws.FeatureScan) {
+		if call.Service == "cloudformation" {
+			actions = append(actions, call.Actions...)
+		}
+	}
+	return actions
+}
+
+// Run runs every check, skipping the checks whose prerequisites failed
+func Run(ctx context.Context, env Env, opts Options) *Report {
+	report := &Report{}
+	add := func(result Result) bool {
+		report.Results = append(report.Results, result)
+		return result.Status != StatusFail
+	}
+	skip := func(name, reason string) {
+		report.Results = append(report.Results, Result{Name: name, Status: StatusSkip, Detail: reason})
+	}
+
+	credentialsOK := false
+	if add(checkProfile(ctx, env, opts)) {
+		credentialsOK = add(checkCredentials(ctx, env, opts))
+	} else {
+		skip("credentials", "requires the profile")
+	}
+
+	identityOK := false
+	if credentialsOK {
+		identityOK = add(checkCallerIdentity(ctx, env, opts))
+	} else {
+		skip("caller identity", "requires credentials")
+	}
+
+	regionOK := false
+	if identityOK {
+		regionOK = add(checkRegion(ctx, env, opts))
+	} else {
+		skip("region", "requires a working caller identity")
+	}
+
+	roleOK := identityOK
+	if opts.RoleARN == "" {
+		skip("assume role", "no --assume-role given")
+	} else if identityOK {
+		roleOK = add(checkAssumeRole(ctx, env, opts))
+	} else {
+		skip("assume role", "requires a working caller identity")
+	}
+
+	if !regionOK || !roleOK {
+		for _, action := range requiredActions {
+			skip(action, "requires a working identity and region")
+		}
+		return report
+	}
+
+	client, err := env.CloudFormation(ctx)
+	if err != nil {
+		for _, action := range requiredActions {
+			add(Result{Name: action, Status: StatusFail, Detail: err.Error()})
+		}
+		return report
+	}
+	for _, action := range requiredActions {
+		add(checkAction(ctx, client, action, opts))
+	}
+
+	return report
+}
+
+// checkProfile checks that the profile exists in the shared config files
+func checkProfile(ctx context.Context, env Env, opts Options) Result {
+	result := Result{Name: "profile"}
+
+	profile, err := env.LoadProfile(ctx)
+	if err != nil {
+		var notExist config.SharedConfigProfileNotExistError
+		switch {
+		case errors.As(err, &notExist):
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("profile %q does not exist in the shared config files", profileName(opts))
+			result.Remediation = fmt.Sprintf("Check the spelling, or create it with 'aws configure --profile %s' or 'aws configure sso --profile %s'; AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE change where the files are read from", profileName(opts), profileName(opts))
+		default:
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("failed to read the shared config files: %v", err)
+			result.Remediation = "Fix the syntax of ~/.aws/config and ~/.aws/credentials"
+		}
+		return result
+	}
+
+	result.Status = StatusOK
+	result.Detail = fmt.Sprintf("profile %q uses %s", profile.Name, profile.Kind)
+	return result
+}
+
+// checkCredentials checks that the credentials of the profile can be retrieved
+func checkCredentials(ctx context.Context, env Env, opts Options) Result {
+	result := Result{Name: "credentials"}
+
+	creds, err := env.RetrieveCredentials(ctx)
+	if err != nil {
+		result.Status = StatusFail
+		var invalidToken *ssocreds.InvalidTokenError
+		switch {
+		case errors.As(err, &invalidToken):
+			result.Detail = "the SSO session has expired or was never started"
+			result.Remediation = fmt.Sprintf("Run 'aws sso login --profile %s'", profileName(opts))
+		case strings.Contains(err.Error(), "no EC2 IMDS role found") || strings.Contains(err.Error(), "failed to refresh cached credentials"):
+			result.Detail = fmt.Sprintf("no credentials found: %v", err)
+			result.Remediation = fmt.Sprintf("Configure credentials with 'aws configure --profile %s' or 'aws configure sso', or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", profileName(opts))
+		default:
+			result.Detail = err.Error()
+			result.Remediation = remediation(err, opts)
+		}
+		return result
+	}
+
+	result.Status = StatusOK
+	source := creds.Source
+	if source == "" {
+		source = "an unnamed provider"
+	}
+	if !creds.CanExpire {
+		result.Detail = fmt.Sprintf("static credentials from %s", source)
+		return result
+	}
+
+	remaining := creds.Expires.Sub(opts.Now).Round(time.Minute)
+	result.Detail = fmt.Sprintf("temporary credentials from %s, valid for %s", source, remaining)
+	if remaining < expiryWarning {
+		result.Status = StatusWarn
+		result.Remediation = "The credentials expire soon; refresh them before a long scan"
+	}
+	return result
+}
+
+// checkCallerIdentity checks that sts:GetCallerIdentity accepts the credentials
+func checkCallerIdentity(ctx context.Context, env Env, opts Options) Result {
+	result := Result{Name: "caller identity"}
+
+	identity, err := env.CallerIdentity(ctx)
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+		result.Remediation = remediation(err, opts)
+		return result
+	}
+
+	result.Status = StatusOK
+	result.Detail = fmt.Sprintf("%s in account %s", identity.ARN, identity.Account)
+	return result
+}
+
+// checkRegion checks the region name and whether the region is enabled for the account
+func checkRegion(ctx context.Context, env Env, opts Options) Result {
+	result := Result{Name: "region"}
+
+	if opts.Region == "" {
+		result.Status = StatusFail
+		result.Detail = "no region given"
+		result.Remediation = "Pass --region, for example --region us-east-1"
+		return result
+	}
+	partition, ok := aws.PartitionOfRegion(opts.Region)
+	if !ok {
+		result.Status = StatusFail
+		result.Detail = fmt.Sprintf("%q is not a region name", opts.Region)
+		result.Remediation = "Pass a region name such as us-east-1, cn-north-1 or us-gov-west-1"
+		return result
+	}
+
+	// Regions are opted in only in the standard partition, where the Account API is available
+	if partition.ID != "aws" {
+		result.Status = StatusOK
+		result.Detail = fmt.Sprintf("%s is in partition %s (%s), which has no opt-in regions", opts.Region, partition.ID, partition.Name)
+		return result
+	}
+
+	status, err := env.RegionOptStatus(ctx)
+	if err != nil {
+		classified := aws.ClassifyError(err, opts.Region)
+		var awsErr *aws.Error
+		if errors.As(classified, &awsErr) && awsErr.Type == aws.ErrorTypePermission {
+			result.Status = StatusWarn
+			result.Detail = fmt.Sprintf("%s: could not check whether the region is enabled", opts.Region)
+			result.Remediation = "Allow account:GetRegionOptStatus to check opt-in regions"
+			return result
+		}
+
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationException" {
+			result.Status = StatusFail
+			result.Detail = fmt.Sprintf("%s is not a known region", opts.Region)
+			result.Remediation = "Pass a region name such as us-east-1 or eu-west-1"
+			return result
+		}
+
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("%s: could not check whether the region is enabled: %v", opts.Region, err)
+		return result
+	}
+
+	switch status {
+	case "ENABLED_BY_DEFAULT":
+		result.Status = StatusOK
+		result.Detail = fmt.Sprintf("%s is enabled by default", opts.Region)
+	case "ENABLED":
+		result.Status = StatusOK
+		result.Detail = fmt.Sprintf("%s is an opt-in region and is enabled", opts.Region)
+	case "ENABLING":
+		result.Status = StatusWarn
+		result.Detail = fmt.Sprintf("%s is an opt-in region that is still being enabled", opts.Region)
+		result.Remediation = "Wait until the region is enabled; this can take several minutes"
+	default:
+		result.Status = StatusFail
+		result.Detail = fmt.Sprintf("%s is an opt-in region that is not enabled (%s)", opts.Region, status)
+		result.Remediation = "Enable the region in the AWS console under Account > AWS Regions, or scan another region"
+	}
+	return result
+}
+
+// checkAssumeRole checks that the role can be assumed
+func checkAssumeRole(ctx context.Context, env Env, opts Options) Result {
+	result := Result{Name: "assume role"}
+
+	identity, err := env.AssumedIdentity(ctx)
+	if err != nil {
+		result.Status = StatusFail
+		result.Detail = err.Error()
+
+		classified := aws.ClassifyError(err, opts.Region)
+		var awsErr *aws.Error
+		if errors.As(classified, &awsErr) && awsErr.Type == aws.ErrorTypePermission {
+			result.Remediation = fmt.Sprintf("The trust policy of %s must allow your identity to assume it", opts.RoleARN)
+			if opts.ExternalID == "" {
+				result.Remediation += "; if it requires an external ID, pass --external-id"
+			} else {
+				result.Remediation += " with this --external-id"
+			}
+			result.Remediation += ", and your identity needs sts:AssumeRole on the role"
+			return result
+		}
+		result.Remediation = remediation(err, opts)
+		return result
+	}
+
+	result.Status = StatusOK
+	result.Detail = fmt.Sprintf("assumed %s in account %s", identity.ARN, identity.Account)
+	return result
+}
+
+// checkAction checks that a CloudFormation action is allowed by calling it
+func checkAction(ctx context.Context, client aws.CloudFormationAPI, action string, opts Options) Result {
+	result := Result{Name: action}
+
+	var err error
+	switch action {
+	case "cloudformation:ListStacks":
+		_, err = client.ListStacks(ctx, &cloudformation.ListStacksInput{})
+	case "cloudformation:DescribeStacks":
+		_, err = client.DescribeStacks(ctx, &cloudformation.DescribeStacksInput{StackName: sdkaws.String(probeStackName)})
+	case "cloudformation:DescribeStackResources":
+		_, err = client.DescribeStackResources(ctx, &cloudformation.DescribeStackResourcesInput{StackName: sdkaws.String(probeStackName)})
+	}
+
+	// The probe stack does not exist, so a validation error means the call was allowed
+	var apiErr smithy.APIError
+	if err == nil || (errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationError") {
+		result.Status = StatusOK
+		result.Detail = "allowed"
+		return result
+	}
+
+	result.Status = StatusFail
+	result.Detail = err.Error()
+	result.Remediation = remediation(err, opts)
+
+	classified := aws.ClassifyError(err, opts.Region)
+	var awsErr *aws.Error
+	if errors.As(classified, &awsErr) && awsErr.Type == aws.ErrorTypePermission {
+		result.Detail = "denied"
+		result.Remediation = fmt.Sprintf("Allow %s on all resources (\"*\") in the policy of the identity the scan runs as", action)
+	}
+	return result
+}
+
+// remediation suggests a fix for an AWS error based on its classification
+func remediation(err error, opts Options) string {
+	var awsErr *aws.Error
+	if !errors.As(aws.ClassifyError(err, opts.Region), &awsErr) {
+		return ""
+	}
+
+	switch awsErr.Type {
+	case aws.ErrorTypeCredentials:
+		return fmt.Sprintf("The credentials were rejected; refresh them with 'aws sso login --profile %s', or rotate the access keys", profileName(opts))
+	case aws.ErrorTypeSSOExpired:
+		return fmt.Sprintf("Run 'aws sso login --profile %s'", profileName(opts))
+	default:
+		return awsErr.Hint()
+	}
+}
+
+// profileName returns the profile name to show in commands
+func profileName(opts Options) string {
+	if opts.Profile == "" {
+		return "default"
+	}
+	return opts.Profile
+}
@@ -0,0 +1,354 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ssocreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go"
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeEnv returns canned answers; every step succeeds unless its error is set
+type fakeEnv struct {
+	profileErr     error
+	credentials    sdkaws.Credentials
+	credentialsErr error
+	identityErr    error
+	optStatus      string
+	optStatusErr   error
+	assumeErr      error
+	cloudFormation *fakeCloudFormation
+}
+
+func newFakeEnv() *fakeEnv {
+	return &fakeEnv{
+		credentials:    sdkaws.Credentials{Source: "SSOProvider", CanExpire: true, Expires: testNow.Add(time.Hour)},
+		optStatus:      "ENABLED_BY_DEFAULT",
+		cloudFormation: &fakeCloudFormation{},
+	}
+}
+
+func (e *fakeEnv) LoadProfile(ctx context.Context) (*Profile, error) {
+	if e.profileErr != nil {
+		return nil, e.profileErr
+	}
+	return &Profile{Name: "dev", Kind: "SSO (sso_session corp)"}, nil
+}
+
+func (e *fakeEnv) RetrieveCredentials(ctx context.Context) (sdkaws.Credentials, error) {
+	return e.credentials, e.credentialsErr
+}
+
+func (e *fakeEnv) CallerIdentity(ctx context.Context) (*aws.CallerIdentity, error) {
+	if e.identityErr != nil {
+		return nil, e.identityErr
+	}
+	return &aws.CallerIdentity{Account: "123456789012", ARN: "arn:aws:sts::123456789012:assumed-role/Dev/alice"}, nil
+}
+
+func (e *fakeEnv) RegionOptStatus(ctx context.Context) (string, error) {
+	return e.optStatus, e.optStatusErr
+}
+
+func (e *fakeEnv) AssumedIdentity(ctx context.Context) (*aws.CallerIdentity, error) {
+	if e.assumeErr != nil {
+		return nil, e.assumeErr
+	}
+	return &aws.CallerIdentity{Account: "210987654321", ARN: "arn:aws:sts::210987654321:assumed-role/Scanner/session"}, nil
+}
+
+func (e *fakeEnv) CloudFormation(ctx context.Context) (aws.CloudFormationAPI, error) {
+	return e.cloudFormation, nil
+}
+
+// fakeCloudFormation denies the actions in denied and answers the probe stack like CloudFormation
+type fakeCloudFormation struct {
+	denied map[string]bool
+}
+
+func (f *fakeCloudFormation) call(action string) error {
+	if f.denied[action] {
+		return &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized to perform: " + action}
+	}
+	return nil
+}
+
+func (f *fakeCloudFormation) ListStacks(ctx context.Context, params *cloudformation.ListStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListStacksOutput, error) {
+	if err := f.call("cloudformation:ListStacks"); err != nil {
+		return nil, err
+	}
+	return &cloudformation.ListStacksOutput{}, nil
+}
+
+func (f *fakeCloudFormation) DescribeStacks(ctx context.Context, params *cloudformation.DescribeStacksInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStacksOutput, error) {
+	if err := f.call("cloudformation:DescribeStacks"); err != nil {
+		return nil, err
+	}
+	return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack with id " + sdkaws.ToString(params.StackName) + " does not exist"}
+}
+
+func (f *fakeCloudFormation) DescribeStackResources(ctx context.Context, params *cloudformation.DescribeStackResourcesInput, optFns ...func(*cloudformation.Options)) (*cloudformation.DescribeStackResourcesOutput, error) {
+	if err := f.call("cloudformation:DescribeStackResources"); err != nil {
+		return nil, err
+	}
+	return nil, &smithy.GenericAPIError{Code: "ValidationError", Message: "Stack with id " + sdkaws.ToString(params.StackName) + " does not exist"}
+}
+
+func testOptions() Options {
+	return Options{Profile: "dev", Region: "us-east-1", Now: testNow}
+}
+
+// statuses returns the status of every check by name
+func statuses(report *Report) map[string]string {
+	result := make(map[string]string)
+	for _, check := range report.Results {
+		result[check.Name] = check.Status
+	}
+	return result
+}
+
+// find returns the result of a check
+func find(t *testing.T, report *Report, name string) Result {
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	require.Failf(t, "check not found", "no result for %s", name)
+	return Result{}
+}
+
+func TestRun_AllPass(t *testing.T) {
+	report := Run(context.Background(), newFakeEnv(), testOptions())
+
+	assert.Equal(t, map[string]string{
+		"profile":                               StatusOK,
+		"credentials":                           StatusOK,
+		"caller identity":                       StatusOK,
+		"region":                                StatusOK,
+		"assume role":                           StatusSkip,
+		"cloudformation:ListStacks":             StatusOK,
+		"cloudformation:DescribeStacks":         StatusOK,
+		"cloudformation:DescribeStackResources": StatusOK,
+	}, statuses(report))
+	assert.Zero(t, report.Failed())
+
+	assert.Equal(t, `profile "dev" uses SSO (sso_session corp)`, find(t, report, "profile").Detail)
+	assert.Equal(t, "temporary credentials from SSOProvider, valid for 1h0m0s", find(t, report, "credentials").Detail)
+	assert.Equal(t, "arn:aws:sts::123456789012:assumed-role/Dev/alice in account 123456789012", find(t, report, "caller identity").Detail)
+}
+
+func TestRun_Failures(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(env *fakeEnv, opts *Options)
+		check       string
+		status      string
+		detail      string
+		remediation string
+		skipped     []string
+	}{
+		{
+			name: "missing profile",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.profileErr = config.SharedConfigProfileNotExistError{Profile: "dev"}
+			},
+			check:       "profile",
+			status:      StatusFail,
+			detail:      `profile "dev" does not exist`,
+			remediation: "aws configure --profile dev",
+			skipped:     []string{"credentials", "caller identity", "region", "cloudformation:ListStacks"},
+		},
+		{
+			name: "expired SSO session",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.credentialsErr = &ssocreds.InvalidTokenError{}
+			},
+			check:       "credentials",
+			status:      StatusFail,
+			detail:      "SSO session has expired",
+			remediation: "aws sso login --profile dev",
+			skipped:     []string{"caller identity", "region", "cloudformation:ListStacks"},
+		},
+		{
+			name: "credentials about to expire",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.credentials.Expires = testNow.Add(5 * time.Minute)
+			},
+			check:       "credentials",
+			status:      StatusWarn,
+			detail:      "valid for 5m0s",
+			remediation: "expire soon",
+		},
+		{
+			name: "static credentials",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.credentials = sdkaws.Credentials{Source: "EnvConfigCredentials"}
+			},
+			check:  "credentials",
+			status: StatusOK,
+			detail: "static credentials from EnvConfigCredentials",
+		},
+		{
+			name: "rejected credentials",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.identityErr = &aws.Error{Type: aws.ErrorTypeCredentials, Message: "failed to get caller identity"}
+			},
+			check:       "caller identity",
+			status:      StatusFail,
+			remediation: "aws sso login --profile dev",
+			skipped:     []string{"region", "cloudformation:ListStacks"},
+		},
+		{
+			name: "missing region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = ""
+			},
+			check:       "region",
+			status:      StatusFail,
+			remediation: "Pass --region",
+			skipped:     []string{"cloudformation:ListStacks"},
+		},
+		{
+			name: "malformed region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = "us-east"
+			},
+			check:   "region",
+			status:  StatusFail,
+			detail:  `"us-east" is not a region name`,
+			skipped: []string{"cloudformation:ListStacks"},
+		},
+		{
+			name: "china region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = "cn-north-1"
+				env.optStatusErr = errors.New("the Account API is not called outside the aws partition")
+			},
+			check:  "region",
+			status: StatusOK,
+			detail: "cn-north-1 is in partition aws-cn (AWS China), which has no opt-in regions",
+		},
+		{
+			name: "govcloud region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = "us-gov-west-1"
+				env.optStatusErr = errors.New("the Account API is not called outside the aws partition")
+			},
+			check:  "region",
+			status: StatusOK,
+			detail: "us-gov-west-1 is in partition aws-us-gov",
+		},
+		{
+			name: "disabled opt-in region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = "ap-east-1"
+				env.optStatus = "DISABLED"
+			},
+			check:       "region",
+			status:      StatusFail,
+			detail:      "ap-east-1 is an opt-in region that is not enabled",
+			remediation: "Account > AWS Regions",
+			skipped:     []string{"cloudformation:ListStacks"},
+		},
+		{
+			name: "enabled opt-in region",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.Region = "ap-east-1"
+				env.optStatus = "ENABLED"
+			},
+			check:  "region",
+			status: StatusOK,
+			detail: "ap-east-1 is an opt-in region and is enabled",
+		},
+		{
+			name: "opt-in status denied",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.optStatusErr = &smithy.GenericAPIError{Code: "AccessDeniedException"}
+			},
+			check:       "region",
+			status:      StatusWarn,
+			remediation: "account:GetRegionOptStatus",
+		},
+		{
+			name: "assume role denied",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.RoleARN = "arn:aws:iam::210987654321:role/Scanner"
+				env.assumeErr = &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized to perform: sts:AssumeRole"}
+			},
+			check:       "assume role",
+			status:      StatusFail,
+			remediation: "pass --external-id",
+			skipped:     []string{"cloudformation:ListStacks", "cloudformation:DescribeStacks"},
+		},
+		{
+			name: "assume role succeeds",
+			setup: func(env *fakeEnv, opts *Options) {
+				opts.RoleARN = "arn:aws:iam::210987654321:role/Scanner"
+			},
+			check:  "assume role",
+			status: StatusOK,
+			detail: "assumed arn:aws:sts::210987654321:assumed-role/Scanner/session in account 210987654321",
+		},
+		{
+			name: "action denied",
+			setup: func(env *fakeEnv, opts *Options) {
+				env.cloudFormation.denied = map[string]bool{"cloudformation:DescribeStackResources": true}
+			},
+			check:       "cloudformation:DescribeStackResources",
+			status:      StatusFail,
+			detail:      "denied",
+			remediation: "Allow cloudformation:DescribeStackResources",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newFakeEnv()
+			opts := testOptions()
+			tt.setup(env, &opts)
+
+			report := Run(context.Background(), env, opts)
+			result := find(t, report, tt.check)
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Contains(t, result.Detail, tt.detail)
+			assert.Contains(t, result.Remediation, tt.remediation)
+			for _, name := range tt.skipped {
+				assert.Equal(t, StatusSkip, find(t, report, name).Status, name)
+			}
+			if tt.status == StatusFail {
+				assert.NotZero(t, report.Failed())
+			}
+		})
+	}
+}
+
+func TestRemediation(t *testing.T) {
+	opts := testOptions()
+	assert.Contains(t, remediation(&smithy.GenericAPIError{Code: "ExpiredToken"}, opts), "aws sso login --profile dev")
+	assert.Contains(t, remediation(&smithy.GenericAPIError{Code: "Throttling"}, opts), "throttling")
+	assert.Contains(t, remediation(errors.New("dial tcp: no such host"), opts), "HTTPS_PROXY")
+	assert.Empty(t, remediation(errors.New("boom"), opts))
+}
+
+func TestRequiredActions(t *testing.T) {
+	assert.Equal(t, []string{
+		"cloudformation:ListStacks",
+		"cloudformation:DescribeStacks",
+		"cloudformation:DescribeStackResources",
+	}, requiredActions)
+
+	_, ok := aws.LookupAPICall("account", "GetRegionOptStatus")
+	assert.True(t, ok, "the region opt-in check must be in the API call registry")
+}
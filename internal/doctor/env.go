@@ -0,0 +1,145 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/account"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+)
+
+// awsEnv is the Env backed by the shared config files and the AWS APIs
+type awsEnv struct {
+	auth aws.AuthConfig
+}
+
+// NewAWSEnv returns an Env that loads the configuration the tool would use for auth
+func NewAWSEnv(auth aws.AuthConfig) Env {
+	return &awsEnv{auth: auth}
+}
+
+// LoadProfile implements Env
+func (e *awsEnv) LoadProfile(ctx context.Context) (*Profile, error) {
+	// Without a named profile the SDK default chain is used, which honours AWS_PROFILE
+	// and falls back to environment or instance role credentials
+	name := e.auth.Profile
+	implicit := name == "" || name == "default"
+	if implicit {
+		name = "default"
+		if env := os.Getenv("AWS_PROFILE"); env != "" {
+			name = env
+		}
+	}
+
+	shared, err := config.LoadSharedConfigProfile(ctx, name, sharedConfigFiles)
+	if err != nil {
+		var notExist config.SharedConfigProfileNotExistError
+		if implicit && name == "default" && errors.As(err, &notExist) {
+			return &Profile{Name: name, Kind: "no shared config entry; credentials come from the environment or the instance role"}, nil
+		}
+		return nil, err
+	}
+
+	return &Profile{Name: name, Kind: profileKind(shared)}, nil
+}
+
+// sharedConfigFiles reads the files the SDK default chain reads, which
+// LoadSharedConfigProfile does not take from the environment by itself
+func sharedConfigFiles(o *config.LoadSharedConfigOptions) {
+	o.ConfigFiles, o.CredentialsFiles = aws.SharedConfigFiles()
+}
+
+// profileKind describes where the credentials of a shared config profile come from
+func profileKind(shared config.SharedConfig) string {
+	switch {
+	case shared.SSOSessionName != "":
+		return fmt.Sprintf("SSO (sso_session %s)", shared.SSOSessionName)
+	case shared.SSOStartURL != "":
+		return fmt.Sprintf("SSO (%s)", shared.SSOStartURL)
+	case shared.RoleARN != "" && shared.SourceProfileName != "":
+		return fmt.Sprintf("role %s from profile %s", shared.RoleARN, shared.SourceProfileName)
+	case shared.RoleARN != "":
+		return fmt.Sprintf("role %s", shared.RoleARN)
+	case shared.CredentialProcess != "":
+		return "a credential process"
+	case shared.WebIdentityTokenFile != "":
+		return "a web identity token"
+	case shared.Credentials.HasKeys():
+		return "static access keys"
+	default:
+		return "no credentials of its own"
+	}
+}
+
+// baseAuth returns the authentication configuration without AssumeRole
+func (e *awsEnv) baseAuth() aws.AuthConfig {
+	auth := e.auth
+	auth.AssumeRole = nil
+	return auth
+}
+
+// RetrieveCredentials implements Env
+func (e *awsEnv) RetrieveCredentials(ctx context.Context) (sdkaws.Credentials, error) {
+	cfg, err := aws.LoadConfig(ctx, e.baseAuth())
+	if err != nil {
+		return sdkaws.Credentials{}, err
+	}
+	if cfg.Credentials == nil {
+		return sdkaws.Credentials{}, fmt.Errorf("no credential providers are configured")
+	}
+
+	return cfg.Credentials.Retrieve(ctx)
+}
+
+// CallerIdentity implements Env
+func (e *awsEnv) CallerIdentity(ctx context.Context) (*aws.CallerIdentity, error) {
+	client, err := aws.CreateSTSClient(ctx, e.baseAuth())
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.GetCallerIdentity(ctx, client)
+}
+
+// RegionOptStatus implements Env
+func (e *awsEnv) RegionOptStatus(ctx context.Context) (string, error) {
+	cfg, err := aws.LoadConfig(ctx, e.baseAuth())
+	if err != nil {
+		return "", err
+	}
+
+	output, err := account.NewFromConfig(cfg).GetRegionOptStatus(ctx, &account.GetRegionOptStatusInput{
+		RegionName: sdkaws.String(e.auth.Region),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(output.RegionOptStatus), nil
+}
+
+// AssumedIdentity implements Env
+func (e *awsEnv) AssumedIdentity(ctx context.Context) (*aws.CallerIdentity, error) {
+	cfg, err := aws.LoadConfig(ctx, e.auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return aws.GetCallerIdentity(ctx, sts.NewFromConfig(cfg))
+}
+
+// CloudFormation implements Env
+func (e *awsEnv) CloudFormation(ctx context.Context) (aws.CloudFormationAPI, error) {
+	cfg, err := aws.LoadConfig(ctx, e.auth)
+	if err != nil {
+		return nil, err
+	}
+
+	return cloudformation.NewFromConfig(cfg), nil
+}
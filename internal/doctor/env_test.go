@@ -0,0 +1,73 @@
+package doctor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		shared   config.SharedConfig
+		expected string
+	}{
+		{name: "sso session", shared: config.SharedConfig{SSOSessionName: "corp"}, expected: "SSO (sso_session corp)"},
+		{name: "legacy sso", shared: config.SharedConfig{SSOStartURL: "https://corp.awsapps.com/start"}, expected: "SSO (https://corp.awsapps.com/start)"},
+		{name: "role", shared: config.SharedConfig{RoleARN: "arn:aws:iam::123456789012:role/Dev", SourceProfileName: "base"}, expected: "role arn:aws:iam::123456789012:role/Dev from profile base"},
+		{name: "credential process", shared: config.SharedConfig{CredentialProcess: "vault-creds"}, expected: "a credential process"},
+		{name: "static keys", shared: config.SharedConfig{Credentials: sdkaws.Credentials{AccessKeyID: "AKIA", SecretAccessKey: "secret"}}, expected: "static access keys"},
+		{name: "nothing", shared: config.SharedConfig{Region: "us-east-1"}, expected: "no credentials of its own"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, profileKind(tt.shared))
+		})
+	}
+}
+
+func TestAWSEnv_LoadProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile dev]\nsso_session = corp\n\n[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = us-east-1\n"), 0600))
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	tests := []struct {
+		name       string
+		profile    string
+		envProfile string
+		wantName   string
+		wantKind   string
+		wantErr    bool
+	}{
+		{name: "missing default profile", profile: "default", wantName: "default", wantKind: "no shared config entry"},
+		{name: "AWS_PROFILE", profile: "default", envProfile: "dev", wantName: "dev", wantKind: "SSO"},
+		{name: "named profile", profile: "dev", wantName: "dev", wantKind: "SSO"},
+		{name: "missing named profile", profile: "prod", wantErr: true},
+		{name: "missing AWS_PROFILE", envProfile: "prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_PROFILE", tt.envProfile)
+
+			profile, err := NewAWSEnv(aws.AuthConfig{Profile: tt.profile, Region: "us-east-1"}).LoadProfile(context.Background())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, profile.Name)
+			assert.Contains(t, profile.Kind, tt.wantKind)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package doctor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats doctor reports
+type Formatter = output.ReportFormatter[*Report]
+
+// TextFormatter formats the report as one line per check, with the
+// remediation of failures and warnings on the following line
+type TextFormatter struct{}
+
+// Format implements the Formatter interface for text output
+func (f *TextFormatter) Format(report *Report) (string, error) {
+	width := 0
+	for _, result := range report.Results {
+		width = max(width, len(result.Name))
+	}
+
+	var out strings.Builder
+	for _, result := range report.Results {
+		out.WriteString(fmt.Sprintf("%-6s %-*s  %s\n", "["+result.Status+"]", width, result.Name, result.Detail))
+		if result.Remediation != "" {
+			out.WriteString(fmt.Sprintf("%-6s %-*s  fix: %s\n", "", width, "", result.Remediation))
+		}
+	}
+
+	switch failed := report.Failed(); failed {
+	case 0:
+		out.WriteString("\nAll checks passed")
+	case 1:
+		out.WriteString("\n1 check failed")
+	default:
+		out.WriteString(fmt.Sprintf("\n%d checks failed", failed))
+	}
+
+	return out.String(), nil
+}
+
+// formats lists the doctor report formats in display order
+var formats = output.ReportFormats[*Report]{
+	{Name: "text", New: func() Formatter { return &TextFormatter{} }},
+	{Name: "json", New: func() Formatter { return output.JSON[*Report]{} }},
+}
+
+// FormatterFactory creates a doctor report formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("doctor report", format)
+}
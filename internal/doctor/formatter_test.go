@@ -0,0 +1,41 @@
+package doctor
+
+import (
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextFormatter(t *testing.T) {
+	report := &Report{Results: []Result{
+		{Name: "profile", Status: StatusOK, Detail: `profile "dev" uses static access keys`},
+		{Name: "caller identity", Status: StatusFail, Detail: "credentials rejected", Remediation: "Rotate the access keys"},
+		{Name: "region", Status: StatusSkip, Detail: "requires a working caller identity"},
+	}}
+
+	result, err := (&TextFormatter{}).Format(report)
+	require.NoError(t, err)
+	expected := `[ok]   profile          profile "dev" uses static access keys
+[fail] caller identity  credentials rejected
+                        fix: Rotate the access keys
+[skip] region           requires a working caller identity
+
+1 check failed`
+	assert.Equal(t, expected, result)
+
+	result, err = (&TextFormatter{}).Format(&Report{Results: report.Results[:1]})
+	require.NoError(t, err)
+	assert.Contains(t, result, "\n\nAll checks passed")
+}
+
+func TestJSONFormatter(t *testing.T) {
+	result, err := output.JSON[*Report]{}.Format(&Report{Results: []Result{{Name: "region", Status: StatusFail, Detail: "no region given", Remediation: "Pass --region"}}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"results":[{"name":"region","status":"fail","detail":"no region given","remediation":"Pass --region"}]}`, result)
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"text", "json"}, formats.Names())
+}
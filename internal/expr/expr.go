@@ -0,0 +1,214 @@
+// Package expr compiles and evaluates CEL expressions over detected stacks.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// Variable describes a variable available to expressions
+type Variable struct {
+	Name        string
+	Type        *cel.Type
+	Description string
+}
+
+// Variables lists the variables of the stack environment, in documentation order
+var Variables = []Variable{
+	{"stackName", cel.StringType, "Stack name"},
+	{"stackId", cel.StringType, "Stack ID (ARN)"},
+	{"region", cel.StringType, "Region of the stack"},
+	{"description", cel.StringType, "Stack description"},
+	{"stage", cel.StringType, "Stage from the STAGE tag, or else the last part of the stack name"},
+	{"owner", cel.StringType, "Value of the Owner tag, or \"\""},
+	{"frameworkVersion", cel.StringType, "Serverless Framework version from the FRAMEWORK_VERSION tag, or \"\""},
+	{"frameworkMajor", cel.IntType, "Major Serverless Framework version, or 0 when unknown"},
+	{"tags", cel.MapType(cel.StringType, cel.StringType), "Stack tags"},
+	{"reasons", cel.ListType(cel.StringType), "Why the stack was detected"},
+	{"createdAt", cel.TimestampType, "Creation time; 0001-01-01T00:00:00Z when unknown"},
+	{"updatedAt", cel.TimestampType, "Last update time; 0001-01-01T00:00:00Z when unknown or never updated"},
+	{"neverUpdated", cel.BoolType, "The stack has not been updated since it was created"},
+	{"lastDeployedAt", cel.TimestampType, "Last update, or creation of never-updated stacks"},
+	{"age", cel.DurationType, "Time since the last deployment; 0s when unknown"},
+	{"now", cel.TimestampType, "Time of the scan, which ages are measured from"},
+	{"stackStatus", cel.StringType, "Stack status, such as UPDATE_COMPLETE"},
+	{"terminationProtection", cel.BoolType, "Termination protection is enabled"},
+	{"driftStatus", cel.StringType, "Drift status of the last drift detection"},
+	{"roleArn", cel.StringType, "Service role of the stack"},
+	{"parentId", cel.StringType, "Stack ID of the parent of a nested stack"},
+	{"capabilities", cel.ListType(cel.StringType), "Capabilities the stack was deployed with"},
+	{"outputs", cel.MapType(cel.StringType, cel.StringType), "Stack outputs by key"},
+	{"parameters", cel.MapType(cel.StringType, cel.StringType), "Stack parameters by key"},
+}
+
+// env is the CEL environment shared by all stack expressions
+var env = func() *cel.Env {
+	options := []cel.EnvOption{ext.Strings(), ext.Lists(), ext.Sets()}
+	for _, variable := range Variables {
+		options = append(options, cel.Variable(variable.Name, variable.Type))
+	}
+
+	e, err := cel.NewEnv(options...)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create expression environment: %v", err))
+	}
+	return e
+}()
+
+// Program is a compiled boolean expression over a stack
+type Program struct {
+	source  string
+	program cel.Program
+	// variables are the names of the variables the expression reads
+	variables map[string]bool
+}
+
+// MissingFieldsError reports that an expression depends on optional fields
+// that the stacks were loaded without, such as a report saved without --fields
+type MissingFieldsError struct {
+	Fields []string
+}
+
+func (e *MissingFieldsError) Error() string {
+	return fmt.Sprintf("field %s not in input; re-save with --fields %s", strings.Join(e.Fields, ", "), strings.Join(e.Fields, ","))
+}
+
+// Compile parses and type-checks a boolean expression
+func Compile(source string) (*Program, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid expression: %w", issues.Err())
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("expression must be a boolean, not %s", ast.OutputType())
+	}
+
+	// Partial evaluation lets missing fields be unknown rather than zero values
+	program, err := env.Program(ast, cel.EvalOptions(cel.OptPartialEval))
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+
+	variables := make(map[string]bool)
+	for _, reference := range ast.NativeRep().ReferenceMap() {
+		if reference.Name != "" {
+			variables[reference.Name] = true
+		}
+	}
+
+	return &Program{source: source, program: program, variables: variables}, nil
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
+
+// Reads returns the fields the expression reads, in the order given
+func (p *Program) Reads(fields []string) []string {
+	var read []string
+	for _, field := range fields {
+		if p.variables[field] {
+			read = append(read, field)
+		}
+	}
+	return read
+}
+
+// Eval evaluates the expression for a stack, measuring ages from now
+func (p *Program) Eval(stack models.Stack, now time.Time) (bool, error) {
+	return p.EvalWithout(stack, now, nil)
+}
+
+// EvalWithout evaluates the expression for a stack that was loaded without the
+// missing optional fields. When the result depends on one of them, the error
+// is a *MissingFieldsError; "stage != 'prod' || terminationProtection" is
+// still true for a dev stack.
+func (p *Program) EvalWithout(stack models.Stack, now time.Time, missing []string) (bool, error) {
+	var activation any = Activation(stack, now)
+	if read := p.Reads(missing); len(read) > 0 {
+		patterns := make([]*cel.AttributePatternType, len(read))
+		for i, field := range read {
+			patterns[i] = cel.AttributePattern(field)
+		}
+		partial, err := cel.PartialVars(activation, patterns...)
+		if err != nil {
+			return false, err
+		}
+		activation = partial
+	}
+
+	value, _, err := p.program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	if types.IsUnknown(value) {
+		return false, &MissingFieldsError{Fields: p.Reads(missing)}
+	}
+
+	result, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression returned %v instead of a boolean", value)
+	}
+	return result, nil
+}
+
+// Activation returns the values of the variables for a stack
+func Activation(stack models.Stack, now time.Time) map[string]any {
+	var age time.Duration
+	if deployedAt := stack.LastDeployedAt(); !deployedAt.IsZero() {
+		age = now.Sub(deployedAt)
+	}
+
+	return map[string]any{
+		"stackName":             stack.StackName,
+		"stackId":               stack.StackID,
+		"region":                stack.Region,
+		"description":           stack.Description,
+		"stage":                 stack.Stage(),
+		"owner":                 stack.Owner(),
+		"frameworkVersion":      stack.FrameworkVersion(),
+		"frameworkMajor":        majorVersion(stack.FrameworkVersion()),
+		"tags":                  stack.StackTags,
+		"reasons":               stack.Reasons,
+		"createdAt":             stack.CreatedAt,
+		"updatedAt":             stack.UpdatedAt,
+		"neverUpdated":          stack.NeverUpdated,
+		"lastDeployedAt":        stack.LastDeployedAt(),
+		"age":                   age,
+		"now":                   now,
+		"stackStatus":           stack.StackStatus,
+		"terminationProtection": stack.TerminationProtection != nil && *stack.TerminationProtection,
+		"driftStatus":           stack.DriftStatus,
+		"roleArn":               stack.RoleARN,
+		"parentId":              stack.ParentID,
+		"capabilities":          stack.Capabilities,
+		"outputs":               stack.Outputs,
+		"parameters":            stack.Parameters,
+	}
+}
+
+// majorVersion returns the major part of a version such as "v4.4.0", or 0 when it has none
+func majorVersion(version string) int64 {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexByte(version, '.'); i >= 0 {
+		version = version[:i]
+	}
+
+	major, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return major
+}
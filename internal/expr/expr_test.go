@@ -0,0 +1,152 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name           string
+		source         string
+		expectedErrMsg string
+	}{
+		{name: "valid", source: `stage in ["dev", "test"] && tags.Owner == "team-a" && age > duration("720h")`},
+		{name: "empty", source: "  ", expectedErrMsg: "expression is empty"},
+		{name: "syntax error", source: `stage ==`, expectedErrMsg: "invalid expression"},
+		{name: "unknown variable", source: `stag == "dev"`, expectedErrMsg: "undeclared reference to 'stag'"},
+		{name: "type mismatch", source: `age > 30`, expectedErrMsg: "no matching overload"},
+		{name: "not a boolean", source: `stackName`, expectedErrMsg: "expression must be a boolean, not string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.source, program.String())
+		})
+	}
+}
+
+func TestProgram_Eval(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	protected := true
+	stack := models.Stack{
+		StackName:             "orders-api-dev",
+		Region:                "us-east-1",
+		UpdatedAt:             now.AddDate(0, 0, -45),
+		StackTags:             map[string]string{"Owner": "team-a", "FRAMEWORK_VERSION": "3.38.0"},
+		Reasons:               []string{"Has ServerlessDeploymentBucket resource"},
+		TerminationProtection: &protected,
+	}
+
+	tests := []struct {
+		source   string
+		stack    models.Stack
+		expected bool
+	}{
+		{source: `stage in ["dev", "test"] && tags.Owner == "team-a" && age > duration("720h")`, stack: stack, expected: true},
+		{source: `age > duration("1440h")`, stack: stack, expected: false},
+		{source: `owner == "team-a" && frameworkMajor == 3 && frameworkVersion.startsWith("3.")`, stack: stack, expected: true},
+		{source: `terminationProtection && region.startsWith("us-")`, stack: stack, expected: true},
+		{source: `reasons.exists(r, r.contains("ServerlessDeploymentBucket"))`, stack: stack, expected: true},
+		{source: `lastDeployedAt < now - duration("24h")`, stack: stack, expected: true},
+		// Stacks without tags or timestamps
+		{source: `!has(tags.Owner) && size(tags) == 0 && age == duration("0s") && frameworkMajor == 0`, stack: models.Stack{StackName: "bare"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			require.NoError(t, err)
+
+			result, err := program.Eval(tt.stack, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestProgram_EvalError(t *testing.T) {
+	program, err := Compile(`tags.Owner == "team-a"`)
+	require.NoError(t, err)
+
+	_, err = program.Eval(models.Stack{StackName: "untagged"}, time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such key: Owner")
+}
+
+func TestProgram_EvalWithout(t *testing.T) {
+	missing := []string{"stackStatus", "terminationProtection", "outputs"}
+	prod := models.Stack{StackName: "orders-api-prod"}
+	dev := models.Stack{StackName: "orders-api-dev"}
+
+	tests := []struct {
+		source      string
+		stack       models.Stack
+		expected    bool
+		wantMissing []string
+	}{
+		{source: `terminationProtection`, stack: prod, wantMissing: []string{"terminationProtection"}},
+		{source: `!terminationProtection`, stack: prod, wantMissing: []string{"terminationProtection"}},
+		{source: `has(outputs.ServiceEndpoint)`, stack: prod, wantMissing: []string{"outputs"}},
+		{source: `stackStatus == "UPDATE_COMPLETE" && terminationProtection`, stack: prod, wantMissing: []string{"stackStatus", "terminationProtection"}},
+		// The result does not depend on the missing field
+		{source: `stage != "prod" || terminationProtection`, stack: dev, expected: true},
+		{source: `stage == "prod" && terminationProtection`, stack: dev, expected: false},
+		{source: `region == ""`, stack: prod, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source)
+			require.NoError(t, err)
+
+			result, err := program.EvalWithout(tt.stack, time.Now(), missing)
+			if tt.wantMissing == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+				return
+			}
+
+			var missingErr *MissingFieldsError
+			require.ErrorAs(t, err, &missingErr)
+			assert.Equal(t, tt.wantMissing, missingErr.Fields)
+		})
+	}
+
+	program, err := Compile(`terminationProtection`)
+	require.NoError(t, err)
+	_, err = program.EvalWithout(prod, time.Now(), missing)
+	assert.EqualError(t, err, "field terminationProtection not in input; re-save with --fields terminationProtection")
+
+	// Without missing fields, the zero value is used
+	result, err := program.EvalWithout(prod, time.Now(), nil)
+	require.NoError(t, err)
+	assert.False(t, result)
+}
+
+func TestProgram_Reads(t *testing.T) {
+	program, err := Compile(`stage == "prod" && outputs.exists(k, k == "ServiceEndpoint") && terminationProtection`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"terminationProtection", "outputs"}, program.Reads([]string{"stackStatus", "terminationProtection", "outputs"}))
+	assert.Empty(t, program.Reads([]string{"parameters"}))
+}
+
+func TestMajorVersion(t *testing.T) {
+	assert.Equal(t, int64(4), majorVersion("4.4.0"))
+	assert.Equal(t, int64(3), majorVersion("v3.38.0"))
+	assert.Equal(t, int64(2), majorVersion("2"))
+	assert.Equal(t, int64(0), majorVersion(""))
+	assert.Equal(t, int64(0), majorVersion("latest"))
+}
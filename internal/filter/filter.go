@@ -0,0 +1,277 @@
+package filter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/hassaku63/find-serverless-stacks/internal/expr"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// TagFilter matches a single stack tag
+type TagFilter struct {
+	Key string
+	// Value is compared only when HasValue is set; otherwise the tag only has to exist
+	Value    string
+	HasValue bool
+	// Negate inverts the match, e.g. "Owner!=team-a" or "!Owner"
+	Negate bool
+}
+
+// ParseTagFilter parses "key=value", "key!=value", "key" (tag present) or "!key" (tag absent)
+func ParseTagFilter(expression string) (TagFilter, error) {
+	var tag TagFilter
+
+	if key, value, found := strings.Cut(expression, "!="); found {
+		tag = TagFilter{Key: key, Value: value, HasValue: true, Negate: true}
+	} else if key, value, found := strings.Cut(expression, "="); found {
+		tag = TagFilter{Key: key, Value: value, HasValue: true}
+	} else if key, found := strings.CutPrefix(expression, "!"); found {
+		tag = TagFilter{Key: key, Negate: true}
+	} else {
+		tag = TagFilter{Key: expression}
+	}
+
+	if tag.Key == "" {
+		return TagFilter{}, fmt.Errorf("invalid tag filter '%s': expected key=value, key!=value, key or !key", expression)
+	}
+
+	return tag, nil
+}
+
+// Match reports whether the tags satisfy the filter
+func (t TagFilter) Match(tags map[string]string) bool {
+	value, exists := tags[t.Key]
+	matched := exists && (!t.HasValue || value == t.Value)
+	return matched != t.Negate
+}
+
+// Filter selects stacks by name, tags and timestamps. Bounds ending in
+// Before are exclusive and bounds ending in After are inclusive; zero
+// values disable a condition. A nil *Filter matches every stack.
+type Filter struct {
+	NameGlob  string
+	NameRegex *regexp.Regexp
+	Tags      []TagFilter
+
+	CreatedBefore time.Time
+	CreatedAfter  time.Time
+	UpdatedBefore time.Time
+	UpdatedAfter  time.Time
+
+	// Where is a CEL expression a stack must satisfy. Stacks for which it
+	// cannot be evaluated, such as when it reads a missing tag, do not match.
+	Where *expr.Program
+	// AsOf is the time ages in Where are measured from
+	AsOf time.Time
+}
+
+// Options holds the filter settings as given on the command line
+type Options struct {
+	NameGlob  string
+	NameRegex string
+	Tags      []string
+
+	CreatedBefore string
+	CreatedAfter  string
+	UpdatedBefore string
+	UpdatedAfter  string
+
+	Where string
+}
+
+// New validates the options and builds a filter. It returns nil when no filter is set.
+func New(opts Options) (*Filter, error) {
+	f := &Filter{NameGlob: opts.NameGlob, AsOf: time.Now().UTC()}
+
+	if opts.NameGlob != "" {
+		if _, err := path.Match(opts.NameGlob, ""); err != nil {
+			return nil, fmt.Errorf("invalid name glob '%s': %w", opts.NameGlob, err)
+		}
+	}
+
+	if opts.NameRegex != "" {
+		re, err := regexp.Compile(opts.NameRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name regex '%s': %w", opts.NameRegex, err)
+		}
+		f.NameRegex = re
+	}
+
+	for _, expression := range opts.Tags {
+		tag, err := ParseTagFilter(expression)
+		if err != nil {
+			return nil, err
+		}
+		f.Tags = append(f.Tags, tag)
+	}
+
+	bounds := []struct {
+		name   string
+		value  string
+		target *time.Time
+	}{
+		{"created-before", opts.CreatedBefore, &f.CreatedBefore},
+		{"created-after", opts.CreatedAfter, &f.CreatedAfter},
+		{"updated-before", opts.UpdatedBefore, &f.UpdatedBefore},
+		{"updated-after", opts.UpdatedAfter, &f.UpdatedAfter},
+	}
+	for _, bound := range bounds {
+		if bound.value == "" {
+			continue
+		}
+		t, err := ParseTime(bound.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", bound.name, err)
+		}
+		*bound.target = t
+	}
+
+	if opts.Where != "" {
+		where, err := expr.Compile(opts.Where)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --where: %w", err)
+		}
+		f.Where = where
+	}
+
+	if f.isEmpty() {
+		return nil, nil
+	}
+
+	return f, nil
+}
+
+// ParseTime parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight UTC)
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s': expected YYYY-MM-DD or RFC 3339", value)
+	}
+
+	return t, nil
+}
+
+// isEmpty reports whether no condition is set
+func (f *Filter) isEmpty() bool {
+	return f.NameGlob == "" && f.NameRegex == nil && len(f.Tags) == 0 &&
+		f.CreatedBefore.IsZero() && f.CreatedAfter.IsZero() &&
+		f.UpdatedBefore.IsZero() && f.UpdatedAfter.IsZero() && f.Where == nil
+}
+
+// MatchSummary applies the conditions that only need ListStacks data: the
+// name and the timestamps. Tag and Where conditions are left to MatchStack.
+func (f *Filter) MatchSummary(summary types.StackSummary) bool {
+	if f == nil {
+		return true
+	}
+
+	var name string
+	if summary.StackName != nil {
+		name = *summary.StackName
+	}
+
+	var createdAt, updatedAt time.Time
+	if summary.CreationTime != nil {
+		createdAt = *summary.CreationTime
+	}
+	if summary.LastUpdatedTime != nil {
+		updatedAt = *summary.LastUpdatedTime
+	}
+
+	return f.matchName(name) && f.matchTimes(createdAt, updatedAt)
+}
+
+// MatchStack applies every condition to a detected stack
+func (f *Filter) MatchStack(stack models.Stack) bool {
+	if f == nil {
+		return true
+	}
+
+	if !f.matchName(stack.StackName) || !f.matchTimes(stack.CreatedAt, stack.UpdatedAt) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !tag.Match(stack.StackTags) {
+			return false
+		}
+	}
+
+	if f.Where != nil {
+		matched, err := f.Where.Eval(stack, f.AsOf)
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply returns the stacks that match the filter
+func (f *Filter) Apply(stacks []models.Stack) []models.Stack {
+	if f == nil {
+		return stacks
+	}
+
+	matched := make([]models.Stack, 0, len(stacks))
+	for _, stack := range stacks {
+		if f.MatchStack(stack) {
+			matched = append(matched, stack)
+		}
+	}
+	return matched
+}
+
+// matchName applies the glob and regular expression conditions
+func (f *Filter) matchName(name string) bool {
+	if f.NameGlob != "" {
+		// The pattern was validated in New, so an error cannot occur here
+		if matched, _ := path.Match(f.NameGlob, name); !matched {
+			return false
+		}
+	}
+
+	if f.NameRegex != nil && !f.NameRegex.MatchString(name) {
+		return false
+	}
+
+	return true
+}
+
+// matchTimes applies the timestamp bounds. A stack that was never updated is
+// treated as last updated when it was created.
+func (f *Filter) matchTimes(createdAt, updatedAt time.Time) bool {
+	if updatedAt.IsZero() {
+		updatedAt = createdAt
+	}
+
+	return inRange(createdAt, f.CreatedAfter, f.CreatedBefore) &&
+		inRange(updatedAt, f.UpdatedAfter, f.UpdatedBefore)
+}
+
+// inRange reports whether t is within [after, before); zero bounds are open.
+// An unknown (zero) time never satisfies a bound.
+func inRange(t, after, before time.Time) bool {
+	if after.IsZero() && before.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	if !after.IsZero() && t.Before(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+	return true
+}
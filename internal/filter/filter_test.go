@@ -0,0 +1,199 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagFilter(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   TagFilter
+		hasError   bool
+	}{
+		{expression: "Owner=team-a", expected: TagFilter{Key: "Owner", Value: "team-a", HasValue: true}},
+		{expression: "Owner!=team-a", expected: TagFilter{Key: "Owner", Value: "team-a", HasValue: true, Negate: true}},
+		{expression: "Owner=", expected: TagFilter{Key: "Owner", HasValue: true}},
+		{expression: "Owner", expected: TagFilter{Key: "Owner"}},
+		{expression: "!Owner", expected: TagFilter{Key: "Owner", Negate: true}},
+		{expression: "url=https://example.com/?a=b", expected: TagFilter{Key: "url", Value: "https://example.com/?a=b", HasValue: true}},
+		{expression: "=team-a", hasError: true},
+		{expression: "!", hasError: true},
+		{expression: "", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			tag, err := ParseTagFilter(tt.expression)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tag)
+		})
+	}
+}
+
+func TestTagFilter_Match(t *testing.T) {
+	tags := map[string]string{"Owner": "team-a", "Empty": ""}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{"Owner=team-a", true},
+		{"Owner=team-b", false},
+		{"Owner!=team-a", false},
+		{"Owner!=team-b", true},
+		{"Missing!=team-a", true},
+		{"Owner", true},
+		{"Missing", false},
+		{"!Owner", false},
+		{"!Missing", true},
+		{"Empty=", true},
+		{"Missing=", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			tag, err := ParseTagFilter(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tag.Match(tags))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	f, err := New(Options{})
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	f, err = New(Options{CreatedAfter: "2024-01-01", UpdatedBefore: "2024-06-01T12:00:00+09:00"})
+	require.NoError(t, err)
+	require.NotNil(t, f)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), f.CreatedAfter)
+	assert.True(t, f.UpdatedBefore.Equal(time.Date(2024, 6, 1, 3, 0, 0, 0, time.UTC)))
+
+	tests := []struct {
+		name    string
+		opts    Options
+		message string
+	}{
+		{name: "bad glob", opts: Options{NameGlob: "orders-["}, message: "invalid name glob"},
+		{name: "bad regex", opts: Options{NameRegex: "orders-("}, message: "invalid name regex"},
+		{name: "bad tag", opts: Options{Tags: []string{"=x"}}, message: "invalid tag filter"},
+		{name: "bad date", opts: Options{CreatedBefore: "last week"}, message: "invalid --created-before"},
+		{name: "unknown where variable", opts: Options{Where: `stag == "dev"`}, message: "invalid --where: invalid expression"},
+		{name: "non-boolean where", opts: Options{Where: `stackName`}, message: "expression must be a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestFilter_MatchStack(t *testing.T) {
+	stack := models.Stack{
+		StackName: "orders-api-prod",
+		CreatedAt: time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		StackTags: map[string]string{"Environment": "prod", "Owner": "team-a"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     Options
+		expected bool
+	}{
+		{name: "glob match", opts: Options{NameGlob: "orders-*"}, expected: true},
+		{name: "glob mismatch", opts: Options{NameGlob: "billing-*"}, expected: false},
+		{name: "regex match", opts: Options{NameRegex: "-(prod|staging)$"}, expected: true},
+		{name: "regex mismatch", opts: Options{NameRegex: "-dev$"}, expected: false},
+		{name: "all tags match", opts: Options{Tags: []string{"Environment=prod", "Owner"}}, expected: true},
+		{name: "negated tag", opts: Options{Tags: []string{"Environment=prod", "Owner!=team-a"}}, expected: false},
+		{name: "created after", opts: Options{CreatedAfter: "2023-01-15"}, expected: true},
+		{name: "created before is exclusive", opts: Options{CreatedBefore: "2023-01-15"}, expected: false},
+		{name: "created range", opts: Options{CreatedAfter: "2023-01-01", CreatedBefore: "2023-02-01"}, expected: true},
+		{name: "updated after", opts: Options{UpdatedAfter: "2024-04-01"}, expected: false},
+		{name: "updated before", opts: Options{UpdatedBefore: "2024-04-01"}, expected: true},
+		{name: "combined", opts: Options{NameGlob: "orders-*", Tags: []string{"Environment=dev"}}, expected: false},
+		{name: "where match", opts: Options{Where: `stage in ["prod", "staging"] && tags.Owner == "team-a"`}, expected: true},
+		{name: "where mismatch", opts: Options{Where: `updatedAt > timestamp("2024-06-01T00:00:00Z")`}, expected: false},
+		{name: "where missing tag does not match", opts: Options{Where: `tags.CostCenter == "1234"`}, expected: false},
+		{name: "where combined with flags", opts: Options{NameGlob: "billing-*", Where: `true`}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := New(tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, f.MatchStack(stack))
+		})
+	}
+}
+
+func TestFilter_MatchSummary(t *testing.T) {
+	f, err := New(Options{NameGlob: "orders-*", UpdatedBefore: "2024-01-01", Tags: []string{"Owner=team-a"}})
+	require.NoError(t, err)
+
+	// Never-updated stacks use the creation time for updated-* bounds
+	assert.True(t, f.MatchSummary(types.StackSummary{
+		StackName:    aws.String("orders-api-dev"),
+		CreationTime: aws.Time(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+	}))
+	assert.False(t, f.MatchSummary(types.StackSummary{
+		StackName:       aws.String("orders-api-dev"),
+		CreationTime:    aws.Time(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+		LastUpdatedTime: aws.Time(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+	}))
+	assert.False(t, f.MatchSummary(types.StackSummary{
+		StackName:    aws.String("billing-dev"),
+		CreationTime: aws.Time(time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)),
+	}))
+}
+
+func TestFilter_WhereAge(t *testing.T) {
+	f, err := New(Options{Where: `age > duration("720h")`})
+	require.NoError(t, err)
+	stack := models.Stack{StackName: "orders-api-dev", UpdatedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+
+	f.AsOf = time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	assert.False(t, f.MatchStack(stack))
+
+	f.AsOf = time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
+	assert.True(t, f.MatchStack(stack))
+}
+
+func TestFilter_Nil(t *testing.T) {
+	var f *Filter
+	stacks := []models.Stack{{StackName: "a"}, {StackName: "b"}}
+
+	assert.True(t, f.MatchStack(stacks[0]))
+	assert.True(t, f.MatchSummary(types.StackSummary{}))
+	assert.Equal(t, stacks, f.Apply(stacks))
+}
+
+func TestFilter_Apply(t *testing.T) {
+	f, err := New(Options{Tags: []string{"!Deprecated"}})
+	require.NoError(t, err)
+
+	stacks := []models.Stack{
+		{StackName: "a", StackTags: map[string]string{"Deprecated": "true"}},
+		{StackName: "b"},
+	}
+
+	result := f.Apply(stacks)
+	require.Len(t, result, 1)
+	assert.Equal(t, "b", result[0].StackName)
+}
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// Appearance records when a stack was first and last seen in the history
+type Appearance struct {
+	StackName string    `json:"stackName"`
+	StackID   string    `json:"stackId"`
+	Account   string    `json:"account"`
+	Region    string    `json:"region"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// MonthlyCount is the number of Serverless stacks recorded in a calendar month
+type MonthlyCount struct {
+	Month  string `json:"month"`
+	Stacks int    `json:"stacks"`
+}
+
+// FirstSeen finds every stack belonging to a service and when it first appeared.
+// Serverless Framework names stacks "<service>-<stage>", so a service matches
+// stacks named exactly after it or prefixed with "<service>-".
+func FirstSeen(snapshots []Snapshot, service string) []Appearance {
+	byID := make(map[string]*Appearance)
+	var order []string
+
+	for _, snapshot := range sortedCopy(snapshots) {
+		for _, stack := range snapshot.Stacks {
+			if !matchesService(stack.StackName, service) {
+				continue
+			}
+
+			appearance, exists := byID[stack.StackID]
+			if !exists {
+				appearance = &Appearance{
+					StackName: stack.StackName,
+					StackID:   stack.StackID,
+					Account:   snapshot.Account,
+					Region:    snapshot.Region,
+					FirstSeen: snapshot.ScannedAt,
+				}
+				byID[stack.StackID] = appearance
+				order = append(order, stack.StackID)
+			}
+			appearance.LastSeen = snapshot.ScannedAt
+		}
+	}
+
+	appearances := make([]Appearance, 0, len(order))
+	for _, id := range order {
+		appearances = append(appearances, *byID[id])
+	}
+	return appearances
+}
+
+// At returns the stacks that existed at the given time, taken from the latest
+// snapshot of each account and region recorded at or before it
+func At(snapshots []Snapshot, at time.Time) []models.Stack {
+	latest := make(map[string]Snapshot)
+	for _, snapshot := range snapshots {
+		if snapshot.ScannedAt.After(at) {
+			continue
+		}
+		key := snapshot.Account + "/" + snapshot.Region
+		if current, exists := latest[key]; !exists || snapshot.ScannedAt.After(current.ScannedAt) {
+			latest[key] = snapshot
+		}
+	}
+
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stacks := []models.Stack{}
+	for _, key := range keys {
+		stacks = append(stacks, latest[key].Stacks...)
+	}
+	return stacks
+}
+
+// MonthlyCounts counts Serverless stacks per calendar month (UTC), using the
+// last snapshot of each account and region within the month
+func MonthlyCounts(snapshots []Snapshot) []MonthlyCount {
+	// month -> account/region -> latest snapshot within that month
+	latest := make(map[string]map[string]Snapshot)
+	for _, snapshot := range snapshots {
+		month := snapshot.ScannedAt.UTC().Format("2006-01")
+		if latest[month] == nil {
+			latest[month] = make(map[string]Snapshot)
+		}
+		key := snapshot.Account + "/" + snapshot.Region
+		if current, exists := latest[month][key]; !exists || snapshot.ScannedAt.After(current.ScannedAt) {
+			latest[month][key] = snapshot
+		}
+	}
+
+	counts := make([]MonthlyCount, 0, len(latest))
+	for month, byScope := range latest {
+		count := MonthlyCount{Month: month}
+		for _, snapshot := range byScope {
+			count.Stacks += len(snapshot.Stacks)
+		}
+		counts = append(counts, count)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		return counts[i].Month < counts[j].Month
+	})
+	return counts
+}
+
+// matchesService reports whether a stack name belongs to the service
+func matchesService(stackName, service string) bool {
+	return stackName == service || strings.HasPrefix(stackName, service+"-")
+}
+
+// sortedCopy returns the snapshots in chronological order without modifying the input
+func sortedCopy(snapshots []Snapshot) []Snapshot {
+	sorted := append([]Snapshot(nil), snapshots...)
+	sortChronologically(sorted)
+	return sorted
+}
+
+// sortChronologically orders snapshots by scan time
+func sortChronologically(snapshots []Snapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].ScannedAt.Before(snapshots[j].ScannedAt)
+	})
+}
@@ -0,0 +1,106 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stack(name string) models.Stack {
+	return models.Stack{
+		StackName: name,
+		StackID:   "arn:aws:cloudformation:us-east-1:123456789012:stack/" + name + "/abc123",
+		Region:    "us-east-1",
+	}
+}
+
+func day(month time.Month, d int) time.Time {
+	return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC)
+}
+
+func sampleSnapshots() []Snapshot {
+	return []Snapshot{
+		{Account: "111111111111", Region: "us-east-1", ScannedAt: day(time.January, 10), Stacks: []models.Stack{stack("orders-dev")}},
+		{Account: "111111111111", Region: "us-east-1", ScannedAt: day(time.January, 20), Stacks: []models.Stack{stack("orders-dev"), stack("orders-prod")}},
+		{Account: "111111111111", Region: "us-east-1", ScannedAt: day(time.February, 5), Stacks: []models.Stack{stack("orders-prod"), stack("payments-dev")}},
+		{Account: "222222222222", Region: "eu-west-1", ScannedAt: day(time.February, 6), Stacks: []models.Stack{stack("billing-prod")}},
+	}
+}
+
+func TestFirstSeen(t *testing.T) {
+	appearances := FirstSeen(sampleSnapshots(), "orders")
+
+	require.Len(t, appearances, 2)
+	assert.Equal(t, "orders-dev", appearances[0].StackName)
+	assert.Equal(t, day(time.January, 10), appearances[0].FirstSeen)
+	assert.Equal(t, day(time.January, 20), appearances[0].LastSeen)
+	assert.Equal(t, "orders-prod", appearances[1].StackName)
+	assert.Equal(t, day(time.January, 20), appearances[1].FirstSeen)
+	assert.Equal(t, day(time.February, 5), appearances[1].LastSeen)
+}
+
+func TestFirstSeen_ServiceMatching(t *testing.T) {
+	snapshots := []Snapshot{
+		{Account: "111111111111", Region: "us-east-1", ScannedAt: day(time.January, 1), Stacks: []models.Stack{
+			stack("orders"), stack("orders-dev"), stack("ordersapi-dev"),
+		}},
+	}
+
+	appearances := FirstSeen(snapshots, "orders")
+
+	require.Len(t, appearances, 2)
+	assert.Equal(t, "orders", appearances[0].StackName)
+	assert.Equal(t, "orders-dev", appearances[1].StackName)
+
+	assert.Empty(t, FirstSeen(snapshots, "unknown"))
+}
+
+func TestAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected []string
+	}{
+		{
+			name:     "before first snapshot",
+			at:       day(time.January, 1),
+			expected: []string{},
+		},
+		{
+			name:     "between snapshots",
+			at:       day(time.January, 25),
+			expected: []string{"orders-dev", "orders-prod"},
+		},
+		{
+			name:     "latest snapshot per account and region",
+			at:       day(time.March, 1),
+			expected: []string{"orders-prod", "payments-dev", "billing-prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stacks := At(sampleSnapshots(), tt.at)
+
+			names := []string{}
+			for _, s := range stacks {
+				names = append(names, s.StackName)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestMonthlyCounts(t *testing.T) {
+	counts := MonthlyCounts(sampleSnapshots())
+
+	assert.Equal(t, []MonthlyCount{
+		{Month: "2026-01", Stacks: 2},
+		{Month: "2026-02", Stacks: 3},
+	}, counts)
+
+	assert.Empty(t, MonthlyCounts(nil))
+}
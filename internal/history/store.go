@@ -0,0 +1,139 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// snapshotsBucket holds one entry per recorded scan
+var snapshotsBucket = []byte("snapshots")
+
+// keyTimeFormat is a fixed-width, lexically sortable timestamp layout
+const keyTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// Snapshot is the full result of one scan of an account and region
+type Snapshot struct {
+	Account   string         `json:"account"`
+	Region    string         `json:"region"`
+	ScannedAt time.Time      `json:"scannedAt"`
+	Stacks    []models.Stack `json:"stacks"`
+}
+
+// Filter restricts which snapshots are read from the store
+type Filter struct {
+	Account string
+	Region  string
+}
+
+// matches reports whether the snapshot passes the filter
+func (f Filter) matches(snapshot Snapshot) bool {
+	if f.Account != "" && snapshot.Account != f.Account {
+		return false
+	}
+	if f.Region != "" && snapshot.Region != f.Region {
+		return false
+	}
+	return true
+}
+
+// Store is a local scan history database backed by an embedded bbolt file
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns the default location of the history database
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".find-serverless-stacks", "history.db"), nil
+}
+
+// Open opens the history database at path, creating it if necessary
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// Time out instead of blocking forever when another process holds the lock
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record stores a scan snapshot
+func (s *Store) Record(snapshot Snapshot) error {
+	if snapshot.Account == "" || snapshot.Region == "" {
+		return fmt.Errorf("snapshot requires an account and a region")
+	}
+	if snapshot.Stacks == nil {
+		snapshot.Stacks = []models.Stack{}
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put(snapshotKey(snapshot), data)
+	})
+}
+
+// Snapshots returns the snapshots matching the filter in chronological order
+func (s *Store) Snapshots(filter Filter) ([]Snapshot, error) {
+	var snapshots []Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEach(func(key, value []byte) error {
+			var snapshot Snapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return fmt.Errorf("failed to decode snapshot %s: %w", key, err)
+			}
+			if filter.matches(snapshot) {
+				snapshots = append(snapshots, snapshot)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortChronologically(snapshots)
+	return snapshots, nil
+}
+
+// snapshotKey builds the storage key: account/region/timestamp
+func snapshotKey(snapshot Snapshot) []byte {
+	return []byte(strings.Join([]string{
+		snapshot.Account,
+		snapshot.Region,
+		snapshot.ScannedAt.UTC().Format(keyTimeFormat),
+	}, "/"))
+}
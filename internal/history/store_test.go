@@ -0,0 +1,97 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "nested", "history.db")
+	store, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store, path
+}
+
+func TestStore_RecordAndRead(t *testing.T) {
+	store, path := openTestStore(t)
+
+	t1 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	// Record out of order to verify chronological reads
+	require.NoError(t, store.Record(Snapshot{
+		Account:   "123456789012",
+		Region:    "us-east-1",
+		ScannedAt: t2,
+		Stacks:    []models.Stack{{StackName: "api-dev", StackID: "id-1"}},
+	}))
+	require.NoError(t, store.Record(Snapshot{
+		Account:   "123456789012",
+		Region:    "us-east-1",
+		ScannedAt: t1,
+	}))
+	require.NoError(t, store.Record(Snapshot{
+		Account:   "210987654321",
+		Region:    "eu-west-1",
+		ScannedAt: t1,
+	}))
+
+	snapshots, err := store.Snapshots(Filter{})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 3)
+	assert.True(t, snapshots[0].ScannedAt.Equal(t1))
+	assert.True(t, snapshots[2].ScannedAt.Equal(t2))
+	assert.Equal(t, "api-dev", snapshots[2].Stacks[0].StackName)
+	assert.NotNil(t, snapshots[0].Stacks)
+
+	snapshots, err = store.Snapshots(Filter{Account: "123456789012"})
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+
+	snapshots, err = store.Snapshots(Filter{Region: "eu-west-1"})
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestStore_RecordRequiresScope(t *testing.T) {
+	store, _ := openTestStore(t)
+
+	err := store.Record(Snapshot{Region: "us-east-1", ScannedAt: time.Now()})
+	assert.Error(t, err)
+
+	err = store.Record(Snapshot{Account: "123456789012", ScannedAt: time.Now()})
+	assert.Error(t, err)
+}
+
+func TestStore_Reopen(t *testing.T) {
+	store, path := openTestStore(t)
+
+	require.NoError(t, store.Record(Snapshot{
+		Account:   "123456789012",
+		Region:    "us-east-1",
+		ScannedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}))
+	require.NoError(t, store.Close())
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	snapshots, err := reopened.Snapshots(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+}
@@ -0,0 +1,341 @@
+// Package iampolicy generates least-privilege IAM policies for the tool
+// from the API call registry of the aws package.
+package iampolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// Version is the IAM policy language version
+const Version = "2012-10-17"
+
+// DefaultPartition is the partition used when none is given
+const DefaultPartition = "aws"
+
+// deploymentBucketPattern matches the buckets Serverless Framework creates for a stack.
+// It misses buckets set with provider.deploymentBucket, and names CloudFormation
+// shortened for long stack names; Options.Buckets names them instead.
+const deploymentBucketPattern = "*-serverlessdeploymentbucket-*"
+
+// DeploymentBucketOutput is the stack output Serverless Framework names the deployment bucket in
+const DeploymentBucketOutput = "ServerlessDeploymentBucketName"
+
+var accountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+
+// Document is an IAM policy document
+type Document struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Statement is a statement of an IAM policy document
+type Statement struct {
+	Sid       string                         `json:"Sid,omitempty"`
+	Effect    string                         `json:"Effect"`
+	Principal map[string][]string            `json:"Principal,omitempty"`
+	Action    []string                       `json:"Action"`
+	Resource  []string                       `json:"Resource,omitempty"`
+	Condition map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+// Marshal encodes the document as indented JSON with a trailing newline
+func (d *Document) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode policy: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Options selects what the permissions policy grants
+type Options struct {
+	// Features are added to scanning, which is always granted
+	Features []aws.Feature
+	// Partition defaults to the partition of Regions, or DefaultPartition without regions
+	Partition string
+	// Account scopes stack ARNs to one account; empty allows any account
+	Account string
+	// Regions scopes the policy to the regions scanned; empty allows any region
+	Regions []string
+	// Buckets scopes the deployment bucket permissions to these bucket names;
+	// empty matches the names Serverless Framework generates
+	Buckets []string
+}
+
+// OptionalFeatures are the features that can be added to a permissions policy
+var OptionalFeatures = []aws.Feature{aws.FeatureDelete, aws.FeatureDoctor}
+
+// ParseFeature returns the optional feature with the given name
+func ParseFeature(name string) (aws.Feature, error) {
+	for _, feature := range OptionalFeatures {
+		if string(feature) == name {
+			return feature, nil
+		}
+	}
+
+	names := make([]string, len(OptionalFeatures))
+	for i, feature := range OptionalFeatures {
+		names[i] = string(feature)
+	}
+	return "", fmt.Errorf("unknown feature %q (supported: %s)", name, strings.Join(names, ", "))
+}
+
+// statementScopes fixes the order and Sid of the statements of a permissions policy
+var statementScopes = []struct {
+	scope aws.ResourceScope
+	sid   string
+}{
+	{aws.ResourceAny, "AllResources"},
+	{aws.ResourceStack, "Stacks"},
+	{aws.ResourceAccount, "Account"},
+	{aws.ResourceDeploymentBucket, "DeploymentBuckets"},
+	{aws.ResourceDeploymentObject, "DeploymentBucketObjects"},
+}
+
+// Permissions builds the identity policy granting exactly the actions of the selected features
+func Permissions(opts Options) (*Document, error) {
+	if opts.Account != "" && !accountIDPattern.MatchString(opts.Account) {
+		return nil, fmt.Errorf("invalid account ID %q: must be 12 digits", opts.Account)
+	}
+	var err error
+	opts.Regions, err = normalizeNames("region", opts.Regions)
+	if err != nil {
+		return nil, err
+	}
+	opts.Buckets, err = normalizeNames("bucket", opts.Buckets)
+	if err != nil {
+		return nil, err
+	}
+	opts.Partition, err = regionsPartition(opts.Partition, opts.Regions)
+	if err != nil {
+		return nil, err
+	}
+
+	features := append([]aws.Feature{aws.FeatureScan}, opts.Features...)
+	actions := make(map[aws.ResourceScope][]string)
+	for _, feature := range features {
+		for _, call := range aws.CallsOf(feature) {
+			for _, action := range call.Actions {
+				if !slices.Contains(actions[call.Resource], action) {
+					actions[call.Resource] = append(actions[call.Resource], action)
+				}
+			}
+		}
+	}
+
+	doc := &Document{Version: Version}
+	for _, s := range statementScopes {
+		if len(actions[s.scope]) == 0 {
+			continue
+		}
+		statement := Statement{
+			Sid:      s.sid,
+			Effect:   "Allow",
+			Action:   slices.Sorted(slices.Values(actions[s.scope])),
+			Resource: resources(s.scope, opts),
+		}
+		// Actions without resource-level permissions are scoped by the requested region instead
+		if s.scope == aws.ResourceAny && len(opts.Regions) > 0 {
+			statement.Condition = map[string]map[string][]string{
+				"StringEquals": {"aws:RequestedRegion": opts.Regions},
+			}
+		}
+		doc.Statement = append(doc.Statement, statement)
+	}
+
+	return doc, nil
+}
+
+// ReadOnlySessionPolicy returns the session policy limiting an assumed role to
+// the read actions of a scan, as compact JSON to stay within the STS size limit
+func ReadOnlySessionPolicy(partition string) (string, error) {
+	doc, err := Permissions(Options{Partition: partition})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session policy: %w", err)
+	}
+	return string(data), nil
+}
+
+// DeploymentBuckets returns the deployment bucket names the stacks record in their outputs
+func DeploymentBuckets(stacks []models.Stack) ([]string, error) {
+	var buckets []string
+	for _, stack := range stacks {
+		bucket := stack.Outputs[DeploymentBucketOutput]
+		if bucket == "" {
+			return nil, fmt.Errorf("stack %s in %s has no %s output; name its bucket with --bucket", stack.StackName, stack.Region, DeploymentBucketOutput)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// normalizeNames sorts and de-duplicates the names of a kind, such as regions
+func normalizeNames(kind string, names []string) ([]string, error) {
+	var result []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%s must not be empty", kind)
+		}
+		if !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	slices.Sort(result)
+	return result, nil
+}
+
+// regionsPartition returns the partition of the policy: the given one, which every
+// region must belong to, or else the one shared by the regions
+func regionsPartition(partition string, regions []string) (string, error) {
+	if partition != "" {
+		if _, ok := aws.LookupPartition(partition); !ok {
+			return "", fmt.Errorf("unknown partition %q", partition)
+		}
+	}
+
+	for _, region := range regions {
+		p, ok := aws.PartitionOfRegion(region)
+		if !ok {
+			return "", fmt.Errorf("%q is not a region of any AWS partition", region)
+		}
+		if partition == "" {
+			partition = p.ID
+		}
+		if p.ID != partition {
+			return "", fmt.Errorf("region %s is in partition %s, not %s; generate a policy per partition", region, p.ID, partition)
+		}
+	}
+
+	if partition == "" {
+		partition = DefaultPartition
+	}
+	return partition, nil
+}
+
+// resources returns the resource ARNs of a scope
+func resources(scope aws.ResourceScope, opts Options) []string {
+	partition, account, regions := opts.Partition, opts.Account, opts.Regions
+	if account == "" {
+		account = "*"
+	}
+	buckets := opts.Buckets
+	if len(buckets) == 0 {
+		buckets = []string{deploymentBucketPattern}
+	}
+
+	switch scope {
+	case aws.ResourceStack:
+		if len(regions) == 0 {
+			return []string{fmt.Sprintf("arn:%s:cloudformation:*:%s:stack/*/*", partition, account)}
+		}
+		arns := make([]string, len(regions))
+		for i, region := range regions {
+			arns[i] = fmt.Sprintf("arn:%s:cloudformation:%s:%s:stack/*/*", partition, region, account)
+		}
+		return arns
+	case aws.ResourceAccount:
+		return []string{fmt.Sprintf("arn:%s:account::%s:account", partition, account)}
+	case aws.ResourceDeploymentBucket:
+		arns := make([]string, len(buckets))
+		for i, bucket := range buckets {
+			arns[i] = fmt.Sprintf("arn:%s:s3:::%s", partition, bucket)
+		}
+		return arns
+	case aws.ResourceDeploymentObject:
+		arns := make([]string, len(buckets))
+		for i, bucket := range buckets {
+			arns[i] = fmt.Sprintf("arn:%s:s3:::%s/*", partition, bucket)
+		}
+		return arns
+	default:
+		return []string{"*"}
+	}
+}
+
+// TrustOptions describes who may assume the scanning role
+type TrustOptions struct {
+	// Principals are account IDs or IAM principal ARNs
+	Principals []string
+	// ExternalID, when set, must be passed by the caller to assume the role
+	ExternalID string
+	// SessionTags allows the caller to pass --session-tag
+	SessionTags bool
+	// SourceIdentity allows the caller to pass --source-identity
+	SourceIdentity bool
+	// Partition defaults to DefaultPartition
+	Partition string
+}
+
+// Trust builds the trust policy of a cross-account scanning role
+func Trust(opts TrustOptions) (*Document, error) {
+	if opts.Partition == "" {
+		opts.Partition = DefaultPartition
+	}
+	if _, ok := aws.LookupPartition(opts.Partition); !ok {
+		return nil, fmt.Errorf("unknown partition %q", opts.Partition)
+	}
+	if len(opts.Principals) == 0 {
+		return nil, fmt.Errorf("at least one trusted principal is required")
+	}
+
+	var principals []string
+	for _, principal := range opts.Principals {
+		arn, err := principalARN(principal, opts.Partition)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(principals, arn) {
+			principals = append(principals, arn)
+		}
+	}
+
+	var actions []string
+	for _, call := range aws.CallsOf(aws.FeatureAssumeRole) {
+		actions = append(actions, call.Actions...)
+	}
+	if opts.SessionTags {
+		actions = append(actions, aws.ActionTagSession)
+	}
+	if opts.SourceIdentity {
+		actions = append(actions, aws.ActionSetSourceIdentity)
+	}
+
+	statement := Statement{
+		Sid:       "AllowScanner",
+		Effect:    "Allow",
+		Principal: map[string][]string{"AWS": principals},
+		Action:    slices.Sorted(slices.Values(actions)),
+	}
+	if opts.ExternalID != "" {
+		statement.Condition = map[string]map[string][]string{
+			"StringEquals": {"sts:ExternalId": {opts.ExternalID}},
+		}
+	}
+
+	return &Document{Version: Version, Statement: []Statement{statement}}, nil
+}
+
+// principalARN turns an account ID into its root ARN and checks other principals are ARNs
+func principalARN(principal, partition string) (string, error) {
+	principal = strings.TrimSpace(principal)
+	if accountIDPattern.MatchString(principal) {
+		return fmt.Sprintf("arn:%s:iam::%s:root", partition, principal), nil
+	}
+	if strings.HasPrefix(principal, "arn:") {
+		return principal, nil
+	}
+	return "", fmt.Errorf("invalid principal %q: must be a 12-digit account ID or an IAM ARN", principal)
+}
@@ -0,0 +1,277 @@
+package iampolicy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/aws"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// actionsOf returns every action granted by a document
+func actionsOf(doc *Document) []string {
+	var actions []string
+	for _, statement := range doc.Statement {
+		actions = append(actions, statement.Action...)
+	}
+	return actions
+}
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		want    []Statement
+		wantErr string
+	}{
+		{
+			name: "scan only",
+			opts: Options{},
+			want: []Statement{
+				{Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"}},
+				{Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"}, Resource: []string{"arn:aws:cloudformation:*:*:stack/*/*"}},
+			},
+		},
+		{
+			name: "regions and account",
+			opts: Options{Account: "123456789012", Regions: []string{"us-east-1", "eu-west-1", "us-east-1"}},
+			want: []Statement{
+				{
+					Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"},
+					Condition: map[string]map[string][]string{"StringEquals": {"aws:RequestedRegion": {"eu-west-1", "us-east-1"}}},
+				},
+				{
+					Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"},
+					Resource: []string{
+						"arn:aws:cloudformation:eu-west-1:123456789012:stack/*/*",
+						"arn:aws:cloudformation:us-east-1:123456789012:stack/*/*",
+					},
+				},
+			},
+		},
+		{
+			name: "delete and doctor",
+			opts: Options{Features: []aws.Feature{aws.FeatureDelete, aws.FeatureDoctor}, Account: "123456789012"},
+			want: []Statement{
+				{Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"}},
+				{Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DeleteStack", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"}, Resource: []string{"arn:aws:cloudformation:*:123456789012:stack/*/*"}},
+				{Sid: "Account", Effect: "Allow", Action: []string{"account:GetRegionOptStatus"}, Resource: []string{"arn:aws:account::123456789012:account"}},
+				{Sid: "DeploymentBuckets", Effect: "Allow", Action: []string{"s3:ListBucketVersions"}, Resource: []string{"arn:aws:s3:::*-serverlessdeploymentbucket-*"}},
+				{Sid: "DeploymentBucketObjects", Effect: "Allow", Action: []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}, Resource: []string{"arn:aws:s3:::*-serverlessdeploymentbucket-*/*"}},
+			},
+		},
+		{
+			name: "partition",
+			opts: Options{Partition: "aws-cn", Regions: []string{"cn-north-1"}},
+			want: []Statement{
+				{
+					Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"},
+					Condition: map[string]map[string][]string{"StringEquals": {"aws:RequestedRegion": {"cn-north-1"}}},
+				},
+				{Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"}, Resource: []string{"arn:aws-cn:cloudformation:cn-north-1:*:stack/*/*"}},
+			},
+		},
+		{
+			name: "partition of the regions",
+			opts: Options{Features: []aws.Feature{aws.FeatureDelete}, Regions: []string{"us-gov-west-1"}},
+			want: []Statement{
+				{
+					Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"},
+					Condition: map[string]map[string][]string{"StringEquals": {"aws:RequestedRegion": {"us-gov-west-1"}}},
+				},
+				{Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DeleteStack", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"}, Resource: []string{"arn:aws-us-gov:cloudformation:us-gov-west-1:*:stack/*/*"}},
+				{Sid: "DeploymentBuckets", Effect: "Allow", Action: []string{"s3:ListBucketVersions"}, Resource: []string{"arn:aws-us-gov:s3:::*-serverlessdeploymentbucket-*"}},
+				{Sid: "DeploymentBucketObjects", Effect: "Allow", Action: []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}, Resource: []string{"arn:aws-us-gov:s3:::*-serverlessdeploymentbucket-*/*"}},
+			},
+		},
+		{
+			name: "deployment buckets",
+			opts: Options{Features: []aws.Feature{aws.FeatureDelete}, Buckets: []string{"my-deployments", "app-dev-serverlessdeploymentbucket-1a2b", "my-deployments"}},
+			want: []Statement{
+				{Sid: "AllResources", Effect: "Allow", Action: []string{"cloudformation:ListStacks"}, Resource: []string{"*"}},
+				{Sid: "Stacks", Effect: "Allow", Action: []string{"cloudformation:DeleteStack", "cloudformation:DescribeStackResources", "cloudformation:DescribeStacks"}, Resource: []string{"arn:aws:cloudformation:*:*:stack/*/*"}},
+				{Sid: "DeploymentBuckets", Effect: "Allow", Action: []string{"s3:ListBucketVersions"}, Resource: []string{"arn:aws:s3:::app-dev-serverlessdeploymentbucket-1a2b", "arn:aws:s3:::my-deployments"}},
+				{Sid: "DeploymentBucketObjects", Effect: "Allow", Action: []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}, Resource: []string{"arn:aws:s3:::app-dev-serverlessdeploymentbucket-1a2b/*", "arn:aws:s3:::my-deployments/*"}},
+			},
+		},
+		{
+			name:    "empty bucket",
+			opts:    Options{Features: []aws.Feature{aws.FeatureDelete}, Buckets: []string{""}},
+			wantErr: "bucket must not be empty",
+		},
+		{
+			name:    "regions of several partitions",
+			opts:    Options{Regions: []string{"us-east-1", "cn-north-1"}},
+			wantErr: "region us-east-1 is in partition aws, not aws-cn",
+		},
+		{
+			name:    "region outside the partition",
+			opts:    Options{Partition: "aws-cn", Regions: []string{"us-east-1"}},
+			wantErr: "region us-east-1 is in partition aws, not aws-cn",
+		},
+		{
+			name:    "unknown partition",
+			opts:    Options{Partition: "aws-mars"},
+			wantErr: `unknown partition "aws-mars"`,
+		},
+		{
+			name:    "unknown region",
+			opts:    Options{Regions: []string{"moon-base-1"}},
+			wantErr: "not a region of any AWS partition",
+		},
+		{
+			name:    "invalid account",
+			opts:    Options{Account: "12345"},
+			wantErr: "invalid account ID",
+		},
+		{
+			name:    "empty region",
+			opts:    Options{Regions: []string{" "}},
+			wantErr: "region must not be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Permissions(tt.opts)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, Version, doc.Version)
+			assert.Equal(t, tt.want, doc.Statement)
+		})
+	}
+}
+
+func TestPermissions_CoversRegistry(t *testing.T) {
+	doc, err := Permissions(Options{Features: OptionalFeatures})
+	require.NoError(t, err)
+	granted := actionsOf(doc)
+
+	for _, call := range aws.APICalls {
+		if call.Feature == aws.FeatureAssumeRole {
+			continue
+		}
+		for _, action := range call.Actions {
+			assert.Contains(t, granted, action)
+		}
+	}
+	assert.NotContains(t, granted, "sts:GetCallerIdentity")
+}
+
+func TestDeploymentBuckets(t *testing.T) {
+	buckets, err := DeploymentBuckets([]models.Stack{
+		{StackName: "app-dev", Region: "us-east-1", Outputs: map[string]string{DeploymentBucketOutput: "app-dev-serverlessdeploymentbuck-1a2b"}},
+		{StackName: "api-prod", Region: "eu-west-1", Outputs: map[string]string{DeploymentBucketOutput: "my-deployments"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app-dev-serverlessdeploymentbuck-1a2b", "my-deployments"}, buckets)
+
+	_, err = DeploymentBuckets([]models.Stack{{StackName: "app-dev", Region: "us-east-1"}})
+	assert.ErrorContains(t, err, "stack app-dev in us-east-1 has no ServerlessDeploymentBucketName output")
+}
+
+func TestParseFeature(t *testing.T) {
+	feature, err := ParseFeature("delete")
+	require.NoError(t, err)
+	assert.Equal(t, aws.FeatureDelete, feature)
+
+	_, err = ParseFeature("scan")
+	assert.ErrorContains(t, err, "unknown feature")
+}
+
+func TestTrust(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    TrustOptions
+		want    Statement
+		wantErr string
+	}{
+		{
+			name: "account",
+			opts: TrustOptions{Principals: []string{"123456789012"}},
+			want: Statement{
+				Sid: "AllowScanner", Effect: "Allow", Action: []string{"sts:AssumeRole"},
+				Principal: map[string][]string{"AWS": {"arn:aws:iam::123456789012:root"}},
+			},
+		},
+		{
+			name: "role with external ID",
+			opts: TrustOptions{Principals: []string{"arn:aws:iam::123456789012:role/ci", "arn:aws:iam::123456789012:role/ci"}, ExternalID: "secret"},
+			want: Statement{
+				Sid: "AllowScanner", Effect: "Allow", Action: []string{"sts:AssumeRole"},
+				Principal: map[string][]string{"AWS": {"arn:aws:iam::123456789012:role/ci"}},
+				Condition: map[string]map[string][]string{"StringEquals": {"sts:ExternalId": {"secret"}}},
+			},
+		},
+		{
+			name: "session tags and source identity",
+			opts: TrustOptions{Principals: []string{"123456789012"}, SessionTags: true, SourceIdentity: true},
+			want: Statement{
+				Sid: "AllowScanner", Effect: "Allow", Action: []string{"sts:AssumeRole", "sts:SetSourceIdentity", "sts:TagSession"},
+				Principal: map[string][]string{"AWS": {"arn:aws:iam::123456789012:root"}},
+			},
+		},
+		{
+			name:    "no principal",
+			opts:    TrustOptions{},
+			wantErr: "at least one trusted principal",
+		},
+		{
+			name:    "invalid principal",
+			opts:    TrustOptions{Principals: []string{"ci-role"}},
+			wantErr: "invalid principal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Trust(tt.opts)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, doc.Statement, 1)
+			assert.Equal(t, tt.want, doc.Statement[0])
+		})
+	}
+}
+
+func TestDocument_Marshal(t *testing.T) {
+	doc, err := Trust(TrustOptions{Principals: []string{"123456789012"}})
+	require.NoError(t, err)
+
+	data, err := doc.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, byte('\n'), data[len(data)-1])
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "2012-10-17", decoded["Version"])
+	statement := decoded["Statement"].([]any)[0].(map[string]any)
+	assert.NotContains(t, statement, "Resource")
+	assert.NotContains(t, statement, "Condition")
+}
+
+func TestReadOnlySessionPolicy(t *testing.T) {
+	policy, err := ReadOnlySessionPolicy("aws-us-gov")
+	require.NoError(t, err)
+	assert.NotContains(t, policy, "\n")
+	assert.Less(t, len(policy), 2048, "STS rejects session policies over 2048 characters")
+
+	var doc Document
+	require.NoError(t, json.Unmarshal([]byte(policy), &doc))
+	var actions []string
+	for _, statement := range doc.Statement {
+		actions = append(actions, statement.Action...)
+	}
+	assert.ElementsMatch(t, []string{"cloudformation:ListStacks", "cloudformation:DescribeStacks", "cloudformation:DescribeStackResources"}, actions)
+	assert.Contains(t, policy, "arn:aws-us-gov:cloudformation:")
+}
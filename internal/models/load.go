@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ReadStacksOutput decodes a previously saved JSON output document
+func ReadStacksOutput(r io.Reader) (*StacksOutput, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stacks output: %w", err)
+	}
+
+	var output StacksOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to decode stacks output: %w", err)
+	}
+
+	if output.SchemaVersion > LatestSchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d (this version reads up to %d)", output.SchemaVersion, LatestSchemaVersion)
+	}
+
+	// Version 1 reported never-updated stacks with their creation time as the update time
+	if output.SchemaVersion < 2 {
+		for i := range output.Stacks {
+			stack := &output.Stacks[i]
+			if !stack.CreatedAt.IsZero() && stack.UpdatedAt.Equal(stack.CreatedAt) {
+				stack.UpdatedAt = time.Time{}
+				stack.NeverUpdated = true
+			}
+		}
+	}
+
+	if output.Stacks == nil {
+		output.Stacks = []Stack{}
+	}
+
+	output.MissingFields = missingFields(data, output.Scan)
+
+	return &output, nil
+}
+
+// missingFields returns the optional fields the document does not contain.
+// Documents that do not record their fields are assumed to contain the fields
+// written for at least one stack.
+func missingFields(data []byte, scan *ScanMetadata) []string {
+	present := make(map[string]bool)
+	if scan != nil && scan.Fields != nil {
+		for _, field := range scan.Fields {
+			present[field] = true
+		}
+	} else {
+		var raw struct {
+			Stacks []map[string]json.RawMessage `json:"stacks"`
+		}
+		// The document was decoded already, so this cannot fail
+		_ = json.Unmarshal(data, &raw)
+		for _, stack := range raw.Stacks {
+			for key := range stack {
+				present[key] = true
+			}
+		}
+	}
+
+	var missing []string
+	for _, field := range OptionalFields {
+		if !present[field] {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// LoadStacksOutput reads a saved JSON output document from a file.
+// The path "-" reads from standard input.
+func LoadStacksOutput(path string) (*StacksOutput, error) {
+	if path == "-" {
+		return ReadStacksOutput(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	output, err := ReadStacksOutput(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadStacksOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expectCount int
+		expectError bool
+	}{
+		{
+			name:        "single stack",
+			input:       `{"stacks":[{"stackName":"my-api-dev","stackId":"id-1","region":"us-east-1"}]}`,
+			expectCount: 1,
+		},
+		{
+			name:        "empty stack list",
+			input:       `{"stacks":[]}`,
+			expectCount: 0,
+		},
+		{
+			name:        "missing stacks key",
+			input:       `{}`,
+			expectCount: 0,
+		},
+		{
+			name:        "versioned document with scan metadata",
+			input:       `{"schemaVersion":1,"scan":{"startedAt":"2024-01-01T00:00:00Z","regions":["us-east-1"]},"stacks":[{"stackName":"a"}]}`,
+			expectCount: 1,
+		},
+		{
+			name:        "newer schema version",
+			input:       `{"schemaVersion":99,"stacks":[]}`,
+			expectError: true,
+		},
+		{
+			name:        "invalid JSON",
+			input:       `{"stacks":`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := ReadStacksOutput(strings.NewReader(tt.input))
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotNil(t, output.Stacks)
+			assert.Len(t, output.Stacks, tt.expectCount)
+		})
+	}
+}
+
+func TestLoadStacksOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "scan.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"stacks":[{"stackName":"my-api-dev"}]}`), 0o644))
+
+	output, err := LoadStacksOutput(path)
+	require.NoError(t, err)
+	require.Len(t, output.Stacks, 1)
+	assert.Equal(t, "my-api-dev", output.Stacks[0].StackName)
+
+	_, err = LoadStacksOutput(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "missing.json")
+}
+
+func TestReadStacksOutput_NormalizesLegacyTimestamps(t *testing.T) {
+	input := `{"schemaVersion":1,"stacks":[
+		{"stackName":"never-updated","createdAt":"2023-10-01T12:34:56Z","updatedAt":"2023-10-01T12:34:56Z"},
+		{"stackName":"updated","createdAt":"2023-10-01T12:34:56Z","updatedAt":"2023-10-02T12:34:56Z"}
+	]}`
+
+	output, err := ReadStacksOutput(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, output.Stacks, 2)
+
+	assert.True(t, output.Stacks[0].UpdatedAt.IsZero())
+	assert.True(t, output.Stacks[0].NeverUpdated)
+	assert.False(t, output.Stacks[1].UpdatedAt.IsZero())
+	assert.False(t, output.Stacks[1].NeverUpdated)
+
+	// Version 2 documents are taken as written
+	input = `{"schemaVersion":2,"stacks":[{"stackName":"a","createdAt":"2023-10-01T12:34:56Z","updatedAt":"2023-10-01T12:34:56Z"}]}`
+	output, err = ReadStacksOutput(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.False(t, output.Stacks[0].UpdatedAt.IsZero())
+	assert.False(t, output.Stacks[0].NeverUpdated)
+}
+
+func TestReadStacksOutput_MissingFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "default fields",
+			input:    `{"scan":{"startedAt":"2024-01-01T00:00:00Z"},"stacks":[{"stackName":"a"}]}`,
+			expected: OptionalFields,
+		},
+		{
+			name:  "fields written for some stacks",
+			input: `{"stacks":[{"stackName":"a","terminationProtection":false},{"stackName":"b","outputs":{"Url":"x"}}]}`,
+			expected: []string{"stackStatus", "roleArn", "notificationArns", "driftStatus", "parentId", "rootId",
+				"capabilities", "parameters"},
+		},
+		{
+			name:     "recorded fields",
+			input:    `{"scan":{"startedAt":"2024-01-01T00:00:00Z","fields":["terminationProtection","outputs"]},"stacks":[{"stackName":"a","terminationProtection":true}]}`,
+			expected: []string{"stackStatus", "roleArn", "notificationArns", "driftStatus", "parentId", "rootId", "capabilities", "parameters"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := ReadStacksOutput(strings.NewReader(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output.MissingFields)
+		})
+	}
+}
@@ -1,22 +1,295 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"time"
 )
 
-// Stack represents a CloudFormation stack with detection information
+// Stack represents a CloudFormation stack with detection information.
+// A zero CreatedAt or UpdatedAt means the time is unknown or, for UpdatedAt,
+// that the stack was never updated; both encode as null in JSON.
 type Stack struct {
-	StackName   string            `json:"stackName"`
-	StackID     string            `json:"stackId"`
-	Region      string            `json:"region"`
-	CreatedAt   time.Time         `json:"createdAt"`
-	UpdatedAt   time.Time         `json:"updatedAt"`
-	Description string            `json:"description"`
-	StackTags   map[string]string `json:"stackTags"`
-	Reasons     []string          `json:"reasons"`
+	StackName    string            `json:"stackName"`
+	StackID      string            `json:"stackId"`
+	Region       string            `json:"region"`
+	CreatedAt    time.Time         `json:"createdAt" nullable:"true"`
+	UpdatedAt    time.Time         `json:"updatedAt" nullable:"true"`
+	NeverUpdated bool              `json:"neverUpdated,omitempty" description:"The stack has not been updated since it was created"`
+	Description  string            `json:"description"`
+	StackTags    map[string]string `json:"stackTags"`
+	Reasons      []string          `json:"reasons"`
+
+	// Attribution of stacks scanned with --all-profiles or --profile-glob
+	AccountID string `json:"accountId,omitempty" description:"Account of the stack, in scans of several profiles"`
+	Profile   string `json:"profile,omitempty" description:"Profile the stack was scanned with, in scans of several profiles"`
+
+	// Optional details from DescribeStacks, written only when selected with --fields
+	StackStatus           string            `json:"stackStatus,omitempty"`
+	TerminationProtection *bool             `json:"terminationProtection,omitempty" description:"Whether termination protection is enabled; written as false too when selected"`
+	RoleARN               string            `json:"roleArn,omitempty"`
+	NotificationARNs      []string          `json:"notificationArns,omitempty"`
+	DriftStatus           string            `json:"driftStatus,omitempty"`
+	ParentID              string            `json:"parentId,omitempty" description:"Stack ID of the parent of a nested stack"`
+	RootID                string            `json:"rootId,omitempty" description:"Stack ID of the top-level stack of a nested stack"`
+	Capabilities          []string          `json:"capabilities,omitempty"`
+	Outputs               map[string]string `json:"outputs,omitempty" description:"Stack outputs by key, such as ServiceEndpoint and ServerlessDeploymentBucketName"`
+	Parameters            map[string]string `json:"parameters,omitempty" description:"Stack parameters by key; NoEcho parameters are left out"`
+}
+
+// OptionalFields lists the JSON names of the optional Stack fields, which are
+// left out of the output unless selected
+var OptionalFields = []string{
+	"stackStatus",
+	"terminationProtection",
+	"roleArn",
+	"notificationArns",
+	"driftStatus",
+	"parentId",
+	"rootId",
+	"capabilities",
+	"outputs",
+	"parameters",
+}
+
+// SelectFields returns a copy of the stack without the optional fields that
+// are not in keep. Core fields are always kept.
+func (s Stack) SelectFields(keep map[string]bool) Stack {
+	if !keep["stackStatus"] {
+		s.StackStatus = ""
+	}
+	if !keep["terminationProtection"] {
+		s.TerminationProtection = nil
+	}
+	if !keep["roleArn"] {
+		s.RoleARN = ""
+	}
+	if !keep["notificationArns"] {
+		s.NotificationARNs = nil
+	}
+	if !keep["driftStatus"] {
+		s.DriftStatus = ""
+	}
+	if !keep["parentId"] {
+		s.ParentID = ""
+	}
+	if !keep["rootId"] {
+		s.RootID = ""
+	}
+	if !keep["capabilities"] {
+		s.Capabilities = nil
+	}
+	if !keep["outputs"] {
+		s.Outputs = nil
+	}
+	if !keep["parameters"] {
+		s.Parameters = nil
+	}
+	return s
+}
+
+// stackJSON is the JSON encoding of Stack. It lists the fields in the order of
+// Stack, so the timestamps stay right after region.
+type stackJSON struct {
+	StackName    string            `json:"stackName"`
+	StackID      string            `json:"stackId"`
+	Region       string            `json:"region"`
+	CreatedAt    *time.Time        `json:"createdAt"`
+	UpdatedAt    *time.Time        `json:"updatedAt"`
+	NeverUpdated bool              `json:"neverUpdated,omitempty"`
+	Description  string            `json:"description"`
+	StackTags    map[string]string `json:"stackTags"`
+	Reasons      []string          `json:"reasons"`
+
+	AccountID string `json:"accountId,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+
+	StackStatus           string            `json:"stackStatus,omitempty"`
+	TerminationProtection *bool             `json:"terminationProtection,omitempty"`
+	RoleARN               string            `json:"roleArn,omitempty"`
+	NotificationARNs      []string          `json:"notificationArns,omitempty"`
+	DriftStatus           string            `json:"driftStatus,omitempty"`
+	ParentID              string            `json:"parentId,omitempty"`
+	RootID                string            `json:"rootId,omitempty"`
+	Capabilities          []string          `json:"capabilities,omitempty"`
+	Outputs               map[string]string `json:"outputs,omitempty"`
+	Parameters            map[string]string `json:"parameters,omitempty"`
+}
+
+// MarshalJSON encodes unknown timestamps as null instead of the zero time
+func (s Stack) MarshalJSON() ([]byte, error) {
+	return json.Marshal(stackJSON{
+		StackName:    s.StackName,
+		StackID:      s.StackID,
+		Region:       s.Region,
+		CreatedAt:    timeOrNil(s.CreatedAt),
+		UpdatedAt:    timeOrNil(s.UpdatedAt),
+		NeverUpdated: s.NeverUpdated,
+		Description:  s.Description,
+		StackTags:    s.StackTags,
+		Reasons:      s.Reasons,
+
+		AccountID: s.AccountID,
+		Profile:   s.Profile,
+
+		StackStatus:           s.StackStatus,
+		TerminationProtection: s.TerminationProtection,
+		RoleARN:               s.RoleARN,
+		NotificationARNs:      s.NotificationARNs,
+		DriftStatus:           s.DriftStatus,
+		ParentID:              s.ParentID,
+		RootID:                s.RootID,
+		Capabilities:          s.Capabilities,
+		Outputs:               s.Outputs,
+		Parameters:            s.Parameters,
+	})
+}
+
+// timeOrNil returns nil for the zero time
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Output schema versions. Version 1 fills unknown timestamps, reporting an
+// unknown creation time as the scan time and a never-updated stack as updated
+// when it was created. Version 2 reports them as null and adds neverUpdated.
+const (
+	DefaultSchemaVersion = 1
+	LatestSchemaVersion  = 2
+)
+
+// FillLegacyTimestamps returns copies of the stacks with timestamps filled
+// the way schema version 1 reports them. fallback replaces unknown creation times.
+func FillLegacyTimestamps(stacks []Stack, fallback time.Time) []Stack {
+	if stacks == nil {
+		return nil
+	}
+
+	filled := make([]Stack, len(stacks))
+	for i, stack := range stacks {
+		if stack.CreatedAt.IsZero() {
+			stack.CreatedAt = fallback
+		}
+		if stack.UpdatedAt.IsZero() {
+			stack.UpdatedAt = stack.CreatedAt
+		}
+		stack.NeverUpdated = false
+		filled[i] = stack
+	}
+	return filled
 }
 
 // StacksOutput represents the output structure for multiple stacks
 type StacksOutput struct {
-	Stacks []Stack `json:"stacks"`
+	// SchemaVersion is omitted in documents written before versioning was introduced
+	SchemaVersion int           `json:"schemaVersion,omitempty"`
+	Scan          *ScanMetadata `json:"scan,omitempty"`
+	Stacks        []Stack       `json:"stacks"`
+
+	// MissingFields lists the optional fields a loaded document does not
+	// contain, so they read as zero values rather than as their real values
+	MissingFields []string `json:"-"`
+}
+
+// ScanMetadata describes where, when and how a document was produced
+type ScanMetadata struct {
+	StartedAt     time.Time     `json:"startedAt"`
+	Duration      float64       `json:"duration" description:"Scan duration in seconds"`
+	AccountID     string        `json:"accountId,omitempty"`
+	CallerARN     string        `json:"callerArn,omitempty"`
+	Profile       string        `json:"profile,omitempty"`
+	RoleARN       string        `json:"roleArn,omitempty" description:"Role assumed for the scan, if any"`
+	Partition     string        `json:"partition,omitempty" description:"AWS partition of the scanned regions, such as aws, aws-cn or aws-us-gov"`
+	Accounts      []AccountScan `json:"accounts,omitempty" description:"Accounts scanned with --all-profiles or --profile-glob, one per distinct account"`
+	Regions       []string      `json:"regions"`
+	ToolVersion   string        `json:"toolVersion"`
+	RulesApplied  []string      `json:"rulesApplied"`
+	StacksScanned *int          `json:"stacksScanned,omitempty" description:"Active stacks listed in the scanned regions; absent in documents written before it was recorded"`
+	Errors        []ScanError   `json:"errors" description:"Failures that made the scan incomplete"`
+	Fields        []string      `json:"fields,omitempty" description:"Optional stack fields the document contains; absent in documents written before it was recorded"`
+}
+
+// AccountScan describes an account of a scan of several profiles
+type AccountScan struct {
+	AccountID string   `json:"accountId"`
+	CallerARN string   `json:"callerArn"`
+	Profile   string   `json:"profile" description:"Profile the account was scanned with"`
+	Profiles  []string `json:"profiles" description:"Every selected profile resolving to the account"`
+}
+
+// ScanError records a failure that did not abort the scan
+type ScanError struct {
+	Profile   string `json:"profile,omitempty"`
+	Region    string `json:"region,omitempty"`
+	StackName string `json:"stackName,omitempty"`
+	Operation string `json:"operation"`
+	Message   string `json:"message"`
+}
+
+// stageTagKeys are the tag keys checked for the deployment stage, in order
+var stageTagKeys = []string{"STAGE", "Stage", "stage"}
+
+// StageTag returns the value of the STAGE tag, or "" when the stack has none
+func (s Stack) StageTag() string {
+	for _, key := range stageTagKeys {
+		if stage := s.StackTags[key]; stage != "" {
+			return stage
+		}
+	}
+	return ""
+}
+
+// Stage returns the Serverless Framework stage of the stack. It prefers the
+// STAGE tag and otherwise uses the last hyphen-separated part of the stack
+// name, since Serverless Framework names stacks "<service>-<stage>".
+func (s Stack) Stage() string {
+	if stage := s.StageTag(); stage != "" {
+		return stage
+	}
+
+	if i := strings.LastIndex(s.StackName, "-"); i >= 0 && i < len(s.StackName)-1 {
+		return s.StackName[i+1:]
+	}
+	return ""
+}
+
+// LastDeployedAt returns when the stack was last deployed: its last update,
+// or its creation for stacks that were never updated. It is zero when unknown.
+func (s Stack) LastDeployedAt() time.Time {
+	if !s.UpdatedAt.IsZero() {
+		return s.UpdatedAt
+	}
+	return s.CreatedAt
+}
+
+// ownerTagKeys are the tag keys checked for the stack owner, in order
+var ownerTagKeys = []string{"Owner", "owner", "OWNER"}
+
+// Owner returns the value of the Owner tag, or "" when the stack has none
+func (s Stack) Owner() string {
+	for _, key := range ownerTagKeys {
+		if owner := s.StackTags[key]; owner != "" {
+			return owner
+		}
+	}
+	return ""
+}
+
+// frameworkVersionTagKeys are the tag keys checked for the Serverless Framework
+// version. The framework does not record its version in the stack, so it is
+// only known when set through provider.stackTags.
+var frameworkVersionTagKeys = []string{"FRAMEWORK_VERSION", "FrameworkVersion", "frameworkVersion"}
+
+// FrameworkVersion returns the Serverless Framework version from the stack
+// tags, or "" when the stack has none
+func (s Stack) FrameworkVersion() string {
+	for _, key := range frameworkVersionTagKeys {
+		if version := s.StackTags[key]; version != "" {
+			return version
+		}
+	}
+	return ""
 }
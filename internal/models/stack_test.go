@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -199,3 +200,204 @@ func TestStack_LargeDataset(t *testing.T) {
 	assert.Equal(t, len(stack.StackTags), len(unmarshaled.StackTags))
 	assert.Equal(t, len(stack.Reasons), len(unmarshaled.Reasons))
 }
+
+func TestStack_Stage(t *testing.T) {
+	tests := []struct {
+		name     string
+		stack    Stack
+		expected string
+	}{
+		{
+			name:     "STAGE tag",
+			stack:    Stack{StackName: "orders-api-prod", StackTags: map[string]string{"STAGE": "production"}},
+			expected: "production",
+		},
+		{
+			name:     "lowercase stage tag",
+			stack:    Stack{StackName: "orders-api-prod", StackTags: map[string]string{"stage": "qa"}},
+			expected: "qa",
+		},
+		{
+			name:     "stage from stack name",
+			stack:    Stack{StackName: "orders-api-dev"},
+			expected: "dev",
+		},
+		{
+			name:     "stack name without stage",
+			stack:    Stack{StackName: "orders"},
+			expected: "",
+		},
+		{
+			name:     "trailing hyphen",
+			stack:    Stack{StackName: "orders-"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.stack.Stage())
+		})
+	}
+}
+
+func TestStack_Owner(t *testing.T) {
+	assert.Equal(t, "team-a", Stack{StackTags: map[string]string{"Owner": "team-a", "owner": "team-b"}}.Owner())
+	assert.Equal(t, "team-b", Stack{StackTags: map[string]string{"owner": "team-b"}}.Owner())
+	assert.Equal(t, "", Stack{StackTags: map[string]string{"Team": "team-c"}}.Owner())
+	assert.Equal(t, "", Stack{}.Owner())
+}
+
+func TestStack_FrameworkVersion(t *testing.T) {
+	assert.Equal(t, "4.4.0", Stack{StackTags: map[string]string{"FRAMEWORK_VERSION": "4.4.0", "frameworkVersion": "3.38.0"}}.FrameworkVersion())
+	assert.Equal(t, "3.38.0", Stack{StackTags: map[string]string{"frameworkVersion": "3.38.0"}}.FrameworkVersion())
+	assert.Equal(t, "", Stack{}.FrameworkVersion())
+}
+
+func TestStack_LastDeployedAt(t *testing.T) {
+	createdAt := time.Date(2023, 10, 1, 12, 34, 56, 0, time.UTC)
+	updatedAt := time.Date(2023, 10, 2, 12, 34, 56, 0, time.UTC)
+
+	assert.Equal(t, updatedAt, Stack{CreatedAt: createdAt, UpdatedAt: updatedAt}.LastDeployedAt())
+	assert.Equal(t, createdAt, Stack{CreatedAt: createdAt, NeverUpdated: true}.LastDeployedAt())
+	assert.True(t, Stack{}.LastDeployedAt().IsZero())
+}
+
+func TestStack_JSONUnknownTimestamps(t *testing.T) {
+	stack := Stack{StackName: "my-api-dev", NeverUpdated: true}
+
+	jsonData, err := json.Marshal(stack)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonData, &fields))
+	assert.Nil(t, fields["createdAt"])
+	assert.Contains(t, fields, "createdAt")
+	assert.Nil(t, fields["updatedAt"])
+	assert.Contains(t, fields, "updatedAt")
+	assert.Equal(t, true, fields["neverUpdated"])
+
+	// null decodes back to the zero time
+	var decoded Stack
+	require.NoError(t, json.Unmarshal(jsonData, &decoded))
+	assert.True(t, decoded.CreatedAt.IsZero())
+	assert.True(t, decoded.UpdatedAt.IsZero())
+	assert.True(t, decoded.NeverUpdated)
+
+	// neverUpdated is omitted when false
+	jsonData, err = json.Marshal(Stack{StackName: "my-api-dev"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(jsonData), "neverUpdated")
+}
+
+func TestStack_JSONFieldOrder(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	stacks := FillLegacyTimestamps([]Stack{{
+		StackName:   "my-api-dev",
+		StackID:     "arn:aws:cloudformation:us-east-1:123456789012:stack/my-api-dev/1",
+		Region:      "us-east-1",
+		CreatedAt:   createdAt,
+		Description: "The AWS CloudFormation template for this Serverless application",
+		StackTags:   map[string]string{"STAGE": "dev"},
+		Reasons:     []string{"ServerlessDeploymentBucket"},
+	}}, createdAt)
+
+	// Version 1 documents keep the key order of the first releases
+	jsonData, err := json.Marshal(StacksOutput{Stacks: stacks})
+	require.NoError(t, err)
+	assert.Equal(t, `{"stacks":[{"stackName":"my-api-dev",`+
+		`"stackId":"arn:aws:cloudformation:us-east-1:123456789012:stack/my-api-dev/1",`+
+		`"region":"us-east-1","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z",`+
+		`"description":"The AWS CloudFormation template for this Serverless application",`+
+		`"stackTags":{"STAGE":"dev"},"reasons":["ServerlessDeploymentBucket"]}]}`, string(jsonData))
+
+	// Every field of Stack is encoded
+	protected := true
+	jsonData, err = json.Marshal(Stack{
+		NeverUpdated: true, AccountID: "1", Profile: "p", StackStatus: "s", TerminationProtection: &protected,
+		RoleARN: "r", NotificationARNs: []string{"n"}, DriftStatus: "d", ParentID: "p", RootID: "r",
+		Capabilities: []string{"c"}, Outputs: map[string]string{"o": "v"}, Parameters: map[string]string{"p": "v"},
+	})
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(jsonData, &fields))
+	assert.Len(t, fields, reflect.TypeOf(Stack{}).NumField())
+}
+
+func TestFillLegacyTimestamps(t *testing.T) {
+	createdAt := time.Date(2023, 10, 1, 12, 34, 56, 0, time.UTC)
+	updatedAt := time.Date(2023, 10, 2, 12, 34, 56, 0, time.UTC)
+	fallback := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stacks := []Stack{
+		{StackName: "updated", CreatedAt: createdAt, UpdatedAt: updatedAt},
+		{StackName: "never-updated", CreatedAt: createdAt, NeverUpdated: true},
+		{StackName: "unknown"},
+	}
+
+	filled := FillLegacyTimestamps(stacks, fallback)
+	require.Len(t, filled, 3)
+
+	assert.Equal(t, createdAt, filled[0].CreatedAt)
+	assert.Equal(t, updatedAt, filled[0].UpdatedAt)
+
+	assert.Equal(t, createdAt, filled[1].UpdatedAt)
+	assert.False(t, filled[1].NeverUpdated)
+
+	assert.Equal(t, fallback, filled[2].CreatedAt)
+	assert.Equal(t, fallback, filled[2].UpdatedAt)
+
+	// The input is left untouched
+	assert.True(t, stacks[1].UpdatedAt.IsZero())
+	assert.True(t, stacks[1].NeverUpdated)
+
+	assert.Nil(t, FillLegacyTimestamps(nil, fallback))
+}
+
+func TestStack_SelectFields(t *testing.T) {
+	protected := true
+	stack := Stack{
+		StackName:             "orders-api-dev",
+		StackStatus:           "UPDATE_COMPLETE",
+		TerminationProtection: &protected,
+		RoleARN:               "arn:aws:iam::123456789012:role/cfn-exec",
+		NotificationARNs:      []string{"arn:aws:sns:us-east-1:123456789012:cfn-events"},
+		DriftStatus:           "IN_SYNC",
+		ParentID:              "parent",
+		RootID:                "root",
+		Capabilities:          []string{"CAPABILITY_IAM"},
+		Outputs:               map[string]string{"ServiceEndpoint": "https://example.com"},
+		Parameters:            map[string]string{"Stage": "dev"},
+	}
+
+	fieldsOf := func(s Stack) map[string]interface{} {
+		data, err := json.Marshal(s)
+		require.NoError(t, err)
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		return fields
+	}
+
+	all := make(map[string]bool)
+	for _, name := range OptionalFields {
+		all[name] = true
+	}
+	kept := fieldsOf(stack.SelectFields(all))
+	for _, name := range OptionalFields {
+		assert.Contains(t, kept, name)
+	}
+
+	stripped := fieldsOf(stack.SelectFields(nil))
+	for _, name := range OptionalFields {
+		assert.NotContains(t, stripped, name)
+	}
+	assert.Equal(t, "orders-api-dev", stripped["stackName"])
+
+	partial := stack.SelectFields(map[string]bool{"outputs": true})
+	assert.Equal(t, map[string]string{"ServiceEndpoint": "https://example.com"}, partial.Outputs)
+	assert.Empty(t, partial.StackStatus)
+	assert.Nil(t, partial.Parameters)
+
+	// The original stack is left untouched
+	assert.Equal(t, "UPDATE_COMPLETE", stack.StackStatus)
+}
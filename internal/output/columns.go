@@ -0,0 +1,121 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// stackHeader lists the columns written by the tabular formatters
+var stackHeader = []string{
+	"StackName",
+	"StackID",
+	"Region",
+	"Description",
+	"CreatedAt",
+	"UpdatedAt",
+	"Tags",
+	"Reasons",
+}
+
+// stackRow renders a stack as the values of stackHeader, applying escape to text values
+func stackRow(stack models.Stack, escape func(string) string) []string {
+	return []string{
+		escape(stack.StackName),
+		escape(stack.StackID),
+		escape(stack.Region),
+		escape(stack.Description),
+		formatTime(stack.CreatedAt),
+		formatTime(stack.UpdatedAt),
+		formatTags(stack.StackTags, escape),
+		formatReasons(stack.Reasons, escape),
+	}
+}
+
+// formatTime formats time to RFC3339 string
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// formatTags formats tags map as key=value pairs separated by semicolons
+func formatTags(tags map[string]string, escape func(string) string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	var pairs []string
+
+	// Sort keys for consistent output
+	var keys []string
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := tags[key]
+		pair := fmt.Sprintf("%s=%s", escape(key), escape(value))
+		pairs = append(pairs, pair)
+	}
+
+	return strings.Join(pairs, ";")
+}
+
+// formatReasons formats reasons slice as semicolon-separated values
+func formatReasons(reasons []string, escape func(string) string) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	var escapedReasons []string
+	for _, reason := range reasons {
+		escapedReasons = append(escapedReasons, escape(reason))
+	}
+
+	return strings.Join(escapedReasons, ";")
+}
+
+// noEscape returns values unchanged, for formats that quote values themselves
+func noEscape(value string) string {
+	return value
+}
+
+// tabularRows renders the header and rows for the tabular formatters. With no
+// columns selected the default columns are used; otherwise each column is a
+// field path such as "stackName" or "stackTags.Owner".
+func tabularRows(stacks []models.Stack, columns []string, escape func(string) string) ([]string, [][]string, error) {
+	rows := make([][]string, 0, len(stacks))
+
+	if len(columns) == 0 {
+		for _, stack := range stacks {
+			rows = append(rows, stackRow(stack, escape))
+		}
+		return stackHeader, rows, nil
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = escape(column)
+	}
+
+	for _, stack := range stacks {
+		fields, err := stackFields(stack)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = formatFieldValue(lookupField(fields, column), escape)
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows, nil
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// CSVFormatter formats output as RFC 4180 Comma-Separated Values
+type CSVFormatter struct {
+	// Columns selects the fields to write; empty uses the default columns
+	Columns []string
+}
+
+// Format implements the Formatter interface for CSV output
+func (f *CSVFormatter) Format(stacks []models.Stack) (string, error) {
+	var result strings.Builder
+
+	header, rows, err := tabularRows(stacks, f.Columns, noEscape)
+	if err != nil {
+		return "", err
+	}
+
+	writer := csv.NewWriter(&result)
+	writer.UseCRLF = true // RFC 4180 line endings
+
+	if err := writer.Write(header); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	// Remove trailing line break, consistent with the other formatters
+	return strings.TrimSuffix(result.String(), "\r\n"), nil
+}
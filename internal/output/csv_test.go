@@ -0,0 +1,55 @@
+package output
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVFormatter_Format(t *testing.T) {
+	formatter := &CSVFormatter{}
+
+	stacks := []models.Stack{
+		{
+			StackName:   "test-stack-1",
+			StackID:     "arn:aws:cloudformation:us-east-1:123456789012:stack/test-stack-1/abc123",
+			Region:      "us-east-1",
+			Description: `Description with "quotes", commas and` + "\nnewlines",
+			CreatedAt:   time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2023, 1, 16, 11, 45, 0, 0, time.UTC),
+			StackTags: map[string]string{
+				"Service":     "serverless",
+				"Environment": "test",
+			},
+			Reasons: []string{"Contains resource with logical ID 'ServerlessDeploymentBucket'"},
+		},
+	}
+
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+
+	// Records are separated by CRLF as required by RFC 4180
+	assert.True(t, strings.HasPrefix(output, "StackName,StackID,Region,Description,CreatedAt,UpdatedAt,Tags,Reasons\r\n"))
+	assert.False(t, strings.HasSuffix(output, "\r\n"))
+
+	// Quoted fields round-trip through a standard CSV reader
+	records, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, stacks[0].Description, records[1][3])
+	assert.Equal(t, "2023-01-15T10:30:00Z", records[1][4])
+	assert.Equal(t, "Environment=test;Service=serverless", records[1][6])
+}
+
+func TestCSVFormatter_EmptyStacks(t *testing.T) {
+	formatter := &CSVFormatter{}
+
+	output, err := formatter.Format([]models.Stack{})
+	require.NoError(t, err)
+	assert.Equal(t, "StackName,StackID,Region,Description,CreatedAt,UpdatedAt,Tags,Reasons", output)
+}
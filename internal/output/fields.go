@@ -0,0 +1,157 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// stackFields converts a stack to its JSON object representation, so fields
+// are addressed by the same names as in the JSON output
+func stackFields(stack models.Stack) (map[string]interface{}, error) {
+	data, err := json.Marshal(stack)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stack: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode stack fields: %w", err)
+	}
+
+	return fields, nil
+}
+
+// lookupField resolves a dotted field path such as "stackTags.Owner".
+// Map keys that themselves contain dots are matched before the path is split.
+func lookupField(fields map[string]interface{}, path string) interface{} {
+	if value, exists := fields[path]; exists {
+		return value
+	}
+
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+
+	nested, ok := fields[head].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return lookupField(nested, rest)
+}
+
+// formatFieldValue renders a field value as text, applying escape to text values
+func formatFieldValue(value interface{}, escape func(string) string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return escape(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, len(keys))
+		for i, key := range keys {
+			pairs[i] = fmt.Sprintf("%s=%s", escape(key), formatFieldValue(v[key], escape))
+		}
+		return strings.Join(pairs, ";")
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatFieldValue(item, escape)
+		}
+		return strings.Join(items, ";")
+	default:
+		return escape(fmt.Sprint(v))
+	}
+}
+
+// stackFieldNames returns the top-level JSON field names of models.Stack
+func stackFieldNames() []string {
+	stackType := reflect.TypeOf(models.Stack{})
+
+	var names []string
+	for i := 0; i < stackType.NumField(); i++ {
+		name, _, _ := strings.Cut(stackType.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ValidateFieldPaths checks that every path starts with a known stack field
+func ValidateFieldPaths(paths []string) error {
+	known := stackFieldNames()
+
+	for _, path := range paths {
+		head, _, _ := strings.Cut(path, ".")
+		valid := false
+		for _, name := range known {
+			if head == name {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown field '%s' (available fields: %s)", path, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
+
+// ExpandOptionalFields validates the optional fields selected with --fields,
+// where "all" selects every optional field, and adds the optional fields that
+// paths refer to, so selected columns and sort keys are never empty
+func ExpandOptionalFields(fields []string, paths []string) ([]string, error) {
+	selected := make(map[string]bool)
+
+	for _, field := range fields {
+		if field == "all" {
+			for _, name := range models.OptionalFields {
+				selected[name] = true
+			}
+			continue
+		}
+		if !isOptionalField(field) {
+			return nil, fmt.Errorf("unknown field '%s' (optional fields: all, %s)", field, strings.Join(models.OptionalFields, ", "))
+		}
+		selected[field] = true
+	}
+
+	for _, path := range paths {
+		head, _, _ := strings.Cut(path, ".")
+		if isOptionalField(head) {
+			selected[head] = true
+		}
+	}
+
+	// Keep the documented order for stable output
+	var expanded []string
+	for _, name := range models.OptionalFields {
+		if selected[name] {
+			expanded = append(expanded, name)
+		}
+	}
+	return expanded, nil
+}
+
+// isOptionalField reports whether name is one of models.OptionalFields
+func isOptionalField(name string) bool {
+	for _, optional := range models.OptionalFields {
+		if name == optional {
+			return true
+		}
+	}
+	return false
+}
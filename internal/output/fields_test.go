@@ -0,0 +1,137 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupField(t *testing.T) {
+	fields, err := stackFields(models.Stack{
+		StackName: "orders-api-dev",
+		CreatedAt: time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+		StackTags: map[string]string{"Owner": "team-a", "aws:cloudformation:stack-name": "orders-api-dev", "app.version": "1.2"},
+		Reasons:   []string{"reason-1", "reason-2"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{path: "stackName", expected: "orders-api-dev"},
+		{path: "createdAt", expected: "2023-01-15T10:30:00Z"},
+		{path: "stackTags.Owner", expected: "team-a"},
+		{path: "stackTags.app.version", expected: "1.2"},
+		{path: "stackTags.Missing", expected: ""},
+		{path: "reasons", expected: "reason-1;reason-2"},
+		{path: "stackTags", expected: "Owner=team-a;app.version=1.2;aws:cloudformation:stack-name=orders-api-dev"},
+		{path: "stackName.nested", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatFieldValue(lookupField(fields, tt.path), noEscape))
+		})
+	}
+}
+
+func TestValidateFieldPaths(t *testing.T) {
+	assert.NoError(t, ValidateFieldPaths([]string{"stackName", "region", "stackTags.Owner"}))
+
+	err := ValidateFieldPaths([]string{"stackName", "owner"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown field 'owner'")
+	assert.Contains(t, err.Error(), "stackTags")
+}
+
+func TestExpandOptionalFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   []string
+		paths    []string
+		expected []string
+		wantErr  string
+	}{
+		{
+			name: "nothing selected",
+		},
+		{
+			name:     "explicit fields in documented order",
+			fields:   []string{"parameters", "stackStatus"},
+			expected: []string{"stackStatus", "parameters"},
+		},
+		{
+			name:     "all",
+			fields:   []string{"all"},
+			expected: models.OptionalFields,
+		},
+		{
+			name:     "implied by paths",
+			paths:    []string{"stackName", "outputs.ServiceEndpoint", "driftStatus"},
+			expected: []string{"driftStatus", "outputs"},
+		},
+		{
+			name:    "unknown field",
+			fields:  []string{"stackName"},
+			wantErr: "unknown field 'stackName'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expanded, err := ExpandOptionalFields(tt.fields, tt.paths)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, expanded)
+		})
+	}
+}
+
+func TestTabularFormatters_Columns(t *testing.T) {
+	stacks := []models.Stack{
+		{StackName: "orders-api-dev", Region: "us-east-1", StackTags: map[string]string{"Owner": "team|a"}},
+		{StackName: "billing-prod", Region: "us-west-2"},
+	}
+	columns := []string{"stackName", "region", "stackTags.Owner"}
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		expected  string
+	}{
+		{
+			name:      "tsv",
+			formatter: &TSVFormatter{Columns: columns},
+			expected:  "stackName\tregion\tstackTags.Owner\norders-api-dev\tus-east-1\tteam|a\nbilling-prod\tus-west-2\t",
+		},
+		{
+			name:      "csv",
+			formatter: &CSVFormatter{Columns: columns},
+			expected:  "stackName,region,stackTags.Owner\r\norders-api-dev,us-east-1,team|a\r\nbilling-prod,us-west-2,",
+		},
+		{
+			name:      "markdown",
+			formatter: &MarkdownFormatter{Columns: columns},
+			expected: "| stackName | region | stackTags.Owner |\n" +
+				"| --- | --- | --- |\n" +
+				"| orders-api-dev | us-east-1 | team\\|a |\n" +
+				"| billing-prod | us-west-2 |  |",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.formatter.Format(stacks)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
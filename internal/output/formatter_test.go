@@ -225,6 +225,26 @@ func TestFormatterFactory_Create(t *testing.T) {
 			format:     "tsv",
 			expectType: &TSVFormatter{},
 		},
+		{
+			name:       "create CSV formatter",
+			format:     "csv",
+			expectType: &CSVFormatter{},
+		},
+		{
+			name:       "create YAML formatter",
+			format:     "yaml",
+			expectType: &YAMLFormatter{},
+		},
+		{
+			name:       "create Markdown formatter",
+			format:     "markdown",
+			expectType: &MarkdownFormatter{},
+		},
+		{
+			name:       "create table formatter",
+			format:     "table",
+			expectType: &TableFormatter{},
+		},
 		{
 			name:        "invalid format",
 			format:      "xml",
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// MarkdownFormatter formats output as a GitHub-flavoured Markdown table
+type MarkdownFormatter struct {
+	// Columns selects the fields to write; empty uses the default columns
+	Columns []string
+}
+
+// Format implements the Formatter interface for Markdown output
+func (f *MarkdownFormatter) Format(stacks []models.Stack) (string, error) {
+	var result strings.Builder
+
+	header, rows, err := tabularRows(stacks, f.Columns, f.escapeValue)
+	if err != nil {
+		return "", err
+	}
+
+	writeMarkdownRow(&result, header)
+
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeMarkdownRow(&result, separator)
+
+	for _, row := range rows {
+		writeMarkdownRow(&result, row)
+	}
+
+	return strings.TrimSuffix(result.String(), "\n"), nil
+}
+
+// escapeValue escapes characters that would break a Markdown table cell
+func (f *MarkdownFormatter) escapeValue(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	value = strings.ReplaceAll(value, "\n", "<br>")
+	value = strings.ReplaceAll(value, "\r", "<br>")
+	return value
+}
+
+// writeMarkdownRow writes one table row
+func writeMarkdownRow(result *strings.Builder, cells []string) {
+	result.WriteString("| ")
+	result.WriteString(strings.Join(cells, " | "))
+	result.WriteString(" |\n")
+}
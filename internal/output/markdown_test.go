@@ -0,0 +1,48 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownFormatter_Format(t *testing.T) {
+	formatter := &MarkdownFormatter{}
+
+	stacks := []models.Stack{
+		{
+			StackName:   "test-stack-1",
+			StackID:     "arn:aws:cloudformation:us-east-1:123456789012:stack/test-stack-1/abc123",
+			Region:      "us-east-1",
+			Description: "Pipes | and\nnewlines",
+			CreatedAt:   time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2023, 1, 16, 11, 45, 0, 0, time.UTC),
+			StackTags:   map[string]string{"Owner": "team-a"},
+			Reasons:     []string{"Contains resource with logical ID 'ServerlessDeploymentBucket'"},
+		},
+	}
+
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "| StackName | StackID | Region | Description | CreatedAt | UpdatedAt | Tags | Reasons |", lines[0])
+	assert.Equal(t, "| --- | --- | --- | --- | --- | --- | --- | --- |", lines[1])
+	assert.Contains(t, lines[2], "| Pipes \\| and<br>newlines |")
+	assert.Contains(t, lines[2], "| Owner=team-a |")
+}
+
+func TestMarkdownFormatter_EmptyStacks(t *testing.T) {
+	formatter := &MarkdownFormatter{}
+
+	output, err := formatter.Format([]models.Stack{})
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	assert.Len(t, lines, 2)
+}
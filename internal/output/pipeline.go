@@ -0,0 +1,102 @@
+package output
+
+import (
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/query"
+)
+
+// Pipeline prepares stacks for a formatter: it sorts them, drops the optional
+// fields that were not selected, applies the query if one is set, and formats
+// the result. It implements Formatter, so every output format gets the same
+// ordering.
+type Pipeline struct {
+	Formatter Formatter
+	Order     SortOrder
+	Query     *query.Query
+	// Fields lists the optional stack fields to keep; see models.OptionalFields
+	Fields []string
+}
+
+// Format implements the Formatter interface
+func (p *Pipeline) Format(stacks []models.Stack) (string, error) {
+	return p.FormatDocument(models.StacksOutput{Stacks: stacks})
+}
+
+// FormatDocument implements the DocumentFormatter interface. The query sees
+// the whole document, so it can select scan metadata as well as stacks.
+// Documents older than schema version 2 get their timestamps filled the way
+// version 1 reported them, in every format.
+func (p *Pipeline) FormatDocument(doc models.StacksOutput) (string, error) {
+	if doc.SchemaVersion < 2 {
+		fallback := time.Now().UTC()
+		if doc.Scan != nil && !doc.Scan.StartedAt.IsZero() {
+			fallback = doc.Scan.StartedAt
+		}
+		doc.Stacks = models.FillLegacyTimestamps(doc.Stacks, fallback)
+	}
+
+	sorted, err := p.Order.Sort(doc.Stacks)
+	if err != nil {
+		return "", err
+	}
+	doc.Stacks = sorted
+
+	keep := make(map[string]bool, len(p.Fields))
+	for _, field := range p.Fields {
+		keep[field] = true
+	}
+	for i, stack := range doc.Stacks {
+		doc.Stacks[i] = stack.SelectFields(keep)
+	}
+
+	// Record the fields written, so that loading the document can tell a
+	// missing field from a zero value
+	if doc.Scan != nil {
+		scan := *doc.Scan
+		scan.Fields = writtenFields(keep, doc.MissingFields)
+		doc.Scan = &scan
+	}
+
+	if p.Query == nil {
+		if documentFormatter, ok := p.Formatter.(DocumentFormatter); ok {
+			return documentFormatter.FormatDocument(doc)
+		}
+		return p.Formatter.Format(doc.Stacks)
+	}
+
+	result, err := p.Query.Apply(doc)
+	if err != nil {
+		return "", err
+	}
+
+	// Formatters that render arbitrary values receive the query result as is;
+	// the others require the result to still be a list of stacks
+	if valueFormatter, ok := p.Formatter.(ValueFormatter); ok {
+		return valueFormatter.FormatValue(result)
+	}
+
+	filtered, err := query.Stacks(result)
+	if err != nil {
+		return "", err
+	}
+	return p.Formatter.Format(filtered)
+}
+
+// writtenFields returns the selected optional fields the stacks actually
+// contain, in the order of models.OptionalFields
+func writtenFields(keep map[string]bool, missing []string) []string {
+	absent := make(map[string]bool, len(missing))
+	for _, field := range missing {
+		absent[field] = true
+	}
+
+	fields := []string{}
+	for _, field := range models.OptionalFields {
+		if keep[field] && !absent[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
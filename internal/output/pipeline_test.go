@@ -0,0 +1,160 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pipelineTestStacks() []models.Stack {
+	return []models.Stack{
+		{StackName: "orders-prod", Region: "us-west-2", StackTags: map[string]string{"Environment": "prod"}},
+		{StackName: "orders-dev", Region: "us-east-1", StackTags: map[string]string{"Environment": "dev"}},
+	}
+}
+
+func TestPipeline_DefaultOrder(t *testing.T) {
+	pipeline := &Pipeline{Formatter: &TSVFormatter{Columns: []string{"stackName"}}}
+
+	output, err := pipeline.Format(pipelineTestStacks())
+	require.NoError(t, err)
+	assert.Equal(t, "stackName\norders-dev\norders-prod", output)
+}
+
+func TestPipeline_Query(t *testing.T) {
+	tests := []struct {
+		name       string
+		formatter  Formatter
+		expression string
+		expected   string
+	}{
+		{
+			name:       "projection with json",
+			formatter:  &JSONFormatter{},
+			expression: "stacks[?stackTags.Environment=='prod'].stackName",
+			expected:   `["orders-prod"]`,
+		},
+		{
+			name:       "projection with yaml",
+			formatter:  &YAMLFormatter{},
+			expression: "stacks[].stackName",
+			expected:   "- orders-dev\n- orders-prod",
+		},
+		{
+			name:       "filter with tabular output",
+			formatter:  &TSVFormatter{Columns: []string{"stackName"}},
+			expression: "stacks[?stackTags.Environment=='dev']",
+			expected:   "stackName\norders-dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Compile(tt.expression)
+			require.NoError(t, err)
+
+			output, err := (&Pipeline{Formatter: tt.formatter, Query: q}).Format(pipelineTestStacks())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestPipeline_ProjectionWithTabularOutput(t *testing.T) {
+	q, err := query.Compile("stacks[].stackName")
+	require.NoError(t, err)
+
+	_, err = (&Pipeline{Formatter: &TSVFormatter{}, Query: q}).Format(pipelineTestStacks())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a list of stacks")
+}
+
+func TestPipeline_SchemaVersionTimestamps(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2023, 10, 1, 12, 34, 56, 0, time.UTC)
+	doc := models.StacksOutput{
+		Scan: &models.ScanMetadata{StartedAt: startedAt},
+		Stacks: []models.Stack{
+			{StackName: "never-updated", CreatedAt: createdAt, NeverUpdated: true},
+			{StackName: "unknown", NeverUpdated: true},
+		},
+	}
+	tsv := &TSVFormatter{Columns: []string{"stackName", "createdAt", "updatedAt", "neverUpdated"}}
+
+	t.Run("version 1 fills timestamps", func(t *testing.T) {
+		doc.SchemaVersion = 1
+
+		output, err := (&Pipeline{Formatter: tsv}).FormatDocument(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "stackName\tcreatedAt\tupdatedAt\tneverUpdated\n"+
+			"never-updated\t2023-10-01T12:34:56Z\t2023-10-01T12:34:56Z\t\n"+
+			"unknown\t2024-01-01T00:00:00Z\t2024-01-01T00:00:00Z\t", output)
+
+		output, err = (&Pipeline{Formatter: &JSONFormatter{}}).FormatDocument(doc)
+		require.NoError(t, err)
+		assert.NotContains(t, output, `"createdAt":null`)
+		assert.NotContains(t, output, `"updatedAt":null`)
+		assert.NotContains(t, output, "neverUpdated")
+	})
+
+	t.Run("version 2 reports unknown timestamps", func(t *testing.T) {
+		doc.SchemaVersion = 2
+
+		output, err := (&Pipeline{Formatter: tsv}).FormatDocument(doc)
+		require.NoError(t, err)
+		assert.Equal(t, "stackName\tcreatedAt\tupdatedAt\tneverUpdated\n"+
+			"never-updated\t2023-10-01T12:34:56Z\t\ttrue\n"+
+			"unknown\t\t\ttrue", output)
+
+		output, err = (&Pipeline{Formatter: &JSONFormatter{}}).FormatDocument(doc)
+		require.NoError(t, err)
+		assert.Contains(t, output, `"createdAt":null,"updatedAt":null`)
+		assert.Contains(t, output, `"neverUpdated":true`)
+	})
+}
+
+func TestPipeline_OptionalFields(t *testing.T) {
+	stacks := []models.Stack{{
+		StackName:   "orders-dev",
+		Region:      "us-east-1",
+		StackStatus: "CREATE_COMPLETE",
+		Outputs:     map[string]string{"ServiceEndpoint": "https://example.com"},
+	}}
+
+	pipeline := &Pipeline{Formatter: &JSONFormatter{}}
+	output, err := pipeline.Format(stacks)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "stackStatus")
+	assert.NotContains(t, output, "ServiceEndpoint")
+
+	pipeline.Fields = []string{"outputs"}
+	output, err = pipeline.Format(stacks)
+	require.NoError(t, err)
+	assert.NotContains(t, output, "stackStatus")
+	assert.Contains(t, output, `"outputs":{"ServiceEndpoint":"https://example.com"}`)
+
+	// The caller's stacks are left untouched
+	assert.Equal(t, "CREATE_COMPLETE", stacks[0].StackStatus)
+}
+
+func TestPipeline_RecordsFields(t *testing.T) {
+	doc := models.StacksOutput{
+		SchemaVersion: 2,
+		Scan:          &models.ScanMetadata{StartedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		Stacks:        pipelineTestStacks(),
+		// Loaded from a document written without terminationProtection
+		MissingFields: []string{"stackStatus", "terminationProtection"},
+	}
+
+	pipeline := &Pipeline{Formatter: &JSONFormatter{}, Fields: []string{"terminationProtection", "outputs"}}
+	output, err := pipeline.FormatDocument(doc)
+	require.NoError(t, err)
+	assert.Contains(t, output, `"fields":["outputs"]`)
+
+	// The caller's metadata is left untouched
+	assert.Nil(t, doc.Scan.Fields)
+}
@@ -0,0 +1,66 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options holds settings needed by formatters that are configured by the user
+type Options struct {
+	// Template is the Go template text used by the template format
+	Template string
+	// Columns selects the fields written by the tabular formats (tsv, csv, markdown, table)
+	Columns []string
+}
+
+// formatEntry describes a supported output format
+type formatEntry struct {
+	name         string
+	newFormatter func(opts Options) (Formatter, error)
+}
+
+// registry lists every supported output format in display order.
+// Adding a format here makes it available to both validation and FormatterFactory.
+var registry = []formatEntry{
+	{name: "json", newFormatter: func(Options) (Formatter, error) { return &JSONFormatter{}, nil }},
+	{name: "tsv", newFormatter: func(opts Options) (Formatter, error) { return &TSVFormatter{Columns: opts.Columns}, nil }},
+	{name: "csv", newFormatter: func(opts Options) (Formatter, error) { return &CSVFormatter{Columns: opts.Columns}, nil }},
+	{name: "yaml", newFormatter: func(Options) (Formatter, error) { return &YAMLFormatter{}, nil }},
+	{name: "markdown", newFormatter: func(opts Options) (Formatter, error) { return &MarkdownFormatter{Columns: opts.Columns}, nil }},
+	{name: "table", newFormatter: func(opts Options) (Formatter, error) {
+		formatter := NewTableFormatter()
+		formatter.Columns = opts.Columns
+		return formatter, nil
+	}},
+	{name: "template", newFormatter: func(opts Options) (Formatter, error) { return NewTemplateFormatter(opts.Template) }},
+}
+
+// SupportedFormats returns the names of all supported output formats
+func SupportedFormats() []string {
+	names := make([]string, len(registry))
+	for i, entry := range registry {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// IsSupportedFormat reports whether the output format is supported
+func IsSupportedFormat(format string) bool {
+	for _, entry := range registry {
+		if entry.name == format {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFormatter creates a formatter for the format, configured with opts
+func NewFormatter(format string, opts Options) (Formatter, error) {
+	for _, entry := range registry {
+		if entry.name == format {
+			return entry.newFormatter(opts)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported output format: %s (supported formats: %s)", format, strings.Join(SupportedFormats(), ", "))
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportedFormats(t *testing.T) {
+	assert.Equal(t, []string{"json", "tsv", "csv", "yaml", "markdown", "table", "template"}, SupportedFormats())
+}
+
+func TestIsSupportedFormat(t *testing.T) {
+	for _, format := range SupportedFormats() {
+		assert.True(t, IsSupportedFormat(format), format)
+	}
+
+	assert.False(t, IsSupportedFormat("xml"))
+	assert.False(t, IsSupportedFormat("JSON"))
+	assert.False(t, IsSupportedFormat(""))
+}
+
+func TestRegistry_EveryFormatHasFormatter(t *testing.T) {
+	for _, format := range SupportedFormats() {
+		formatter, err := NewFormatter(format, Options{Template: "{{len .Stacks}}"})
+		require.NoError(t, err, format)
+
+		_, err = formatter.Format(nil)
+		assert.NoError(t, err, format)
+	}
+}
+
+func TestFormatterFactory_ErrorListsFormats(t *testing.T) {
+	_, err := FormatterFactory("xml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "json, tsv, csv, yaml, markdown, table, template")
+}
+
+func TestNewFormatter_TemplateRequiresText(t *testing.T) {
+	_, err := NewFormatter("template", Options{})
+	assert.Error(t, err)
+
+	formatter, err := NewFormatter("template", Options{Template: "{{len .Stacks}}"})
+	require.NoError(t, err)
+	assert.IsType(t, &TemplateFormatter{}, formatter)
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ReportFormatter formats the report of a subcommand, such as a diff or a policy report
+type ReportFormatter[T any] interface {
+	Format(report T) (string, error)
+}
+
+// JSON formats any report as compact JSON
+type JSON[T any] struct{}
+
+// Format implements the ReportFormatter interface for JSON output
+func (JSON[T]) Format(report T) (string, error) {
+	jsonData, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	return string(jsonData), nil
+}
+
+// ReportFormat names a report format and creates its formatter
+type ReportFormat[T any] struct {
+	Name string
+	New  func() ReportFormatter[T]
+}
+
+// ReportFormats lists the formats of a report in display order
+type ReportFormats[T any] []ReportFormat[T]
+
+// Names returns the names of the formats
+func (f ReportFormats[T]) Names() []string {
+	names := make([]string, len(f))
+	for i, format := range f {
+		names[i] = format.Name
+	}
+	return names
+}
+
+// New creates the formatter of the named format; kind describes the report in errors
+func (f ReportFormats[T]) New(kind, name string) (ReportFormatter[T], error) {
+	for _, format := range f {
+		if format.Name == name {
+			return format.New(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported %s format: %s (supported formats: %s)", kind, name, strings.Join(f.Names(), ", "))
+}
@@ -0,0 +1,53 @@
+package output
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testReport struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+type testTextFormatter struct{}
+
+func (testTextFormatter) Format(report *testReport) (string, error) {
+	return report.Name, nil
+}
+
+var testReportFormats = ReportFormats[*testReport]{
+	{Name: "text", New: func() ReportFormatter[*testReport] { return testTextFormatter{} }},
+	{Name: "json", New: func() ReportFormatter[*testReport] { return JSON[*testReport]{} }},
+}
+
+func TestJSON_Format(t *testing.T) {
+	result, err := JSON[*testReport]{}.Format(&testReport{Name: "orders", Score: 1.5})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"orders","score":1.5}`, result)
+
+	_, err = JSON[*testReport]{}.Format(&testReport{Score: math.Inf(1)})
+	assert.ErrorContains(t, err, "failed to marshal JSON")
+}
+
+func TestReportFormats_New(t *testing.T) {
+	assert.Equal(t, []string{"text", "json"}, testReportFormats.Names())
+
+	for _, name := range testReportFormats.Names() {
+		formatter, err := testReportFormats.New("test report", name)
+		require.NoError(t, err, name)
+
+		_, err = formatter.Format(&testReport{Name: "orders"})
+		assert.NoError(t, err, name)
+	}
+
+	formatter, err := testReportFormats.New("test report", "json")
+	require.NoError(t, err)
+	assert.IsType(t, JSON[*testReport]{}, formatter)
+
+	_, err = testReportFormats.New("test report", "xml")
+	assert.EqualError(t, err, "unsupported test report format: xml (supported formats: text, json)")
+}
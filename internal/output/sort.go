@@ -0,0 +1,111 @@
+package output
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// SortOrder defines the order of stacks in the output. The default order is
+// region, then stack name; By sorts on a field path first, such as
+// "createdAt" or "stackTags.Owner", and uses the default order for ties.
+type SortOrder struct {
+	By      string
+	Reverse bool
+}
+
+// defaultSortFields break ties so the order never depends on scan concurrency
+var defaultSortFields = []string{"region", "stackName", "stackId"}
+
+// Validate checks that the sort field is a known stack field
+func (o SortOrder) Validate() error {
+	if o.By == "" {
+		return nil
+	}
+	return ValidateFieldPaths([]string{o.By})
+}
+
+// Sort returns a sorted copy of the stacks
+func (o SortOrder) Sort(stacks []models.Stack) ([]models.Stack, error) {
+	fields := defaultSortFields
+	if o.By != "" {
+		fields = append([]string{o.By}, defaultSortFields...)
+	}
+
+	type sortItem struct {
+		stack models.Stack
+		keys  []interface{}
+	}
+
+	items := make([]sortItem, len(stacks))
+	for i, stack := range stacks {
+		values, err := stackFields(stack)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]interface{}, len(fields))
+		for j, field := range fields {
+			keys[j] = lookupField(values, field)
+		}
+		items[i] = sortItem{stack: stack, keys: keys}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		for k := range fields {
+			if c := compareFieldValues(items[i].keys[k], items[j].keys[k], o.Reverse); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	sorted := make([]models.Stack, len(items))
+	for i, item := range items {
+		sorted[i] = item.stack
+	}
+	return sorted, nil
+}
+
+// compareFieldValues orders two field values. Missing and empty values sort
+// last, also when reversed, timestamps and numbers compare by value, and
+// everything else compares by its text form.
+func compareFieldValues(a, b interface{}, reverse bool) int {
+	aText := formatFieldValue(a, noEscape)
+	bText := formatFieldValue(b, noEscape)
+
+	switch {
+	case aText == "" && bText == "":
+		return 0
+	case aText == "":
+		return 1
+	case bText == "":
+		return -1
+	}
+
+	c := comparePresentValues(a, b, aText, bText)
+	if reverse {
+		return -c
+	}
+	return c
+}
+
+// comparePresentValues orders two non-empty field values given their text forms
+func comparePresentValues(a, b interface{}, aText, bText string) int {
+	if aNumber, ok := a.(float64); ok {
+		if bNumber, ok := b.(float64); ok {
+			return cmp.Compare(aNumber, bNumber)
+		}
+	}
+
+	if aTime, err := time.Parse(time.RFC3339Nano, aText); err == nil {
+		if bTime, err := time.Parse(time.RFC3339Nano, bText); err == nil {
+			return aTime.Compare(bTime)
+		}
+	}
+
+	return strings.Compare(aText, bText)
+}
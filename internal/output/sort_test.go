@@ -0,0 +1,102 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sortTestStacks() []models.Stack {
+	return []models.Stack{
+		{
+			StackName: "orders-prod",
+			StackID:   "id-3",
+			Region:    "us-west-2",
+			CreatedAt: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+			StackTags: map[string]string{"Owner": "team-b"},
+		},
+		{
+			StackName: "orders-dev",
+			StackID:   "id-2",
+			Region:    "us-east-1",
+			// An earlier instant than orders-prod whose text form sorts later
+			CreatedAt: time.Date(2023, 5, 1, 8, 30, 0, 0, time.FixedZone("JST", 9*60*60)),
+		},
+		{
+			StackName: "billing-prod",
+			StackID:   "id-1",
+			Region:    "us-east-1",
+			CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			StackTags: map[string]string{"Owner": "team-a"},
+		},
+	}
+}
+
+func stackNames(stacks []models.Stack) []string {
+	names := make([]string, len(stacks))
+	for i, stack := range stacks {
+		names[i] = stack.StackName
+	}
+	return names
+}
+
+func TestSortOrder_Sort(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    SortOrder
+		expected []string
+	}{
+		{
+			name:     "default order is region then name",
+			order:    SortOrder{},
+			expected: []string{"billing-prod", "orders-dev", "orders-prod"},
+		},
+		{
+			name:     "reverse default order",
+			order:    SortOrder{Reverse: true},
+			expected: []string{"orders-prod", "orders-dev", "billing-prod"},
+		},
+		{
+			name:     "by timestamp compares instants",
+			order:    SortOrder{By: "createdAt"},
+			expected: []string{"billing-prod", "orders-dev", "orders-prod"},
+		},
+		{
+			name:     "by tag puts missing values last",
+			order:    SortOrder{By: "stackTags.Owner"},
+			expected: []string{"billing-prod", "orders-prod", "orders-dev"},
+		},
+		{
+			name:     "by tag reversed keeps missing values last",
+			order:    SortOrder{By: "stackTags.Owner", Reverse: true},
+			expected: []string{"orders-prod", "billing-prod", "orders-dev"},
+		},
+		{
+			name:     "by stack ID",
+			order:    SortOrder{By: "stackId"},
+			expected: []string{"billing-prod", "orders-dev", "orders-prod"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stacks := sortTestStacks()
+
+			sorted, err := tt.order.Sort(stacks)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stackNames(sorted))
+
+			// The input is left untouched
+			assert.Equal(t, []string{"orders-prod", "orders-dev", "billing-prod"}, stackNames(stacks))
+		})
+	}
+}
+
+func TestSortOrder_Validate(t *testing.T) {
+	assert.NoError(t, SortOrder{}.Validate())
+	assert.NoError(t, SortOrder{By: "stackTags.Owner"}.Validate())
+	assert.Error(t, SortOrder{By: "owner"}.Validate())
+}
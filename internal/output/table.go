@@ -0,0 +1,273 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+const (
+	ansiBold  = "\x1b[1m"
+	ansiDim   = "\x1b[2m"
+	ansiReset = "\x1b[0m"
+
+	// tableColumnGap is the number of spaces between columns
+	tableColumnGap = 2
+
+	// minTruncatedWidth is the narrowest a truncatable column is shrunk to
+	minTruncatedWidth = 12
+
+	// tableTimeFormat is a compact timestamp layout for terminals
+	tableTimeFormat = "2006-01-02 15:04"
+)
+
+// displayWidth measures terminal cells: CJK characters and emoji take two.
+// Ambiguous-width characters count as one whatever the locale, so the layout is stable.
+var displayWidth = &runewidth.Condition{EastAsianWidth: false}
+
+// tableColumn describes a column of the table output
+type tableColumn struct {
+	header string
+	value  func(stack models.Stack) string
+	// truncatable columns are shrunk when the table is wider than the terminal
+	truncatable bool
+}
+
+// tableColumns lists the table columns; truncatable columns shrink from last to first
+var tableColumns = []tableColumn{
+	{header: "STACK NAME", value: func(s models.Stack) string { return s.StackName }},
+	{header: "REGION", value: func(s models.Stack) string { return s.Region }},
+	{header: "STAGE", value: func(s models.Stack) string { return s.Stage() }},
+	{header: "CREATED", value: func(s models.Stack) string { return formatTableTime(s.CreatedAt) }},
+	{header: "UPDATED", value: func(s models.Stack) string { return formatTableTime(s.UpdatedAt) }},
+	{header: "STACK ID", value: func(s models.Stack) string { return s.StackID }, truncatable: true},
+	{header: "DESCRIPTION", value: func(s models.Stack) string { return s.Description }, truncatable: true},
+}
+
+// truncatableFields are the field paths shrunk first when columns are selected
+var truncatableFields = map[string]bool{
+	"stackId":     true,
+	"description": true,
+}
+
+// TableFormatter formats output as an aligned table for terminals
+type TableFormatter struct {
+	// Width is the maximum line width; zero disables truncation
+	Width int
+	// Color enables ANSI styling of the header and footer
+	Color bool
+	// Columns selects the fields to show; empty uses the default columns
+	Columns []string
+}
+
+// NewTableFormatter creates a table formatter configured for the current stdout.
+// Colour is used only on a terminal and when NO_COLOR is not set; the width is
+// taken from the terminal, or from COLUMNS when output is piped.
+func NewTableFormatter() *TableFormatter {
+	fd := int(os.Stdout.Fd())
+	isTerminal := term.IsTerminal(fd)
+
+	formatter := &TableFormatter{
+		Color: isTerminal && os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+	}
+
+	if isTerminal {
+		if width, _, err := term.GetSize(fd); err == nil {
+			formatter.Width = width
+		}
+	}
+	if formatter.Width == 0 {
+		if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+			formatter.Width = columns
+		}
+	}
+
+	return formatter
+}
+
+// Format implements the Formatter interface for table output
+func (f *TableFormatter) Format(stacks []models.Stack) (string, error) {
+	if len(stacks) == 0 {
+		return "No Serverless Framework stacks found.", nil
+	}
+
+	headers, rows, truncatable, err := f.tableRows(stacks)
+	if err != nil {
+		return "", err
+	}
+
+	widths := f.columnWidths(headers, rows, truncatable)
+
+	var result strings.Builder
+
+	result.WriteString(f.style(ansiBold, formatTableRow(headers, widths)))
+	result.WriteString("\n")
+
+	for _, row := range rows {
+		result.WriteString(formatTableRow(row, widths))
+		result.WriteString("\n")
+	}
+
+	result.WriteString("\n")
+	result.WriteString(f.style(ansiDim, tableSummary(stacks)))
+
+	return result.String(), nil
+}
+
+// tableRows renders the headers and cells, and marks which columns may be truncated
+func (f *TableFormatter) tableRows(stacks []models.Stack) ([]string, [][]string, []bool, error) {
+	if len(f.Columns) > 0 {
+		header, rows, err := tabularRows(stacks, f.Columns, sanitizeCell)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		truncatable := make([]bool, len(f.Columns))
+		for i, column := range f.Columns {
+			header[i] = strings.ToUpper(header[i])
+			truncatable[i] = truncatableFields[column]
+		}
+		return header, rows, truncatable, nil
+	}
+
+	headers := make([]string, len(tableColumns))
+	truncatable := make([]bool, len(tableColumns))
+	for i, column := range tableColumns {
+		headers[i] = column.header
+		truncatable[i] = column.truncatable
+	}
+
+	rows := make([][]string, len(stacks))
+	for i, stack := range stacks {
+		row := make([]string, len(tableColumns))
+		for j, column := range tableColumns {
+			row[j] = sanitizeCell(column.value(stack))
+		}
+		rows[i] = row
+	}
+
+	return headers, rows, truncatable, nil
+}
+
+// columnWidths computes column widths, shrinking truncatable columns to fit Width
+func (f *TableFormatter) columnWidths(headers []string, rows [][]string, truncatable []bool) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth.StringWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if w := displayWidth.StringWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	if f.Width <= 0 {
+		return widths
+	}
+
+	total := tableColumnGap * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	for i := len(widths) - 1; i >= 0 && total > f.Width; i-- {
+		if !truncatable[i] || widths[i] <= minTruncatedWidth {
+			continue
+		}
+		shrink := total - f.Width
+		if widths[i]-shrink < minTruncatedWidth {
+			shrink = widths[i] - minTruncatedWidth
+		}
+		widths[i] -= shrink
+		total -= shrink
+	}
+
+	return widths
+}
+
+// style wraps text in an ANSI style when colour is enabled
+func (f *TableFormatter) style(code, text string) string {
+	if !f.Color {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// formatTableRow pads and truncates cells to the column widths
+func formatTableRow(cells []string, widths []int) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = truncate(cell, widths[i])
+		if i < len(cells)-1 {
+			cell += strings.Repeat(" ", widths[i]-displayWidth.StringWidth(cell))
+		}
+		parts[i] = cell
+	}
+	return strings.TrimRight(strings.Join(parts, strings.Repeat(" ", tableColumnGap)), " ")
+}
+
+// truncate shortens a value to width terminal cells, marking the cut with an ellipsis
+func truncate(value string, width int) string {
+	if width <= 1 {
+		return displayWidth.Truncate(value, width, "")
+	}
+	return displayWidth.Truncate(value, width, "…")
+}
+
+// sanitizeCell keeps a value on a single line
+func sanitizeCell(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// formatTableTime renders a compact timestamp, or "-" when it is unknown
+func formatTableTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(tableTimeFormat)
+}
+
+// tableSummary renders the footer with the number of stacks per region and stage
+func tableSummary(stacks []models.Stack) string {
+	byRegion := make(map[string]int)
+	byStage := make(map[string]int)
+	for _, stack := range stacks {
+		byRegion[stack.Region]++
+		stage := stack.Stage()
+		if stage == "" {
+			stage = "(none)"
+		}
+		byStage[stage]++
+	}
+
+	noun := "stacks"
+	if len(stacks) == 1 {
+		noun = "stack"
+	}
+
+	return fmt.Sprintf("%d %s\nBy region: %s\nBy stage: %s", len(stacks), noun, formatCounts(byRegion), formatCounts(byStage))
+}
+
+// formatCounts renders counts as "key=count" pairs sorted by key
+func formatCounts(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%d", key, counts[key])
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,148 @@
+package output
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/mattn/go-runewidth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tableTestStacks() []models.Stack {
+	return []models.Stack{
+		{
+			StackName:   "orders-api-dev",
+			StackID:     "arn:aws:cloudformation:us-east-1:123456789012:stack/orders-api-dev/0f1e2d3c-4b5a-6978-8796-a5b4c3d2e1f0",
+			Region:      "us-east-1",
+			Description: "The orders API deployed by Serverless Framework with a rather long description",
+			CreatedAt:   time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2023, 1, 16, 11, 45, 0, 0, time.UTC),
+		},
+		{
+			StackName: "billing-prod",
+			StackID:   "arn:aws:cloudformation:us-west-2:123456789012:stack/billing-prod/abc123",
+			Region:    "us-west-2",
+			StackTags: map[string]string{"STAGE": "production"},
+		},
+	}
+}
+
+func TestTableFormatter_Format(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	output, err := formatter.Format(tableTestStacks())
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	require.Len(t, lines, 7)
+
+	// Columns are aligned: every row starts the REGION column at the same offset
+	regionOffset := strings.Index(lines[0], "REGION")
+	assert.Equal(t, "us-east-1", lines[1][regionOffset:regionOffset+len("us-east-1")])
+	assert.Equal(t, "us-west-2", lines[2][regionOffset:regionOffset+len("us-west-2")])
+
+	// Unknown timestamps are shown as "-"
+	assert.Contains(t, lines[2], "-  ")
+	assert.Contains(t, lines[1], "2023-01-16 11:45")
+
+	// Without a width limit, nothing is truncated
+	assert.Contains(t, output, tableTestStacks()[0].StackID)
+	assert.NotContains(t, output, "…")
+
+	// Summary footer
+	assert.Equal(t, "", lines[3])
+	assert.Equal(t, "2 stacks", lines[4])
+	assert.Equal(t, "By region: us-east-1=1, us-west-2=1", lines[5])
+	assert.Equal(t, "By stage: dev=1, production=1", lines[6])
+
+	// No ANSI codes when colour is disabled
+	assert.NotContains(t, output, "\x1b[")
+}
+
+func TestTableFormatter_Truncation(t *testing.T) {
+	formatter := &TableFormatter{Width: 110}
+
+	output, err := formatter.Format(tableTestStacks())
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines[:3] {
+		assert.LessOrEqual(t, runewidth.StringWidth(line), 110, line)
+	}
+	assert.Contains(t, output, "…")
+
+	// Non-truncatable columns are kept intact
+	assert.Contains(t, lines[1], "orders-api-dev")
+	assert.Contains(t, lines[1], "2023-01-15 10:30")
+}
+
+func TestTableFormatter_Color(t *testing.T) {
+	formatter := &TableFormatter{Color: true}
+
+	output, err := formatter.Format(tableTestStacks())
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(output, ansiBold+"STACK NAME"))
+	assert.Contains(t, output, ansiReset)
+}
+
+func TestTableFormatter_EmptyStacks(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	output, err := formatter.Format(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "No Serverless Framework stacks found.", output)
+}
+
+func TestNewTableFormatter_NotATerminal(t *testing.T) {
+	// Tests run with stdout redirected, so colour must be off
+	t.Setenv("COLUMNS", "80")
+
+	formatter := NewTableFormatter()
+	assert.False(t, formatter.Color)
+	assert.Equal(t, 80, formatter.Width)
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcdefghi…", truncate("abcdefghijklmnop", 10))
+	assert.Equal(t, "ü", truncate("üü", 1))
+
+	// Wide characters take two cells
+	assert.Equal(t, "注文…", truncate("注文サービス", 6))
+	assert.Equal(t, "注文サービス", truncate("注文サービス", 12))
+	assert.Equal(t, "🚀 de…", truncate("🚀 deploy", 6))
+}
+
+func TestTableFormatter_WideCharacters(t *testing.T) {
+	stacks := tableTestStacks()
+	for i := range stacks {
+		stacks[i].Description = "注文処理サービスのバックエンド API 🚀 本番環境と開発環境で共通のテンプレート"
+	}
+	formatter := &TableFormatter{Width: 110}
+
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	for _, line := range lines[:3] {
+		assert.LessOrEqual(t, runewidth.StringWidth(line), 110, line)
+	}
+	assert.Contains(t, lines[1], "…")
+}
+
+func TestTableFormatter_Columns(t *testing.T) {
+	formatter := &TableFormatter{Columns: []string{"stackName", "stackTags.STAGE"}}
+
+	output, err := formatter.Format(tableTestStacks())
+	require.NoError(t, err)
+
+	lines := strings.Split(output, "\n")
+	assert.Equal(t, "STACKNAME       STACKTAGS.STAGE", lines[0])
+	assert.Equal(t, "orders-api-dev", strings.TrimRight(lines[1], " "))
+	assert.Equal(t, "billing-prod    production", lines[2])
+	assert.Equal(t, "2 stacks", lines[4])
+}
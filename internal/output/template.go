@@ -0,0 +1,80 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// TemplateFormatter renders output with a user-defined Go template.
+// The template is executed over models.StacksOutput.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// NewTemplateFormatter parses the template text so errors are reported before any scan
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("template output requires a template (use --template or --template-file)")
+	}
+
+	tmpl, err := template.New("output").Option("missingkey=zero").Funcs(templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+// Format implements the Formatter interface for template output
+func (f *TemplateFormatter) Format(stacks []models.Stack) (string, error) {
+	return f.FormatDocument(models.StacksOutput{Stacks: stacks})
+}
+
+// FormatDocument implements the DocumentFormatter interface, exposing the scan metadata as .Scan
+func (f *TemplateFormatter) FormatDocument(doc models.StacksOutput) (string, error) {
+	if doc.Stacks == nil {
+		doc.Stacks = []models.Stack{}
+	}
+
+	var result strings.Builder
+	if err := f.tmpl.Execute(&result, doc); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	return result.String(), nil
+}
+
+// templateFuncs returns the helper functions available to output templates.
+// Argument order puts the piped value last, e.g. {{.Reasons | join ", "}}.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// join concatenates a list with a separator
+		"join": func(sep string, items []string) string {
+			return strings.Join(items, sep)
+		},
+		// formatTime formats a timestamp with a Go layout; unknown times render empty
+		"formatTime": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		},
+		// tag looks up a stack tag, returning an empty string when absent
+		"tag": func(stack models.Stack, key string) string {
+			return stack.StackTags[key]
+		},
+		// default returns fallback when value is empty
+		"default": func(fallback, value string) string {
+			if value == "" {
+				return fallback
+			}
+			return value
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
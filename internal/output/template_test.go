@@ -0,0 +1,108 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateTestStacks() []models.Stack {
+	return []models.Stack{
+		{
+			StackName: "orders-api-dev",
+			Region:    "us-east-1",
+			CreatedAt: time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+			StackTags: map[string]string{"Owner": "team-a"},
+			Reasons:   []string{"reason-1", "reason-2"},
+		},
+		{
+			StackName: "billing-prod",
+			Region:    "us-west-2",
+		},
+	}
+}
+
+func TestTemplateFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "index on tags",
+			template: `{{range .Stacks}}{{.StackName}} {{index .StackTags "Owner"}}{{"\n"}}{{end}}`,
+			expected: "orders-api-dev team-a\nbilling-prod \n",
+		},
+		{
+			name:     "tag and default helpers",
+			template: `{{range .Stacks}}{{tag . "Owner" | default "unowned"}};{{end}}`,
+			expected: "team-a;unowned;",
+		},
+		{
+			name:     "join helper",
+			template: `{{range .Stacks}}[{{.Reasons | join ", "}}]{{end}}`,
+			expected: "[reason-1, reason-2][]",
+		},
+		{
+			name:     "formatTime helper",
+			template: `{{range .Stacks}}<{{.CreatedAt | formatTime "2006-01-02"}}>{{end}}`,
+			expected: "<2023-01-15><>",
+		},
+		{
+			name:     "upper and lower helpers",
+			template: `{{range .Stacks}}{{upper .Region}} {{lower .StackName}};{{end}}`,
+			expected: "US-EAST-1 orders-api-dev;US-WEST-2 billing-prod;",
+		},
+		{
+			name:     "stack count",
+			template: `{{len .Stacks}} stacks`,
+			expected: "2 stacks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewTemplateFormatter(tt.template)
+			require.NoError(t, err)
+
+			output, err := formatter.Format(templateTestStacks())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, output)
+		})
+	}
+}
+
+func TestTemplateFormatter_EmptyStacks(t *testing.T) {
+	formatter, err := NewTemplateFormatter(`{{len .Stacks}}`)
+	require.NoError(t, err)
+
+	output, err := formatter.Format(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0", output)
+}
+
+func TestNewTemplateFormatter_Errors(t *testing.T) {
+	_, err := NewTemplateFormatter("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--template")
+
+	_, err = NewTemplateFormatter(`{{range .Stacks}}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse template")
+
+	_, err = NewTemplateFormatter(`{{unknownFunc .}}`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse template")
+}
+
+func TestTemplateFormatter_ExecutionError(t *testing.T) {
+	formatter, err := NewTemplateFormatter(`{{.NoSuchField}}`)
+	require.NoError(t, err)
+
+	_, err = formatter.Format(templateTestStacks())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to execute template")
+}
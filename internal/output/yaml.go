@@ -0,0 +1,79 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLFormatter formats output as YAML using the same field names as JSON
+type YAMLFormatter struct{}
+
+// Format implements the Formatter interface for YAML output
+func (f *YAMLFormatter) Format(stacks []models.Stack) (string, error) {
+	return f.FormatDocument(models.StacksOutput{Stacks: stacks})
+}
+
+// FormatDocument implements the DocumentFormatter interface for YAML output
+func (f *YAMLFormatter) FormatDocument(doc models.StacksOutput) (string, error) {
+	// Render JSON first so field names and value encoding match the JSON output exactly
+	jsonOutput, err := (&JSONFormatter{}).FormatDocument(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonToYAML([]byte(jsonOutput))
+}
+
+// FormatValue implements the ValueFormatter interface for YAML output
+func (f *YAMLFormatter) FormatValue(value interface{}) (string, error) {
+	jsonOutput, err := (&JSONFormatter{}).FormatValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	return jsonToYAML([]byte(jsonOutput))
+}
+
+// jsonToYAML converts a JSON document to block-style YAML, preserving key order
+func jsonToYAML(data []byte) (string, error) {
+	// JSON is valid YAML, so parsing it into a node tree keeps the original key order
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return "", fmt.Errorf("failed to parse JSON for YAML conversion: %w", err)
+	}
+	resetStyle(&node)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// resetStyle drops the flow and quoting styles inherited from JSON syntax
+func resetStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+		// Keep empty collections readable as {} and []
+		if len(node.Content) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Style = 0
+	}
+
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestYAMLFormatter_Format(t *testing.T) {
+	formatter := &YAMLFormatter{}
+
+	stacks := []models.Stack{
+		{
+			StackName:   "true",
+			StackID:     "arn:aws:cloudformation:us-east-1:123456789012:stack/true/abc123",
+			Region:      "us-east-1",
+			Description: "Multi-line\ndescription: with colon",
+			CreatedAt:   time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+			UpdatedAt:   time.Date(2023, 1, 16, 11, 45, 0, 0, time.UTC),
+			StackTags:   map[string]string{"Environment": "test"},
+			Reasons:     []string{"Contains resource with logical ID 'ServerlessDeploymentBucket'"},
+		},
+	}
+
+	output, err := formatter.Format(stacks)
+	require.NoError(t, err)
+
+	// Field names match the JSON output
+	assert.Contains(t, output, "stacks:\n")
+	assert.Contains(t, output, "stackName:")
+	assert.Contains(t, output, "stackId:")
+	assert.Contains(t, output, "stackTags:\n")
+
+	// Values that look like other YAML types stay strings
+	var decoded struct {
+		Stacks []map[string]interface{} `yaml:"stacks"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(output), &decoded))
+	require.Len(t, decoded.Stacks, 1)
+	assert.Equal(t, "true", decoded.Stacks[0]["stackName"])
+	assert.Equal(t, "2023-01-15T10:30:00Z", decoded.Stacks[0]["createdAt"])
+	assert.Equal(t, stacks[0].Description, decoded.Stacks[0]["description"])
+}
+
+func TestYAMLFormatter_EmptyStacks(t *testing.T) {
+	formatter := &YAMLFormatter{}
+
+	output, err := formatter.Format(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "stacks: []", output)
+}
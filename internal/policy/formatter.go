@@ -0,0 +1,165 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats policy reports
+type Formatter = output.ReportFormatter[*Report]
+
+// TableFormatter formats the failed and unevaluable results as an aligned table for terminals
+type TableFormatter struct{}
+
+// Format implements the Formatter interface for table output
+func (f *TableFormatter) Format(report *Report) (string, error) {
+	summary := fmt.Sprintf("%d stacks checked against %d rules: %d passed, %d failed, %d errors",
+		report.Checked, len(report.Rules), report.Passed, report.Failed, report.Errors)
+	if report.Failed == 0 && report.Errors == 0 {
+		return summary, nil
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STACK NAME\tREGION\tRULE\tSEVERITY\tSTATUS\tMESSAGE")
+	for _, result := range report.Results {
+		if result.Status == StatusPass {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.StackName, result.Region, result.RuleID, result.Severity, result.Status, result.Message)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write table: %w", err)
+	}
+
+	out.WriteString("\n" + summary)
+	return out.String(), nil
+}
+
+// SARIFFormatter formats the failed and unevaluable results as a SARIF 2.1.0
+// log for code scanning tools. Stacks are reported as logical locations.
+type SARIFFormatter struct{}
+
+// sarifSchema is the JSON Schema of SARIF 2.1.0 logs
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     *sarifMessage      `json:"shortDescription,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName,omitempty"`
+	Kind               string `json:"kind"`
+}
+
+// Format implements the Formatter interface for SARIF output
+func (f *SARIFFormatter) Format(report *Report) (string, error) {
+	driver := sarifDriver{
+		Name:           "find_serverless_stacks",
+		InformationURI: "https://github.com/hassaku63/find-serverless-stacks",
+		Rules:          make([]sarifRule, 0, len(report.Rules)),
+	}
+	ruleIndex := make(map[string]int, len(report.Rules))
+	for i, rule := range report.Rules {
+		sr := sarifRule{ID: rule.ID, DefaultConfiguration: sarifConfiguration{Level: rule.Severity}}
+		if rule.Description != "" {
+			sr.ShortDescription = &sarifMessage{Text: rule.Description}
+		}
+		driver.Rules = append(driver.Rules, sr)
+		ruleIndex[rule.ID] = i
+	}
+
+	results := []sarifResult{}
+	for _, result := range report.Results {
+		if result.Status == StatusPass {
+			continue
+		}
+		results = append(results, sarifResult{
+			RuleID:    result.RuleID,
+			RuleIndex: ruleIndex[result.RuleID],
+			Level:     result.Severity,
+			Message:   sarifMessage{Text: fmt.Sprintf("%s (%s): %s", result.StackName, result.Region, result.Message)},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				Name:               result.StackName,
+				FullyQualifiedName: result.StackID,
+				Kind:               "resource",
+			}}}},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// formats lists the policy report formats in display order
+var formats = output.ReportFormats[*Report]{
+	{Name: "table", New: func() Formatter { return &TableFormatter{} }},
+	{Name: "json", New: func() Formatter { return output.JSON[*Report]{} }},
+	{Name: "sarif", New: func() Formatter { return &SARIFFormatter{} }},
+}
+
+// FormatterFactory creates a policy report formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("policy report", format)
+}
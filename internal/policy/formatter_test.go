@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	return &Report{
+		AsOf: time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		Rules: []Rule{
+			{ID: "prod-termination-protection", Description: "Production stacks must have termination protection", Severity: SeverityError, Condition: "terminationProtection"},
+			{ID: "approved-regions", Severity: SeverityWarning, Condition: `region in ["us-east-1"]`},
+		},
+		Checked: 2,
+		Passed:  2,
+		Failed:  1,
+		Errors:  1,
+		Results: []Result{
+			{RuleID: "prod-termination-protection", Severity: SeverityError, Status: StatusPass, StackName: "orders-prod", StackID: "arn:orders-prod", Region: "us-east-1"},
+			{RuleID: "approved-regions", Severity: SeverityWarning, Status: StatusPass, StackName: "orders-prod", StackID: "arn:orders-prod", Region: "us-east-1"},
+			{RuleID: "prod-termination-protection", Severity: SeverityError, Status: StatusFail, StackName: "users-prod", StackID: "arn:users-prod", Region: "eu-west-1",
+				Message: "Production stacks must have termination protection"},
+			{RuleID: "approved-regions", Severity: SeverityWarning, Status: StatusError, StackName: "users-prod", StackID: "arn:users-prod", Region: "eu-west-1",
+				Message: "failed to evaluate condition: no such key"},
+		},
+	}
+}
+
+func TestTableFormatter(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	result, err := formatter.Format(testReport())
+	require.NoError(t, err)
+	expected := `STACK NAME  REGION     RULE                         SEVERITY  STATUS  MESSAGE
+users-prod  eu-west-1  prod-termination-protection  error     fail    Production stacks must have termination protection
+users-prod  eu-west-1  approved-regions             warning   error   failed to evaluate condition: no such key
+
+2 stacks checked against 2 rules: 2 passed, 1 failed, 1 errors`
+	assert.Equal(t, expected, result)
+
+	result, err = formatter.Format(&Report{Checked: 3, Rules: testReport().Rules, Passed: 6})
+	require.NoError(t, err)
+	assert.Equal(t, "3 stacks checked against 2 rules: 6 passed, 0 failed, 0 errors", result)
+}
+
+func TestJSONFormatter(t *testing.T) {
+	result, err := output.JSON[*Report]{}.Format(testReport())
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	assert.Equal(t, "2026-06-01T00:00:00Z", decoded["asOf"])
+	assert.Len(t, decoded["rules"], 2)
+	assert.Len(t, decoded["results"], 4)
+	assert.Contains(t, result, `{"ruleId":"approved-regions","severity":"warning","status":"pass","stackName":"orders-prod","stackId":"arn:orders-prod","region":"us-east-1"}`)
+}
+
+func TestSARIFFormatter(t *testing.T) {
+	result, err := (&SARIFFormatter{}).Format(testReport())
+	require.NoError(t, err)
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal([]byte(result), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+
+	driver := log.Runs[0].Tool.Driver
+	assert.Equal(t, "find_serverless_stacks", driver.Name)
+	assert.Equal(t, []sarifRule{
+		{ID: "prod-termination-protection", ShortDescription: &sarifMessage{Text: "Production stacks must have termination protection"}, DefaultConfiguration: sarifConfiguration{Level: "error"}},
+		{ID: "approved-regions", DefaultConfiguration: sarifConfiguration{Level: "warning"}},
+	}, driver.Rules)
+
+	// Passing results are left out
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:    "prod-termination-protection",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{Text: "users-prod (eu-west-1): Production stacks must have termination protection"},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{Name: "users-prod", FullyQualifiedName: "arn:users-prod", Kind: "resource"}}}},
+		},
+		{
+			RuleID:    "approved-regions",
+			RuleIndex: 1,
+			Level:     "warning",
+			Message:   sarifMessage{Text: "users-prod (eu-west-1): failed to evaluate condition: no such key"},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{Name: "users-prod", FullyQualifiedName: "arn:users-prod", Kind: "resource"}}}},
+		},
+	}, log.Runs[0].Results)
+
+	empty, err := (&SARIFFormatter{}).Format(&Report{Rules: testReport().Rules})
+	require.NoError(t, err)
+	assert.Contains(t, empty, `"results": []`)
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"table", "json", "sarif"}, formats.Names())
+}
@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/expr"
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of rules, from the most to the least severe. They match the SARIF result levels.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityNote    = "note"
+)
+
+// severityRank orders the severities; higher is more severe
+var severityRank = map[string]int{
+	SeverityNote:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// Statuses of a rule evaluated for a stack
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	// StatusError means the rule could not be evaluated, such as when it reads a missing tag
+	StatusError = "error"
+)
+
+// Rule is a condition every stack it applies to must satisfy
+type Rule struct {
+	ID          string `json:"id" yaml:"id"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Severity    string `json:"severity" yaml:"severity"`
+	// When is an optional expression selecting the stacks the rule applies to
+	When string `json:"when,omitempty" yaml:"when"`
+	// Condition is the expression a stack must satisfy to pass
+	Condition string `json:"condition" yaml:"condition"`
+
+	when      *expr.Program
+	condition *expr.Program
+}
+
+// Policy is a list of rules evaluated against every stack
+type Policy struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ParsePolicy parses a policy written in YAML or JSON and compiles its expressions
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("policy is empty")
+		}
+		return nil, err
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// compile validates the rules and compiles their expressions
+func (p *Policy) compile() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy has no rules")
+	}
+
+	seen := make(map[string]bool)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d has no id", i+1)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("rule id %s is used more than once", rule.ID)
+		}
+		seen[rule.ID] = true
+
+		if rule.Severity == "" {
+			rule.Severity = SeverityError
+		}
+		if err := ValidateSeverity(rule.Severity); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+
+		condition, err := expr.Compile(rule.Condition)
+		if err != nil {
+			return fmt.Errorf("rule %s condition: %w", rule.ID, err)
+		}
+		rule.condition = condition
+
+		if rule.When != "" {
+			when, err := expr.Compile(rule.When)
+			if err != nil {
+				return fmt.Errorf("rule %s when: %w", rule.ID, err)
+			}
+			rule.when = when
+		}
+	}
+	return nil
+}
+
+// ValidateSeverity checks that severity is error, warning or note
+func ValidateSeverity(severity string) error {
+	if _, ok := severityRank[severity]; !ok {
+		return fmt.Errorf("invalid severity '%s' (must be error, warning or note)", severity)
+	}
+	return nil
+}
+
+// Result is the outcome of a rule for a stack
+type Result struct {
+	RuleID    string `json:"ruleId"`
+	Severity  string `json:"severity"`
+	Status    string `json:"status"`
+	StackName string `json:"stackName"`
+	StackID   string `json:"stackId"`
+	Region    string `json:"region"`
+	// Message explains failures and evaluation errors
+	Message string `json:"message,omitempty"`
+}
+
+// Report is the outcome of evaluating a policy against stacks
+type Report struct {
+	// AsOf is the time ages are measured from: the scan start when known
+	AsOf    time.Time `json:"asOf"`
+	Rules   []Rule    `json:"rules"`
+	Checked int       `json:"checked"`
+	Passed  int       `json:"passed"`
+	Failed  int       `json:"failed"`
+	Errors  int       `json:"errors"`
+	// Results lists the rules that apply to each stack, stack by stack
+	Results []Result `json:"results"`
+}
+
+// Evaluate evaluates every rule of the policy against every stack
+func Evaluate(stacks []models.Stack, policy *Policy, asOf time.Time, missing []string) *Report {
+	report := &Report{AsOf: asOf, Rules: policy.Rules, Checked: len(stacks), Results: []Result{}}
+
+	for _, stack := range stacks {
+		for _, rule := range policy.Rules {
+			result, applies := evaluateRule(rule, stack, asOf, missing)
+			if !applies {
+				continue
+			}
+
+			switch result.Status {
+			case StatusPass:
+				report.Passed++
+			case StatusFail:
+				report.Failed++
+			case StatusError:
+				report.Errors++
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	return report
+}
+
+// evaluateRule evaluates a rule for a stack, reporting false when the rule does not apply.
+// Rules whose result depends on a missing optional field are reported as errors.
+func evaluateRule(rule Rule, stack models.Stack, asOf time.Time, missing []string) (Result, bool) {
+	result := Result{
+		RuleID:    rule.ID,
+		Severity:  rule.Severity,
+		StackName: stack.StackName,
+		StackID:   stack.StackID,
+		Region:    stack.Region,
+	}
+
+	if rule.when != nil {
+		applies, err := rule.when.EvalWithout(stack, asOf, missing)
+		if err != nil {
+			result.Status = StatusError
+			result.Message = fmt.Sprintf("failed to evaluate when: %v", err)
+			return result, true
+		}
+		if !applies {
+			return result, false
+		}
+	}
+
+	passed, err := rule.condition.EvalWithout(stack, asOf, missing)
+	switch {
+	case err != nil:
+		result.Status = StatusError
+		result.Message = fmt.Sprintf("failed to evaluate condition: %v", err)
+	case passed:
+		result.Status = StatusPass
+	default:
+		result.Status = StatusFail
+		result.Message = rule.Description
+		if result.Message == "" {
+			result.Message = fmt.Sprintf("condition not met: %s", rule.Condition)
+		}
+	}
+	return result, true
+}
+
+// Violations counts the failed and unevaluable results of rules at least as severe as minSeverity
+func (r *Report) Violations(minSeverity string) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status != StatusPass && severityRank[result.Severity] >= severityRank[minSeverity] {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,150 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+rules:
+  - id: prod-termination-protection
+    description: Production stacks must have termination protection
+    when: stage == "prod"
+    condition: terminationProtection
+  - id: approved-regions
+    severity: warning
+    condition: region in ["us-east-1", "eu-west-1"]
+  - id: dev-max-age
+    severity: note
+    when: stage == "dev"
+    condition: age < duration("720h")
+  - id: cost-center
+    condition: tags.CostCenter != ""
+`
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		expectedErrMsg string
+	}{
+		{name: "yaml", data: testPolicy},
+		{name: "json", data: `{"rules": [{"id": "framework-v4", "condition": "frameworkMajor >= 4"}]}`},
+		{name: "empty", data: "", expectedErrMsg: "policy is empty"},
+		{name: "no rules", data: "rules: []", expectedErrMsg: "policy has no rules"},
+		{name: "missing id", data: "rules:\n  - condition: 'true'", expectedErrMsg: "rule 1 has no id"},
+		{name: "duplicate id", data: "rules:\n  - {id: a, condition: 'true'}\n  - {id: a, condition: 'true'}", expectedErrMsg: "rule id a is used more than once"},
+		{name: "invalid severity", data: "rules:\n  - {id: a, severity: critical, condition: 'true'}", expectedErrMsg: "rule a: invalid severity 'critical'"},
+		{name: "missing condition", data: "rules:\n  - {id: a}", expectedErrMsg: "rule a condition: expression is empty"},
+		{name: "invalid condition", data: "rules:\n  - {id: a, condition: 'stag == \"dev\"'}", expectedErrMsg: "undeclared reference to 'stag'"},
+		{name: "invalid when", data: "rules:\n  - {id: a, when: 'region', condition: 'true'}", expectedErrMsg: "rule a when: expression must be a boolean"},
+		{name: "unknown field", data: "rules:\n  - {id: a, expr: 'true'}", expectedErrMsg: "field expr not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParsePolicy([]byte(tt.data))
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			for _, rule := range policy.Rules {
+				assert.NotEmpty(t, rule.Severity)
+			}
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Len(t, policy.Rules, 4)
+	assert.Equal(t, SeverityError, policy.Rules[0].Severity)
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read policy file")
+}
+
+func TestEvaluate(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	asOf := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	protected := true
+	stacks := []models.Stack{
+		{
+			StackName:             "orders-prod",
+			Region:                "us-east-1",
+			UpdatedAt:             asOf.AddDate(0, 0, -1),
+			StackTags:             map[string]string{"CostCenter": "1234"},
+			TerminationProtection: &protected,
+		},
+		{
+			StackName: "users-dev",
+			Region:    "ap-northeast-1",
+			UpdatedAt: asOf.AddDate(0, 0, -60),
+		},
+	}
+
+	report := Evaluate(stacks, policy, asOf, nil)
+	assert.Equal(t, asOf, report.AsOf)
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 3, report.Passed)
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, 1, report.Errors)
+
+	assert.Equal(t, []Result{
+		{RuleID: "prod-termination-protection", Severity: SeverityError, Status: StatusPass, StackName: "orders-prod", Region: "us-east-1"},
+		{RuleID: "approved-regions", Severity: SeverityWarning, Status: StatusPass, StackName: "orders-prod", Region: "us-east-1"},
+		{RuleID: "cost-center", Severity: SeverityError, Status: StatusPass, StackName: "orders-prod", Region: "us-east-1"},
+		{RuleID: "approved-regions", Severity: SeverityWarning, Status: StatusFail, StackName: "users-dev", Region: "ap-northeast-1",
+			Message: `condition not met: region in ["us-east-1", "eu-west-1"]`},
+		{RuleID: "dev-max-age", Severity: SeverityNote, Status: StatusFail, StackName: "users-dev", Region: "ap-northeast-1",
+			Message: `condition not met: age < duration("720h")`},
+		{RuleID: "cost-center", Severity: SeverityError, Status: StatusError, StackName: "users-dev", Region: "ap-northeast-1",
+			Message: "failed to evaluate condition: no such key: CostCenter"},
+	}, report.Results)
+
+	assert.Equal(t, 1, report.Violations(SeverityError))
+	assert.Equal(t, 2, report.Violations(SeverityWarning))
+	assert.Equal(t, 3, report.Violations(SeverityNote))
+}
+
+func TestEvaluate_Description(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	report := Evaluate([]models.Stack{{StackName: "orders-prod", Region: "us-east-1", StackTags: map[string]string{"CostCenter": "1"}}}, policy, time.Now(), nil)
+	require.Equal(t, StatusFail, report.Results[0].Status)
+	assert.Equal(t, "Production stacks must have termination protection", report.Results[0].Message)
+}
+
+func TestEvaluate_MissingFields(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	stacks := []models.Stack{
+		{StackName: "orders-prod", Region: "us-east-1", StackTags: map[string]string{"CostCenter": "1"}},
+		{StackName: "users-dev", Region: "us-east-1", StackTags: map[string]string{"CostCenter": "1"}},
+	}
+	report := Evaluate(stacks, policy, time.Now(), []string{"terminationProtection"})
+
+	// Only the prod stack reads the missing field
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, Result{
+		RuleID: "prod-termination-protection", Severity: SeverityError, Status: StatusError, StackName: "orders-prod", Region: "us-east-1",
+		Message: "failed to evaluate condition: field terminationProtection not in input; re-save with --fields terminationProtection",
+	}, report.Results[0])
+}
@@ -0,0 +1,106 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/jmespath/go-jmespath"
+)
+
+// Query is a compiled JMESPath expression evaluated against the stacks output document
+type Query struct {
+	expression string
+	compiled   *jmespath.JMESPath
+}
+
+// Compile parses a JMESPath expression so syntax errors are reported before scanning
+func Compile(expression string) (*Query, error) {
+	compiled, err := jmespath.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query '%s': %w", expression, err)
+	}
+
+	return &Query{expression: expression, compiled: compiled}, nil
+}
+
+// String returns the original expression
+func (q *Query) String() string {
+	return q.expression
+}
+
+// identifierPattern matches JMESPath identifiers, quoted or not
+var identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// Identifiers returns the words of the expression that may name fields, such as
+// "terminationProtection" in "stacks[?terminationProtection]". Words inside
+// literals are included too, so it can name more fields than the query reads.
+func (q *Query) Identifiers() []string {
+	return identifierPattern.FindAllString(q.expression, -1)
+}
+
+// Apply evaluates the query against the document, using the JSON field names
+func (q *Query) Apply(output models.StacksOutput) (interface{}, error) {
+	if output.Stacks == nil {
+		output.Stacks = []models.Stack{}
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal stacks output: %w", err)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to decode stacks output: %w", err)
+	}
+
+	result, err := q.compiled.Search(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate query '%s': %w", q.expression, err)
+	}
+
+	return result, nil
+}
+
+// Stacks converts a query result back to stacks, for formats that only render
+// stacks. The result must be a list of stack objects, each with a stackName, or
+// a document with a "stacks" list, such as the result of "stacks[?region=='us-east-1']".
+func Stacks(result interface{}) ([]models.Stack, error) {
+	if document, ok := result.(map[string]interface{}); ok {
+		if stacks, exists := document["stacks"]; exists {
+			result = stacks
+		}
+	}
+
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("query result is not a list of stacks; use --output json or yaml for projections")
+	}
+
+	stacks := make([]models.Stack, 0, len(items))
+	for _, item := range items {
+		// A projection such as "stacks[].{n: stackName}" yields objects that are not stacks
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("query result is not a list of stacks; use --output json or yaml for projections")
+		}
+		if name, _ := object["stackName"].(string); name == "" {
+			return nil, fmt.Errorf("query result is not a list of stacks: an item has no stackName; use --output json or yaml for projections")
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal query result: %w", err)
+		}
+
+		var stack models.Stack
+		if err := json.Unmarshal(data, &stack); err != nil {
+			return nil, fmt.Errorf("query result is not a list of stacks: %w", err)
+		}
+		stacks = append(stacks, stack)
+	}
+
+	return stacks, nil
+}
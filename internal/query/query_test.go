@@ -0,0 +1,145 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOutput() models.StacksOutput {
+	return models.StacksOutput{
+		Stacks: []models.Stack{
+			{
+				StackName: "orders-api-prod",
+				Region:    "us-east-1",
+				CreatedAt: time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC),
+				StackTags: map[string]string{"Environment": "prod", "Owner": "team-a"},
+			},
+			{
+				StackName: "orders-api-dev",
+				Region:    "us-west-2",
+				StackTags: map[string]string{"Environment": "dev"},
+			},
+		},
+	}
+}
+
+func TestQuery_Apply(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   interface{}
+	}{
+		{
+			name:       "filter and project",
+			expression: "stacks[?stackTags.Environment=='prod'].stackName",
+			expected:   []interface{}{"orders-api-prod"},
+		},
+		{
+			name:       "multi-select hash",
+			expression: "stacks[].{name: stackName, owner: stackTags.Owner}",
+			expected: []interface{}{
+				map[string]interface{}{"name": "orders-api-prod", "owner": "team-a"},
+				map[string]interface{}{"name": "orders-api-dev", "owner": nil},
+			},
+		},
+		{
+			name:       "function",
+			expression: "length(stacks)",
+			expected:   float64(2),
+		},
+		{
+			name:       "no match",
+			expression: "stacks[?region=='eu-west-1'].stackName",
+			expected:   []interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Compile(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expression, q.String())
+
+			result, err := q.Apply(testOutput())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestCompile_InvalidExpression(t *testing.T) {
+	_, err := Compile("stacks[?")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid query")
+}
+
+func TestStacks(t *testing.T) {
+	q, err := Compile("stacks[?region=='us-east-1']")
+	require.NoError(t, err)
+
+	result, err := q.Apply(testOutput())
+	require.NoError(t, err)
+
+	stacks, err := Stacks(result)
+	require.NoError(t, err)
+	require.Len(t, stacks, 1)
+	assert.Equal(t, "orders-api-prod", stacks[0].StackName)
+	assert.Equal(t, "team-a", stacks[0].StackTags["Owner"])
+	assert.Equal(t, time.Date(2023, 1, 15, 10, 30, 0, 0, time.UTC), stacks[0].CreatedAt)
+}
+
+func TestStacks_Document(t *testing.T) {
+	q, err := Compile("@")
+	require.NoError(t, err)
+
+	result, err := q.Apply(testOutput())
+	require.NoError(t, err)
+
+	stacks, err := Stacks(result)
+	require.NoError(t, err)
+	assert.Len(t, stacks, 2)
+}
+
+func TestStacks_Projection(t *testing.T) {
+	tests := []struct {
+		name   string
+		result interface{}
+	}{
+		{name: "list of strings", result: []interface{}{"orders-api-prod"}},
+		{name: "scalar", result: float64(2)},
+		{name: "null", result: nil},
+		{name: "object projection", result: []interface{}{map[string]interface{}{"n": "orders-api-prod"}}},
+		{name: "empty stack name", result: []interface{}{map[string]interface{}{"stackName": "", "region": "us-east-1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Stacks(tt.result)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "--output json or yaml")
+		})
+	}
+}
+
+func TestStacks_ProjectionQuery(t *testing.T) {
+	q, err := Compile("stacks[].{n: stackName}")
+	require.NoError(t, err)
+
+	result, err := q.Apply(testOutput())
+	require.NoError(t, err)
+
+	// Decoding would yield blank stacks, so the projection is rejected
+	_, err = Stacks(result)
+	assert.ErrorContains(t, err, "no stackName")
+}
+
+func TestQuery_Identifiers(t *testing.T) {
+	q, err := Compile(`stacks[?terminationProtection && outputs."ServiceEndpoint" != null].{name: stackName}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"stacks", "terminationProtection", "outputs", "ServiceEndpoint", "null", "name", "stackName"}, q.Identifiers())
+}
@@ -0,0 +1,145 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// Draft is the JSON Schema dialect of the generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// StacksOutputID identifies the published schema of the output document
+const StacksOutputID = "https://raw.githubusercontent.com/hassaku63/find-serverless-stacks/main/schema/stacks-output.schema.json"
+
+// Schema is a JSON Schema document or subschema
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// timeType is rendered as an RFC 3339 string, matching encoding/json
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generate builds a schema for a Go type from its encoding/json field tags.
+// Slices and maps may encode as null and are marked nullable accordingly, as
+// are fields tagged nullable:"true"; a field's "description" tag becomes the
+// property description.
+func Generate(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return nullable(Generate(t.Elem()))
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return generateObject(t)
+	case reflect.Slice, reflect.Array:
+		return nullable(&Schema{Type: "array", Items: Generate(t.Elem())})
+	case reflect.Map:
+		return nullable(&Schema{Type: "object", AdditionalProperties: Generate(t.Elem())})
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	}
+
+	// Interfaces and other kinds accept any value
+	return &Schema{}
+}
+
+// generateObject builds an object schema; fields without omitempty are required
+func generateObject(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := Generate(field.Type)
+		property.Description = field.Tag.Get("description")
+		if field.Tag.Get("nullable") == "true" {
+			// Types with custom encoding, such as timestamps that encode as null when unknown
+			nullable(property)
+		}
+		schema.Properties[name] = property
+
+		if strings.Contains(","+options+",", ",omitempty,") {
+			// Empty values are omitted rather than encoded as null
+			notNull(property)
+		} else {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
+
+// nullable allows null in addition to the schema's type
+func nullable(schema *Schema) *Schema {
+	if typeName, ok := schema.Type.(string); ok {
+		schema.Type = []string{typeName, "null"}
+	}
+	return schema
+}
+
+// notNull removes null from the schema's types
+func notNull(schema *Schema) {
+	if types, ok := schema.Type.([]string); ok && len(types) == 2 && types[1] == "null" {
+		schema.Type = types[0]
+	}
+}
+
+// StacksOutput returns the published schema of the output document
+func StacksOutput() *Schema {
+	schema := Generate(reflect.TypeOf(models.StacksOutput{}))
+	schema.Schema = Draft
+	schema.ID = StacksOutputID
+	schema.Title = "find_serverless_stacks output"
+	schema.Description = fmt.Sprintf("Stacks detected by find_serverless_stacks (schema versions 1 to %d)", models.LatestSchemaVersion)
+
+	// Readers reject documents newer than they understand
+	maximum := models.LatestSchemaVersion
+	schema.Properties["schemaVersion"].Maximum = &maximum
+
+	return schema
+}
+
+// Marshal renders a schema as indented JSON with a trailing newline
+func Marshal(schema *Schema) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishedSchemaPath is the schema file committed to the repository
+const publishedSchemaPath = "../../schema/stacks-output.schema.json"
+
+// compileSchema compiles the generated schema with format assertions enabled
+func compileSchema(t *testing.T) *jsonschema.Schema {
+	t.Helper()
+
+	data, err := Marshal(StacksOutput())
+	require.NoError(t, err)
+
+	doc, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	require.NoError(t, compiler.AddResource(StacksOutputID, doc))
+
+	schema, err := compiler.Compile(StacksOutputID)
+	require.NoError(t, err)
+	return schema
+}
+
+// validate checks a JSON document against the schema
+func validate(t *testing.T, schema *jsonschema.Schema, document string) error {
+	t.Helper()
+
+	instance, err := jsonschema.UnmarshalJSON(strings.NewReader(document))
+	require.NoError(t, err)
+	return schema.Validate(instance)
+}
+
+func TestPublishedSchemaIsUpToDate(t *testing.T) {
+	generated, err := Marshal(StacksOutput())
+	require.NoError(t, err)
+
+	published, err := os.ReadFile(publishedSchemaPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(generated), string(published),
+		"schema/stacks-output.schema.json is out of date; regenerate it with 'make schema'")
+}
+
+func TestStacksOutput_ValidatesJSONOutput(t *testing.T) {
+	schema := compileSchema(t)
+
+	tests := []struct {
+		name string
+		doc  models.StacksOutput
+	}{
+		{
+			name: "full document",
+			doc: models.StacksOutput{
+				SchemaVersion: models.LatestSchemaVersion,
+				Scan: &models.ScanMetadata{
+					StartedAt:    time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+					Duration:     2.345,
+					AccountID:    "123456789012",
+					CallerARN:    "arn:aws:iam::123456789012:user/scanner",
+					Profile:      "default",
+					Regions:      []string{"us-east-1"},
+					ToolVersion:  "dev",
+					RulesApplied: []string{"ServerlessDeploymentBucket"},
+					Errors:       []models.ScanError{{StackName: "broken", Operation: "DescribeStackResources", Message: "access denied"}},
+				},
+				Stacks: []models.Stack{
+					{
+						StackName: "my-api-dev",
+						StackID:   "arn:aws:cloudformation:us-east-1:123456789012:stack/my-api-dev/abc",
+						Region:    "us-east-1",
+						CreatedAt: time.Date(2023, 10, 1, 12, 34, 56, 0, time.UTC),
+						UpdatedAt: time.Date(2023, 10, 2, 12, 34, 56, 0, time.UTC),
+						StackTags: map[string]string{"STAGE": "dev"},
+						Reasons:   []string{"Contains resource with logical ID 'ServerlessDeploymentBucket'"},
+					},
+					{
+						StackName:    "my-api-prod",
+						Region:       "us-east-1",
+						NeverUpdated: true,
+					},
+				},
+			},
+		},
+		{
+			name: "unversioned document with nil collections",
+			doc:  models.StacksOutput{Stacks: []models.Stack{{StackName: "legacy"}}},
+		},
+		{
+			name: "empty scan",
+			doc: models.StacksOutput{
+				SchemaVersion: models.DefaultSchemaVersion,
+				Scan:          &models.ScanMetadata{StartedAt: time.Now().UTC()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := (&output.JSONFormatter{}).FormatDocument(tt.doc)
+			require.NoError(t, err)
+			assert.NoError(t, validate(t, schema, document))
+		})
+	}
+}
+
+func TestStacksOutput_RejectsInvalidDocuments(t *testing.T) {
+	schema := compileSchema(t)
+
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{name: "missing stacks", document: `{"schemaVersion":1}`},
+		{name: "newer schema version", document: `{"schemaVersion":3,"stacks":[]}`},
+		{name: "invalid timestamp", document: `{"stacks":[{"stackName":"a","stackId":"","region":"","createdAt":"yesterday","updatedAt":"2024-01-01T00:00:00Z","description":"","stackTags":null,"reasons":null}]}`},
+		{name: "wrong tag value type", document: `{"stacks":[{"stackName":"a","stackId":"","region":"","createdAt":"2024-01-01T00:00:00Z","updatedAt":"2024-01-01T00:00:00Z","description":"","stackTags":{"k":1},"reasons":null}]}`},
+		{name: "scan without start time", document: `{"scan":{"duration":1,"regions":[],"toolVersion":"dev","rulesApplied":[],"errors":[]},"stacks":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, validate(t, schema, tt.document))
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type example struct {
+		Name     string            `json:"name"`
+		Count    int               `json:"count,omitempty"`
+		Labels   map[string]string `json:"labels"`
+		Optional *time.Time        `json:"optional,omitempty"`
+		Ignored  string            `json:"-"`
+		private  string
+	}
+
+	schema := Generate(reflect.TypeOf(example{}))
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"name", "labels"}, schema.Required)
+	assert.Len(t, schema.Properties, 4)
+	assert.Equal(t, "integer", schema.Properties["count"].Type)
+	assert.Equal(t, []string{"object", "null"}, schema.Properties["labels"].Type)
+	assert.Equal(t, "string", schema.Properties["labels"].AdditionalProperties.Type)
+	assert.Equal(t, "string", schema.Properties["optional"].Type)
+	assert.Equal(t, "date-time", schema.Properties["optional"].Format)
+}
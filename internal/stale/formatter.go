@@ -0,0 +1,154 @@
+package stale
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats stale reports
+type Formatter = output.ReportFormatter[*Report]
+
+// reportHeader lists the columns of the tabular formats
+var reportHeader = []string{"StackName", "Region", "Stage", "Owner", "LastDeployedAt", "AgeDays", "Ephemeral", "StackID"}
+
+// reportRow renders an entry as the values of reportHeader
+func reportRow(entry Entry) []string {
+	return []string{
+		entry.StackName,
+		entry.Region,
+		entry.Stage,
+		entry.Owner,
+		entry.LastDeployedAt.Format(time.RFC3339),
+		strconv.Itoa(entry.AgeDays),
+		strconv.FormatBool(entry.Ephemeral),
+		entry.StackID,
+	}
+}
+
+// TableFormatter formats a stale report as an aligned table for terminals,
+// marking ephemeral-looking stages with an asterisk
+type TableFormatter struct{}
+
+// tableDateFormat is the date layout of the table and Markdown output
+const tableDateFormat = "2006-01-02"
+
+// Format implements the Formatter interface for table output
+func (f *TableFormatter) Format(report *Report) (string, error) {
+	if len(report.Stacks) == 0 {
+		return fmt.Sprintf("No stacks last deployed more than %s ago.", report.OlderThan), nil
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STACK NAME\tREGION\tSTAGE\tOWNER\tLAST DEPLOYED\tAGE")
+	ephemeral := 0
+	for _, entry := range report.Stacks {
+		stage := entry.Stage
+		if entry.Ephemeral {
+			stage += " *"
+			ephemeral++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%dd\n",
+			entry.StackName, entry.Region, stage, orNone(entry.Owner), entry.LastDeployedAt.Format(tableDateFormat), entry.AgeDays)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write table: %w", err)
+	}
+
+	out.WriteString(fmt.Sprintf("\n%d stacks last deployed more than %s ago, %d with ephemeral stages (*)", len(report.Stacks), report.OlderThan, ephemeral))
+	if report.Skipped > 0 {
+		out.WriteString(fmt.Sprintf("; %d skipped with unknown deployment time", report.Skipped))
+	}
+
+	return out.String(), nil
+}
+
+// CSVFormatter formats a stale report as RFC 4180 Comma-Separated Values
+type CSVFormatter struct{}
+
+// Format implements the Formatter interface for CSV output
+func (f *CSVFormatter) Format(report *Report) (string, error) {
+	var out strings.Builder
+	writer := csv.NewWriter(&out)
+	writer.UseCRLF = true // RFC 4180 line endings
+
+	if err := writer.Write(reportHeader); err != nil {
+		return "", fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, entry := range report.Stacks {
+		if err := writer.Write(reportRow(entry)); err != nil {
+			return "", fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return strings.TrimSuffix(out.String(), "\r\n"), nil
+}
+
+// MarkdownFormatter formats a stale report as a GitHub-flavoured Markdown table
+type MarkdownFormatter struct{}
+
+// Format implements the Formatter interface for Markdown output
+func (f *MarkdownFormatter) Format(report *Report) (string, error) {
+	var out strings.Builder
+
+	out.WriteString("| Stack | Region | Stage | Owner | Last deployed | Age (days) | Ephemeral |\n")
+	out.WriteString("|-------|--------|-------|-------|---------------|------------|-----------|\n")
+	for _, entry := range report.Stacks {
+		ephemeral := ""
+		if entry.Ephemeral {
+			ephemeral = "yes"
+		}
+		out.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %d | %s |\n",
+			escapeMarkdown(entry.StackName),
+			escapeMarkdown(entry.Region),
+			escapeMarkdown(entry.Stage),
+			escapeMarkdown(entry.Owner),
+			entry.LastDeployedAt.Format(tableDateFormat),
+			entry.AgeDays,
+			ephemeral,
+		))
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// formats lists the stale report formats in display order
+var formats = output.ReportFormats[*Report]{
+	{Name: "table", New: func() Formatter { return &TableFormatter{} }},
+	{Name: "json", New: func() Formatter { return output.JSON[*Report]{} }},
+	{Name: "csv", New: func() Formatter { return &CSVFormatter{} }},
+	{Name: "markdown", New: func() Formatter { return &MarkdownFormatter{} }},
+}
+
+// FormatterFactory creates a stale report formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("stale report", format)
+}
+
+// orNone marks empty values explicitly in the table
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// escapeMarkdown escapes characters that would break a Markdown table cell
+func escapeMarkdown(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\n", " ")
+	return value
+}
@@ -0,0 +1,65 @@
+package stale
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleReport() *Report {
+	return Find(testStacks(), testAsOf, Options{OlderThan: 90 * 24 * time.Hour, EphemeralStages: DefaultEphemeralStages})
+}
+
+func TestTableFormatter_Format(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	output, err := formatter.Format(sampleReport())
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "STACK NAME         REGION     STAGE        OWNER   LAST DEPLOYED  AGE")
+	assert.Contains(t, output, "billing-sandbox    us-west-2  feature-x *  team-b  2023-08-01     305d")
+	assert.Contains(t, output, "billing-alice      us-west-2  alice        (none)  2024-02-01     121d")
+	assert.Contains(t, output, "3 stacks last deployed more than 90d ago, 2 with ephemeral stages (*); 1 skipped with unknown deployment time")
+
+	output, err = formatter.Format(Find(nil, testAsOf, Options{OlderThan: 90 * 24 * time.Hour}))
+	require.NoError(t, err)
+	assert.Equal(t, "No stacks last deployed more than 90d ago.", output)
+}
+
+func TestJSONFormatter_Format(t *testing.T) {
+	result, err := output.JSON[*Report]{}.Format(sampleReport())
+	require.NoError(t, err)
+
+	var decoded Report
+	require.NoError(t, json.Unmarshal([]byte(result), &decoded))
+	assert.Equal(t, "90d", decoded.OlderThan)
+	require.Len(t, decoded.Stacks, 3)
+	assert.Equal(t, "pr-123", decoded.Stacks[1].Stage)
+}
+
+func TestCSVFormatter_Format(t *testing.T) {
+	output, err := (&CSVFormatter{}).Format(sampleReport())
+	require.NoError(t, err)
+
+	assert.Equal(t, "StackName,Region,Stage,Owner,LastDeployedAt,AgeDays,Ephemeral,StackID\r\n"+
+		"billing-sandbox,us-west-2,feature-x,team-b,2023-08-01T00:00:00Z,305,true,\r\n"+
+		"orders-api-pr-123,us-east-1,pr-123,team-a,2023-12-01T00:00:00Z,183,true,\r\n"+
+		"billing-alice,us-west-2,alice,,2024-02-01T00:00:00Z,121,false,", output)
+}
+
+func TestMarkdownFormatter_Format(t *testing.T) {
+	output, err := (&MarkdownFormatter{}).Format(sampleReport())
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "| Stack | Region | Stage | Owner | Last deployed | Age (days) | Ephemeral |")
+	assert.Contains(t, output, "| orders-api-pr-123 | us-east-1 | pr-123 | team-a | 2023-12-01 | 183 | yes |")
+	assert.Contains(t, output, "| billing-alice | us-west-2 | alice |  | 2024-02-01 | 121 |  |")
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"table", "json", "csv", "markdown"}, formats.Names())
+}
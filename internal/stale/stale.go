@@ -0,0 +1,190 @@
+package stale
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// DefaultEphemeralStages are the stage patterns of short-lived deployments
+// such as pull request previews and feature branches
+var DefaultEphemeralStages = []string{
+	"pr-*",
+	"pr[0-9]*",
+	"feature-*",
+	"feat-*",
+	"fix-*",
+	"bugfix-*",
+	"hotfix-*",
+	"tmp-*",
+	"test-*",
+}
+
+// Options configures which stacks are reported as stale
+type Options struct {
+	// OlderThan is the minimum time since the last deployment
+	OlderThan time.Duration
+	// EphemeralStages are glob patterns of stages that look short-lived
+	EphemeralStages []string
+}
+
+// Validate checks the threshold and the stage patterns
+func (o Options) Validate() error {
+	if o.OlderThan <= 0 {
+		return fmt.Errorf("the age threshold must be positive")
+	}
+
+	for _, pattern := range o.EphemeralStages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid stage pattern '%s': %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// Entry describes a stale stack
+type Entry struct {
+	StackName      string    `json:"stackName"`
+	StackID        string    `json:"stackId"`
+	Region         string    `json:"region"`
+	Stage          string    `json:"stage"`
+	Owner          string    `json:"owner"`
+	LastDeployedAt time.Time `json:"lastDeployedAt"`
+	AgeDays        int       `json:"ageDays"`
+	// Ephemeral is set when the stage matches one of the ephemeral stage patterns
+	Ephemeral bool `json:"ephemeral"`
+}
+
+// Report lists the stale stacks from oldest to newest
+type Report struct {
+	// AsOf is the time ages are measured from: the scan start when known
+	AsOf      time.Time `json:"asOf"`
+	OlderThan string    `json:"olderThan"`
+	Stacks    []Entry   `json:"stacks"`
+	// Skipped counts stacks left out because their deployment time is unknown
+	Skipped int `json:"skipped"`
+}
+
+// Find returns the stacks last deployed more than opts.OlderThan before asOf
+func Find(stacks []models.Stack, asOf time.Time, opts Options) *Report {
+	report := &Report{
+		AsOf:      asOf,
+		OlderThan: FormatAge(opts.OlderThan),
+		Stacks:    []Entry{},
+	}
+
+	for _, stack := range stacks {
+		deployedAt := stack.LastDeployedAt()
+		if deployedAt.IsZero() {
+			report.Skipped++
+			continue
+		}
+
+		age := asOf.Sub(deployedAt)
+		if age < opts.OlderThan {
+			continue
+		}
+
+		stage, ephemeral := ephemeralStage(stack, opts.EphemeralStages)
+		report.Stacks = append(report.Stacks, Entry{
+			StackName:      stack.StackName,
+			StackID:        stack.StackID,
+			Region:         stack.Region,
+			Stage:          stage,
+			Owner:          stack.Owner(),
+			LastDeployedAt: deployedAt,
+			AgeDays:        int(age / (24 * time.Hour)),
+			Ephemeral:      ephemeral,
+		})
+	}
+
+	sort.SliceStable(report.Stacks, func(i, j int) bool {
+		a, b := report.Stacks[i], report.Stacks[j]
+		if !a.LastDeployedAt.Equal(b.LastDeployedAt) {
+			return a.LastDeployedAt.Before(b.LastDeployedAt)
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.StackName < b.StackName
+	})
+
+	return report
+}
+
+// ephemeralStage returns the stage of the stack and whether it matches one of
+// patterns. Stages may contain hyphens ("pr-123"), which the stage derived from
+// the stack name cannot tell apart from the service name, so without a stage
+// tag the last two parts of the name are tried too when the last one is a
+// number. Longer suffixes are not: "payments-test-runner-prod" is not a "test-*" stage.
+func ephemeralStage(stack models.Stack, patterns []string) (string, bool) {
+	stage := stack.Stage()
+
+	candidates := []string{stage}
+	if stack.StageTag() == "" {
+		if numbered := numberedStage(stack.StackName); numbered != "" {
+			candidates = append(candidates, numbered)
+		}
+	}
+
+	for _, candidate := range candidates {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return candidate, true
+			}
+		}
+	}
+	return stage, false
+}
+
+// numberedStage returns the "<prefix>-<number>" end of a stack name such as
+// "orders-api-pr-123", or "" when the name does not end that way
+func numberedStage(name string) string {
+	parts := strings.Split(name, "-")
+	if len(parts) < 3 {
+		return ""
+	}
+
+	prefix, id := parts[len(parts)-2], parts[len(parts)-1]
+	if prefix == "" {
+		return ""
+	}
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return ""
+	}
+	return prefix + "-" + id
+}
+
+// ParseAge parses an age such as "90d", "12w" or a Go duration such as "36h"
+func ParseAge(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if number, found := strings.CutSuffix(value, suffix); found {
+			n, err := strconv.Atoi(number)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid age '%s': expected a positive number of days (90d) or weeks (12w)", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid age '%s': expected a number of days (90d), weeks (12w) or a duration (36h)", value)
+	}
+	return duration, nil
+}
+
+// FormatAge renders a duration in whole days when it is one, as in "90d"
+func FormatAge(d time.Duration) string {
+	day := 24 * time.Hour
+	if d > 0 && d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return d.String()
+}
@@ -0,0 +1,170 @@
+package stale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAsOf = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testStacks() []models.Stack {
+	return []models.Stack{
+		{
+			StackName: "orders-api-prod",
+			Region:    "us-east-1",
+			CreatedAt: testAsOf.AddDate(-2, 0, 0),
+			UpdatedAt: testAsOf.AddDate(0, 0, -3),
+		},
+		{
+			StackName: "orders-api-pr-123",
+			Region:    "us-east-1",
+			CreatedAt: testAsOf.AddDate(-1, 0, 0),
+			UpdatedAt: testAsOf.AddDate(0, -6, 0),
+			StackTags: map[string]string{"Owner": "team-a"},
+		},
+		{
+			StackName:    "billing-alice",
+			Region:       "us-west-2",
+			CreatedAt:    testAsOf.AddDate(0, -4, 0),
+			NeverUpdated: true,
+		},
+		{
+			StackName: "billing-sandbox",
+			Region:    "us-west-2",
+			CreatedAt: testAsOf.AddDate(-1, 0, 0),
+			UpdatedAt: testAsOf.AddDate(0, -10, 0),
+			StackTags: map[string]string{"STAGE": "feature-x", "owner": "team-b"},
+		},
+		{
+			StackName: "unknown-dev",
+			Region:    "us-west-2",
+		},
+	}
+}
+
+func TestFind(t *testing.T) {
+	opts := Options{OlderThan: 90 * 24 * time.Hour, EphemeralStages: append([]string{"alice"}, DefaultEphemeralStages...)}
+	require.NoError(t, opts.Validate())
+
+	report := Find(testStacks(), testAsOf, opts)
+
+	assert.Equal(t, testAsOf, report.AsOf)
+	assert.Equal(t, "90d", report.OlderThan)
+	assert.Equal(t, 1, report.Skipped)
+
+	// Oldest deployment first; the recently updated stack is not stale
+	var names []string
+	for _, entry := range report.Stacks {
+		names = append(names, entry.StackName)
+	}
+	assert.Equal(t, []string{"billing-sandbox", "orders-api-pr-123", "billing-alice"}, names)
+
+	assert.Equal(t, Entry{
+		StackName:      "billing-sandbox",
+		Region:         "us-west-2",
+		Stage:          "feature-x",
+		Owner:          "team-b",
+		LastDeployedAt: testAsOf.AddDate(0, -10, 0),
+		AgeDays:        305,
+		Ephemeral:      true,
+	}, report.Stacks[0])
+
+	// A hyphenated stage is found in the stack name
+	assert.Equal(t, "pr-123", report.Stacks[1].Stage)
+	assert.True(t, report.Stacks[1].Ephemeral)
+	assert.Equal(t, "team-a", report.Stacks[1].Owner)
+
+	// Never-updated stacks are aged from their creation
+	assert.Equal(t, "alice", report.Stacks[2].Stage)
+	assert.True(t, report.Stacks[2].Ephemeral)
+	assert.Equal(t, testAsOf.AddDate(0, -4, 0), report.Stacks[2].LastDeployedAt)
+}
+
+func TestEphemeralStage(t *testing.T) {
+	tests := []struct {
+		name          string
+		stack         models.Stack
+		wantStage     string
+		wantEphemeral bool
+	}{
+		{name: "stage from the name", stack: models.Stack{StackName: "orders-api-dev"}, wantStage: "dev"},
+		{name: "numbered stage", stack: models.Stack{StackName: "orders-api-pr-123"}, wantStage: "pr-123", wantEphemeral: true},
+		{name: "unhyphenated stage", stack: models.Stack{StackName: "orders-api-pr123"}, wantStage: "pr123", wantEphemeral: true},
+		{name: "stage tag", stack: models.Stack{StackName: "orders-api", StackTags: map[string]string{"STAGE": "feature-login"}}, wantStage: "feature-login", wantEphemeral: true},
+		// Hyphenated service names are not stages
+		{name: "test in the service name", stack: models.Stack{StackName: "payments-test-runner-prod"}, wantStage: "prod"},
+		{name: "feature in the service name", stack: models.Stack{StackName: "orders-feature-flags-prod"}, wantStage: "prod"},
+		{name: "prefix before a word", stack: models.Stack{StackName: "billing-feature-prod"}, wantStage: "prod"},
+		{name: "number in the service name", stack: models.Stack{StackName: "orders-pr-2-prod"}, wantStage: "prod"},
+		{name: "tag wins over the name", stack: models.Stack{StackName: "orders-api-pr-123", StackTags: map[string]string{"STAGE": "prod"}}, wantStage: "prod"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, ephemeral := ephemeralStage(tt.stack, DefaultEphemeralStages)
+			assert.Equal(t, tt.wantStage, stage)
+			assert.Equal(t, tt.wantEphemeral, ephemeral)
+		})
+	}
+}
+
+func TestFind_NotEphemeral(t *testing.T) {
+	report := Find(testStacks(), testAsOf, Options{OlderThan: 90 * 24 * time.Hour})
+
+	require.Len(t, report.Stacks, 3)
+	for _, entry := range report.Stacks {
+		assert.False(t, entry.Ephemeral, entry.StackName)
+	}
+	// Without a match the stage is the one derived from the stack name
+	assert.Equal(t, "123", report.Stacks[1].Stage)
+
+	report = Find(nil, testAsOf, Options{OlderThan: time.Hour})
+	assert.NotNil(t, report.Stacks)
+	assert.Empty(t, report.Stacks)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.Error(t, Options{}.Validate())
+
+	err := Options{OlderThan: time.Hour, EphemeralStages: []string{"pr-["}}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stage pattern 'pr-['")
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "90d", expected: 90 * 24 * time.Hour},
+		{value: "12w", expected: 12 * 7 * 24 * time.Hour},
+		{value: "36h", expected: 36 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "-5d", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "3x", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			age, err := ParseAge(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, age)
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "90d", FormatAge(90*24*time.Hour))
+	assert.Equal(t, "36h0m0s", FormatAge(36*time.Hour))
+}
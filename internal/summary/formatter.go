@@ -0,0 +1,83 @@
+package summary
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats summary reports
+type Formatter = output.ReportFormatter[*Report]
+
+// TableFormatter formats a summary as aligned tables for terminals
+type TableFormatter struct{}
+
+// tableTimeFormat matches the compact timestamps of the stack table output
+const tableTimeFormat = "2006-01-02 15:04"
+
+// Format implements the Formatter interface for table output
+func (f *TableFormatter) Format(report *Report) (string, error) {
+	var out strings.Builder
+	// Lines without tabs end a column block, so each section is aligned on its own
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	scanned := "unknown"
+	if report.Stacks.Scanned != nil {
+		scanned = strconv.Itoa(*report.Stacks.Scanned)
+	}
+	fmt.Fprintln(w, "STACKS")
+	fmt.Fprintf(w, "  Scanned\t%s\n", scanned)
+	fmt.Fprintf(w, "  Detected\t%d\n", report.Stacks.Detected)
+	fmt.Fprintf(w, "  Failed\t%d\n", report.Stacks.Failed)
+
+	writeGroups(w, "REGION", report.ByRegion)
+	writeGroups(w, "STAGE", report.ByStage)
+	writeGroups(w, "OWNER", report.ByOwner)
+	writeGroups(w, "FRAMEWORK VERSION", report.ByFrameworkVersion)
+	writeGroups(w, "LAST DEPLOYED (as of "+report.AsOf.Format(tableTimeFormat)+")", report.LastDeployedAge)
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Oldest deployment\t%s\n", formatDeployment(report.Oldest))
+	fmt.Fprintf(w, "Newest deployment\t%s\n", formatDeployment(report.Newest))
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write table: %w", err)
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// writeGroups writes a titled section with one line per group
+func writeGroups(w *tabwriter.Writer, title string, groups []Group) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, title)
+	for _, group := range groups {
+		key := group.Key
+		if key == "" {
+			key = "(none)"
+		}
+		fmt.Fprintf(w, "  %s\t%d\n", key, group.Count)
+	}
+}
+
+// formatDeployment renders a deployment as "name (region) time"
+func formatDeployment(deployment *Deployment) string {
+	if deployment == nil {
+		return "(none)"
+	}
+	return fmt.Sprintf("%s (%s) %s", deployment.StackName, deployment.Region, deployment.DeployedAt.Format(tableTimeFormat))
+}
+
+// formats lists the summary formats in display order
+var formats = output.ReportFormats[*Report]{
+	{Name: "json", New: func() Formatter { return output.JSON[*Report]{} }},
+	{Name: "table", New: func() Formatter { return &TableFormatter{} }},
+}
+
+// FormatterFactory creates a summary formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("summary", format)
+}
@@ -0,0 +1,42 @@
+package summary
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONFormatter_Format(t *testing.T) {
+	formatter := output.JSON[*Report]{}
+
+	output, err := formatter.Format(Compute(testStacks(), nil, testAsOf))
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(output), &decoded))
+	assert.Equal(t, map[string]interface{}{"scanned": nil, "detected": 4.0, "failed": 0.0}, decoded["stacks"])
+	assert.Contains(t, decoded, "byFrameworkVersion")
+	assert.Contains(t, decoded, "lastDeployedAge")
+	assert.Equal(t, "orders-api-prod", decoded["oldest"].(map[string]interface{})["stackName"])
+}
+
+func TestTableFormatter_Format(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	output, err := formatter.Format(Compute(testStacks(), nil, testAsOf))
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "STACKS\n  Scanned   unknown\n  Detected  4\n  Failed    0")
+	assert.Contains(t, output, "REGION\n  us-east-1  2\n  us-west-2  2")
+	assert.Contains(t, output, "OWNER\n  team-a  2\n  (none)  1\n  team-b  1")
+	assert.Contains(t, output, "LAST DEPLOYED (as of 2024-06-01 00:00)")
+	assert.Contains(t, output, "Oldest deployment  orders-api-prod (us-east-1) 2022-06-01 00:00")
+	assert.Contains(t, output, "Newest deployment  orders-api-dev (us-east-1) 2024-05-30 00:00")
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"json", "table"}, formats.Names())
+}
@@ -0,0 +1,186 @@
+package summary
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+)
+
+// AgeBucket is a range of time since the last deployment
+type AgeBucket struct {
+	Label string
+	// Max is the exclusive upper bound; zero means unbounded
+	Max time.Duration
+}
+
+// AgeBuckets lists the ranges used for the age distribution, from newest to oldest
+var AgeBuckets = []AgeBucket{
+	{Label: "0-7d", Max: 7 * 24 * time.Hour},
+	{Label: "7-30d", Max: 30 * 24 * time.Hour},
+	{Label: "30-90d", Max: 90 * 24 * time.Hour},
+	{Label: "90-365d", Max: 365 * 24 * time.Hour},
+	{Label: "365d+"},
+}
+
+// unknownAge labels stacks whose deployment time is unknown
+const unknownAge = "unknown"
+
+// Counts holds the number of stacks at each stage of the scan
+type Counts struct {
+	// Scanned is nil when the input does not record it
+	Scanned  *int `json:"scanned"`
+	Detected int  `json:"detected"`
+	Failed   int  `json:"failed"`
+}
+
+// Group counts the stacks sharing a value; an empty Key means the value is not set
+type Group struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// Deployment identifies a stack by its last deployment
+type Deployment struct {
+	StackName  string    `json:"stackName"`
+	Region     string    `json:"region"`
+	DeployedAt time.Time `json:"deployedAt"`
+}
+
+// Report holds the aggregate statistics of a scan
+type Report struct {
+	// AsOf is the time ages are measured from: the scan start when known
+	AsOf               time.Time   `json:"asOf"`
+	Stacks             Counts      `json:"stacks"`
+	ByRegion           []Group     `json:"byRegion"`
+	ByStage            []Group     `json:"byStage"`
+	ByOwner            []Group     `json:"byOwner"`
+	ByFrameworkVersion []Group     `json:"byFrameworkVersion"`
+	LastDeployedAge    []Group     `json:"lastDeployedAge"`
+	Oldest             *Deployment `json:"oldest"`
+	Newest             *Deployment `json:"newest"`
+}
+
+// Compute aggregates the stacks of a scan. scan may be nil for documents
+// without scan metadata, in which case ages are measured from now.
+func Compute(stacks []models.Stack, scan *models.ScanMetadata, now time.Time) *Report {
+	report := &Report{
+		AsOf:   now,
+		Stacks: Counts{Detected: len(stacks)},
+	}
+
+	if scan != nil {
+		if !scan.StartedAt.IsZero() {
+			report.AsOf = scan.StartedAt
+		}
+		if scan.StacksScanned != nil {
+			scanned := *scan.StacksScanned
+			report.Stacks.Scanned = &scanned
+		}
+		report.Stacks.Failed = failedStacks(scan.Errors, stacks)
+	}
+
+	byRegion := make(map[string]int)
+	byStage := make(map[string]int)
+	byOwner := make(map[string]int)
+	byVersion := make(map[string]int)
+	byAge := make(map[string]int)
+
+	for _, stack := range stacks {
+		byRegion[stack.Region]++
+		byStage[stack.Stage()]++
+		byOwner[stack.Owner()]++
+		byVersion[stack.FrameworkVersion()]++
+
+		deployedAt := stack.LastDeployedAt()
+		byAge[ageLabel(deployedAt, report.AsOf)]++
+
+		if deployedAt.IsZero() {
+			continue
+		}
+		deployment := &Deployment{StackName: stack.StackName, Region: stack.Region, DeployedAt: deployedAt}
+		if report.Oldest == nil || deployedAt.Before(report.Oldest.DeployedAt) {
+			report.Oldest = deployment
+		}
+		if report.Newest == nil || deployedAt.After(report.Newest.DeployedAt) {
+			report.Newest = deployment
+		}
+	}
+
+	report.ByRegion = sortedGroups(byRegion)
+	report.ByStage = sortedGroups(byStage)
+	report.ByOwner = sortedGroups(byOwner)
+	report.ByFrameworkVersion = sortedGroups(byVersion)
+	report.LastDeployedAge = ageGroups(byAge)
+
+	return report
+}
+
+// failedStacks counts the distinct stacks that could not be inspected. Stacks
+// that were detected despite an error, such as a failed DescribeStacks, are not counted.
+func failedStacks(errors []models.ScanError, stacks []models.Stack) int {
+	detected := make(map[string]bool, len(stacks))
+	for _, stack := range stacks {
+		detected[stackKey(stack.Profile, stack.Region, stack.StackName)] = true
+	}
+
+	failed := make(map[string]bool)
+	for _, scanErr := range errors {
+		key := stackKey(scanErr.Profile, scanErr.Region, scanErr.StackName)
+		if scanErr.StackName != "" && !detected[key] {
+			failed[key] = true
+		}
+	}
+	return len(failed)
+}
+
+// stackKey identifies a stack of a scan. In scans of several profiles, each
+// account is scanned with one profile, which keeps the stacks of different
+// accounts apart.
+func stackKey(profile, region, stackName string) string {
+	return profile + "/" + region + "/" + stackName
+}
+
+// ageLabel returns the label of the age bucket that deployedAt falls into
+func ageLabel(deployedAt, asOf time.Time) string {
+	if deployedAt.IsZero() {
+		return unknownAge
+	}
+
+	age := asOf.Sub(deployedAt)
+	for _, bucket := range AgeBuckets {
+		if bucket.Max == 0 || age < bucket.Max {
+			return bucket.Label
+		}
+	}
+	return AgeBuckets[len(AgeBuckets)-1].Label
+}
+
+// ageGroups lists every age bucket in order, including empty ones, followed
+// by the stacks of unknown age if there are any
+func ageGroups(counts map[string]int) []Group {
+	groups := make([]Group, 0, len(AgeBuckets)+1)
+	for _, bucket := range AgeBuckets {
+		groups = append(groups, Group{Key: bucket.Label, Count: counts[bucket.Label]})
+	}
+	if counts[unknownAge] > 0 {
+		groups = append(groups, Group{Key: unknownAge, Count: counts[unknownAge]})
+	}
+	return groups
+}
+
+// sortedGroups lists the counts from most to least common, then by key
+func sortedGroups(counts map[string]int) []Group {
+	groups := make([]Group, 0, len(counts))
+	for key, count := range counts {
+		groups = append(groups, Group{Key: key, Count: count})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
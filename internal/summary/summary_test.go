@@ -0,0 +1,146 @@
+package summary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAsOf = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testStacks() []models.Stack {
+	return []models.Stack{
+		{
+			StackName: "orders-api-dev",
+			Region:    "us-east-1",
+			CreatedAt: testAsOf.AddDate(0, -3, 0),
+			UpdatedAt: testAsOf.AddDate(0, 0, -2),
+			StackTags: map[string]string{"Owner": "team-a", "FRAMEWORK_VERSION": "3.38.0"},
+		},
+		{
+			StackName:    "orders-api-prod",
+			Region:       "us-east-1",
+			CreatedAt:    testAsOf.AddDate(-2, 0, 0),
+			NeverUpdated: true,
+			StackTags:    map[string]string{"owner": "team-a"},
+		},
+		{
+			StackName: "billing-prod",
+			Region:    "us-west-2",
+			CreatedAt: testAsOf.AddDate(0, -6, 0),
+			UpdatedAt: testAsOf.AddDate(0, 0, -45),
+			StackTags: map[string]string{"STAGE": "prod", "Owner": "team-b", "FRAMEWORK_VERSION": "3.38.0"},
+		},
+		{
+			StackName: "legacy",
+			Region:    "us-west-2",
+		},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	scanned := 10
+	scan := &models.ScanMetadata{
+		StartedAt:     testAsOf,
+		StacksScanned: &scanned,
+		Errors: []models.ScanError{
+			{Region: "us-east-1", StackName: "broken", Operation: "DescribeStackResources", Message: "throttled"},
+			{Region: "us-east-1", StackName: "broken", Operation: "DescribeStacks", Message: "throttled"},
+			{Region: "us-east-1", StackName: "orders-api-dev", Operation: "DescribeStacks", Message: "throttled"},
+			{Region: "us-east-1", Operation: "GetCallerIdentity", Message: "denied"},
+		},
+	}
+
+	report := Compute(testStacks(), scan, time.Now())
+
+	assert.Equal(t, testAsOf, report.AsOf)
+	require.NotNil(t, report.Stacks.Scanned)
+	assert.Equal(t, 10, *report.Stacks.Scanned)
+	assert.Equal(t, 4, report.Stacks.Detected)
+	// Two errors for the same stack count once; account-level errors and errors
+	// for stacks that were still detected are not stack failures
+	assert.Equal(t, 1, report.Stacks.Failed)
+
+	assert.Equal(t, []Group{{Key: "us-east-1", Count: 2}, {Key: "us-west-2", Count: 2}}, report.ByRegion)
+	assert.Equal(t, []Group{{Key: "prod", Count: 2}, {Key: "", Count: 1}, {Key: "dev", Count: 1}}, report.ByStage)
+	assert.Equal(t, []Group{{Key: "team-a", Count: 2}, {Key: "", Count: 1}, {Key: "team-b", Count: 1}}, report.ByOwner)
+	assert.Equal(t, []Group{{Key: "", Count: 2}, {Key: "3.38.0", Count: 2}}, report.ByFrameworkVersion)
+
+	assert.Equal(t, []Group{
+		{Key: "0-7d", Count: 1},
+		{Key: "7-30d", Count: 0},
+		{Key: "30-90d", Count: 1},
+		{Key: "90-365d", Count: 0},
+		{Key: "365d+", Count: 1},
+		{Key: "unknown", Count: 1},
+	}, report.LastDeployedAge)
+
+	require.NotNil(t, report.Oldest)
+	assert.Equal(t, "orders-api-prod", report.Oldest.StackName)
+	assert.Equal(t, testAsOf.AddDate(-2, 0, 0), report.Oldest.DeployedAt)
+	require.NotNil(t, report.Newest)
+	assert.Equal(t, "orders-api-dev", report.Newest.StackName)
+}
+
+func TestCompute_FailedStacksAcrossAccounts(t *testing.T) {
+	stacks := []models.Stack{
+		{StackName: "orders-api-prod", Region: "us-east-1", AccountID: "111111111111", Profile: "dev"},
+	}
+	scan := &models.ScanMetadata{
+		StartedAt: testAsOf,
+		Errors: []models.ScanError{
+			// The same stack name fails in the other account
+			{Profile: "prod", Region: "us-east-1", StackName: "orders-api-prod", Operation: "DescribeStackResources", Message: "throttled"},
+			// and is detected despite an error in the first one
+			{Profile: "dev", Region: "us-east-1", StackName: "orders-api-prod", Operation: "DescribeStacks", Message: "throttled"},
+		},
+	}
+
+	report := Compute(stacks, scan, time.Now())
+	assert.Equal(t, 1, report.Stacks.Failed)
+}
+
+func TestCompute_NoActiveStacks(t *testing.T) {
+	scanned := 0
+	report := Compute(nil, &models.ScanMetadata{StartedAt: testAsOf, StacksScanned: &scanned}, time.Now())
+
+	// An empty account is reported as 0 scanned, not as unknown
+	require.NotNil(t, report.Stacks.Scanned)
+	assert.Equal(t, 0, *report.Stacks.Scanned)
+}
+
+func TestCompute_WithoutScanMetadata(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	report := Compute(nil, nil, now)
+
+	assert.Equal(t, now, report.AsOf)
+	assert.Nil(t, report.Stacks.Scanned)
+	assert.Equal(t, 0, report.Stacks.Detected)
+	assert.Empty(t, report.ByRegion)
+	assert.Nil(t, report.Oldest)
+	assert.Nil(t, report.Newest)
+	// The age buckets are always listed
+	assert.Len(t, report.LastDeployedAge, len(AgeBuckets))
+}
+
+func TestAgeLabel(t *testing.T) {
+	tests := []struct {
+		age      time.Duration
+		expected string
+	}{
+		{0, "0-7d"},
+		{7*24*time.Hour - time.Second, "0-7d"},
+		{7 * 24 * time.Hour, "7-30d"},
+		{89 * 24 * time.Hour, "30-90d"},
+		{365 * 24 * time.Hour, "365d+"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ageLabel(testAsOf.Add(-tt.age), testAsOf), tt.age.String())
+	}
+	assert.Equal(t, "unknown", ageLabel(time.Time{}, testAsOf))
+}
@@ -0,0 +1,140 @@
+package tagcheck
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+)
+
+// Formatter formats tag check reports
+type Formatter = output.ReportFormatter[*Report]
+
+// TableFormatter formats the violations as an aligned table for terminals
+type TableFormatter struct{}
+
+// Format implements the Formatter interface for table output
+func (f *TableFormatter) Format(report *Report) (string, error) {
+	if !report.HasViolations() {
+		return fmt.Sprintf("All %d stacks comply with the tag policy.", report.Checked), nil
+	}
+
+	var out strings.Builder
+	w := tabwriter.NewWriter(&out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "STACK NAME\tREGION\tTAG\tPROBLEM")
+	for _, result := range report.Stacks {
+		if result.Error != "" {
+			fmt.Fprintf(w, "%s\t%s\t-\terror: %s\n", result.StackName, result.Region, result.Error)
+		}
+		for _, violation := range result.Violations {
+			problem := violation.Reason
+			if violation.Reason == ReasonInvalid {
+				problem = fmt.Sprintf("invalid value %q", violation.Value)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.StackName, result.Region, violation.Key, problem)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write table: %w", err)
+	}
+
+	out.WriteString(fmt.Sprintf("\n%d of %d stacks violate the tag policy", report.NonCompliant, report.Checked))
+	if report.Errors > 0 {
+		out.WriteString(fmt.Sprintf(", %d could not be checked", report.Errors))
+	}
+	return out.String(), nil
+}
+
+// JUnitFormatter formats the report as a JUnit XML test report, with one
+// test case per stack, so CI systems can display the violations
+type JUnitFormatter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr,omitempty"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr,omitempty"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Format implements the Formatter interface for JUnit output
+func (f *JUnitFormatter) Format(report *Report) (string, error) {
+	suite := junitTestSuite{
+		Name:     "required tags",
+		Tests:    report.Checked,
+		Failures: report.NonCompliant,
+		Errors:   report.Errors,
+		Cases:    make([]junitTestCase, 0, len(report.Stacks)),
+	}
+
+	for _, result := range report.Stacks {
+		testCase := junitTestCase{Name: result.StackName, ClassName: result.Region}
+		if result.Error != "" {
+			testCase.Error = &junitFailure{Message: result.Error, Type: "TagsUnavailable"}
+		} else if !result.Compliant() {
+			messages := make([]string, 0, len(result.Violations))
+			for _, violation := range result.Violations {
+				messages = append(messages, violation.Message)
+			}
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d tag policy violations", len(result.Violations)),
+				Type:    "TagPolicyViolation",
+				Text:    strings.Join(messages, "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Name:     "tag policy",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+
+	return xml.Header + string(data), nil
+}
+
+// formats lists the tag check report formats in display order
+var formats = output.ReportFormats[*Report]{
+	{Name: "table", New: func() Formatter { return &TableFormatter{} }},
+	{Name: "json", New: func() Formatter { return output.JSON[*Report]{} }},
+	{Name: "junit", New: func() Formatter { return &JUnitFormatter{} }},
+}
+
+// FormatterFactory creates a tag check report formatter based on the specified format
+func FormatterFactory(format string) (Formatter, error) {
+	return formats.New("tag check report", format)
+}
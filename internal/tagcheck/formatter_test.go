@@ -0,0 +1,102 @@
+package tagcheck
+
+import (
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	return &Report{
+		Checked:      2,
+		NonCompliant: 1,
+		Stacks: []StackResult{
+			{StackName: "orders-prod", StackID: "arn:orders-prod", Region: "us-east-1", Violations: []Violation{}},
+			{
+				StackName: "users-dev",
+				StackID:   "arn:users-dev",
+				Region:    "us-east-1",
+				Violations: []Violation{
+					{Key: "Owner", Reason: ReasonMissing, Message: "tag Owner is missing"},
+					{Key: "CostCenter", Reason: ReasonInvalid, Value: "abc", Message: `tag CostCenter value "abc" does not match [0-9]{4}`},
+				},
+			},
+		},
+	}
+}
+
+func TestTableFormatter(t *testing.T) {
+	formatter := &TableFormatter{}
+
+	result, err := formatter.Format(testReport())
+	require.NoError(t, err)
+	expected := `STACK NAME  REGION     TAG         PROBLEM
+users-dev   us-east-1  Owner       missing
+users-dev   us-east-1  CostCenter  invalid value "abc"
+
+1 of 2 stacks violate the tag policy`
+	assert.Equal(t, expected, result)
+
+	result, err = formatter.Format(&Report{Checked: 4})
+	require.NoError(t, err)
+	assert.Equal(t, "All 4 stacks comply with the tag policy.", result)
+}
+
+func TestJSONFormatter(t *testing.T) {
+	result, err := output.JSON[*Report]{}.Format(testReport())
+	require.NoError(t, err)
+	assert.Contains(t, result, `"checked":2,"nonCompliant":1`)
+	assert.Contains(t, result, `{"stackName":"orders-prod","stackId":"arn:orders-prod","region":"us-east-1","violations":[]}`)
+	assert.Contains(t, result, `{"key":"CostCenter","reason":"invalid","value":"abc","message":"tag CostCenter value \"abc\" does not match [0-9]{4}"}`)
+}
+
+func TestJUnitFormatter(t *testing.T) {
+	result, err := (&JUnitFormatter{}).Format(testReport())
+	require.NoError(t, err)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="tag policy" tests="2" failures="1">
+  <testsuite name="required tags" tests="2" failures="1">
+    <testcase name="orders-prod" classname="us-east-1"></testcase>
+    <testcase name="users-dev" classname="us-east-1">
+      <failure message="2 tag policy violations" type="TagPolicyViolation">tag Owner is missing&#xA;tag CostCenter value &#34;abc&#34; does not match [0-9]{4}</failure>
+    </testcase>
+  </testsuite>
+</testsuites>`
+	assert.Equal(t, expected, result)
+}
+
+func testReportWithError() *Report {
+	report := testReport()
+	report.Checked++
+	report.Errors = 1
+	report.Stacks = append(report.Stacks, StackResult{
+		StackName: "billing-prod", Region: "eu-west-1", Violations: []Violation{}, Error: "tags could not be read: throttled",
+	})
+	return report
+}
+
+func TestTableFormatter_Error(t *testing.T) {
+	result, err := (&TableFormatter{}).Format(testReportWithError())
+	require.NoError(t, err)
+	assert.Contains(t, result, "billing-prod  eu-west-1  -           error: tags could not be read: throttled")
+	assert.Contains(t, result, "1 of 3 stacks violate the tag policy, 1 could not be checked")
+
+	result, err = (&TableFormatter{}).Format(&Report{Checked: 1, Errors: 1, Stacks: []StackResult{{StackName: "a", Region: "us-east-1", Error: "tags could not be read: denied"}}})
+	require.NoError(t, err)
+	assert.NotContains(t, result, "comply")
+}
+
+func TestJUnitFormatter_Error(t *testing.T) {
+	result, err := (&JUnitFormatter{}).Format(testReportWithError())
+	require.NoError(t, err)
+	assert.Contains(t, result, `<testsuites name="tag policy" tests="3" failures="1" errors="1">`)
+	assert.Contains(t, result, `<testcase name="billing-prod" classname="eu-west-1">
+      <error message="tags could not be read: throttled" type="TagsUnavailable"></error>
+    </testcase>`)
+}
+
+func TestFormats(t *testing.T) {
+	assert.Equal(t, []string{"table", "json", "junit"}, formats.Names())
+}
@@ -0,0 +1,194 @@
+package tagcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Violation reasons
+const (
+	ReasonMissing = "missing"
+	ReasonEmpty   = "empty"
+	ReasonInvalid = "invalid"
+)
+
+// RequiredTag is a tag every stack must have
+type RequiredTag struct {
+	Key string `yaml:"key"`
+	// Pattern is an optional regular expression the whole value must match
+	Pattern string `yaml:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// Policy lists the tags required on every stack
+type Policy struct {
+	Required []RequiredTag `yaml:"required"`
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	policy, err := ParsePolicy(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// ParsePolicy parses and validates a policy written in YAML or JSON
+func ParsePolicy(data []byte) (*Policy, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var policy Policy
+	if err := decoder.Decode(&policy); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("policy is empty")
+		}
+		return nil, err
+	}
+
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// compile validates the required tags and compiles their patterns
+func (p *Policy) compile() error {
+	if len(p.Required) == 0 {
+		return fmt.Errorf("policy requires no tags")
+	}
+
+	seen := make(map[string]bool)
+	for i := range p.Required {
+		tag := &p.Required[i]
+		if tag.Key == "" {
+			return fmt.Errorf("required tag %d has no key", i+1)
+		}
+		if seen[tag.Key] {
+			return fmt.Errorf("tag %s is required more than once", tag.Key)
+		}
+		seen[tag.Key] = true
+
+		if tag.Pattern != "" {
+			pattern, err := regexp.Compile("^(?:" + tag.Pattern + ")$")
+			if err != nil {
+				return fmt.Errorf("invalid pattern for tag %s: %w", tag.Key, err)
+			}
+			tag.pattern = pattern
+		}
+	}
+	return nil
+}
+
+// Violation describes a required tag a stack does not satisfy
+type Violation struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+	// Value is the rejected value of invalid tags
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// StackResult is the outcome of checking one stack
+type StackResult struct {
+	StackName  string      `json:"stackName"`
+	StackID    string      `json:"stackId"`
+	Region     string      `json:"region"`
+	Violations []Violation `json:"violations"`
+	// Error is set when the tags of the stack could not be read, so it was not checked
+	Error string `json:"error,omitempty"`
+}
+
+// Compliant reports whether the stack was checked and has every required tag
+func (r StackResult) Compliant() bool {
+	return len(r.Violations) == 0 && r.Error == ""
+}
+
+// Report is the outcome of checking stacks against a policy
+type Report struct {
+	Checked int `json:"checked"`
+	// NonCompliant counts the stacks with at least one violation
+	NonCompliant int `json:"nonCompliant"`
+	// Errors counts the stacks whose tags could not be read
+	Errors int           `json:"errors"`
+	Stacks []StackResult `json:"stacks"`
+}
+
+// HasViolations reports whether any stack violates the policy or could not be checked
+func (r *Report) HasViolations() bool {
+	return r.NonCompliant > 0 || r.Errors > 0
+}
+
+// Check evaluates the tags of every stack against the policy. Stacks with a
+// failed DescribeStacks in scanErrors have no tags to check and are reported
+// as errors rather than as missing every tag.
+func Check(stacks []models.Stack, policy *Policy, scanErrors []models.ScanError) *Report {
+	report := &Report{Checked: len(stacks), Stacks: make([]StackResult, 0, len(stacks))}
+
+	unread := make(map[string]string)
+	for _, scanErr := range scanErrors {
+		if scanErr.Operation == "DescribeStacks" && scanErr.StackName != "" {
+			unread[scanErr.Profile+"/"+scanErr.Region+"/"+scanErr.StackName] = scanErr.Message
+		}
+	}
+
+	for _, stack := range stacks {
+		result := StackResult{
+			StackName:  stack.StackName,
+			StackID:    stack.StackID,
+			Region:     stack.Region,
+			Violations: []Violation{},
+		}
+		if message, ok := unread[stack.Profile+"/"+stack.Region+"/"+stack.StackName]; ok {
+			result.Error = fmt.Sprintf("tags could not be read: %s", message)
+			report.Errors++
+			report.Stacks = append(report.Stacks, result)
+			continue
+		}
+		for _, tag := range policy.Required {
+			if violation, ok := checkTag(stack.StackTags, tag); !ok {
+				result.Violations = append(result.Violations, violation)
+			}
+		}
+
+		if !result.Compliant() {
+			report.NonCompliant++
+		}
+		report.Stacks = append(report.Stacks, result)
+	}
+
+	return report
+}
+
+// checkTag returns the violation of a required tag, if any
+func checkTag(tags map[string]string, tag RequiredTag) (Violation, bool) {
+	value, ok := tags[tag.Key]
+	switch {
+	case !ok:
+		return Violation{Key: tag.Key, Reason: ReasonMissing, Message: fmt.Sprintf("tag %s is missing", tag.Key)}, false
+	case value == "":
+		return Violation{Key: tag.Key, Reason: ReasonEmpty, Message: fmt.Sprintf("tag %s is empty", tag.Key)}, false
+	case tag.pattern != nil && !tag.pattern.MatchString(value):
+		return Violation{
+			Key:     tag.Key,
+			Reason:  ReasonInvalid,
+			Value:   value,
+			Message: fmt.Sprintf("tag %s value %q does not match %s", tag.Key, value, tag.Pattern),
+		}, false
+	}
+	return Violation{}, true
+}
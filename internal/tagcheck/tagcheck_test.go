@@ -0,0 +1,130 @@
+package tagcheck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hassaku63/find-serverless-stacks/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+required:
+  - key: Owner
+  - key: CostCenter
+    pattern: "[0-9]{4}"
+  - key: Environment
+    pattern: "dev|staging|prod"
+`
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		expectedErrMsg string
+	}{
+		{name: "yaml", data: testPolicy},
+		{name: "json", data: `{"required": [{"key": "Owner"}, {"key": "CostCenter", "pattern": "[0-9]{4}"}]}`},
+		{name: "empty", data: "", expectedErrMsg: "policy is empty"},
+		{name: "no required tags", data: "required: []", expectedErrMsg: "policy requires no tags"},
+		{name: "missing key", data: "required:\n  - pattern: x", expectedErrMsg: "required tag 1 has no key"},
+		{name: "duplicate key", data: "required:\n  - key: Owner\n  - key: Owner", expectedErrMsg: "tag Owner is required more than once"},
+		{name: "invalid pattern", data: "required:\n  - key: Owner\n    pattern: '['", expectedErrMsg: "invalid pattern for tag Owner"},
+		{name: "unknown field", data: "required:\n  - key: Owner\n    allowed: x", expectedErrMsg: "field allowed not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParsePolicy([]byte(tt.data))
+			if tt.expectedErrMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, policy.Required)
+		})
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0o600))
+
+	policy, err := LoadPolicy(path)
+	require.NoError(t, err)
+	assert.Len(t, policy.Required, 3)
+
+	_, err = LoadPolicy(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read policy file")
+}
+
+func TestCheck(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	stacks := []models.Stack{
+		{
+			StackName: "orders-prod",
+			StackID:   "arn:orders-prod",
+			Region:    "us-east-1",
+			StackTags: map[string]string{"Owner": "team-a", "CostCenter": "1234", "Environment": "prod"},
+		},
+		{
+			StackName: "users-dev",
+			StackID:   "arn:users-dev",
+			Region:    "us-east-1",
+			StackTags: map[string]string{"Owner": "", "CostCenter": "12345", "environment": "dev"},
+		},
+		{StackName: "untagged", StackID: "arn:untagged", Region: "eu-west-1"},
+	}
+
+	report := Check(stacks, policy, nil)
+	assert.Equal(t, 3, report.Checked)
+	assert.Equal(t, 2, report.NonCompliant)
+	assert.True(t, report.HasViolations())
+	require.Len(t, report.Stacks, 3)
+
+	assert.True(t, report.Stacks[0].Compliant())
+	assert.Equal(t, []Violation{}, report.Stacks[0].Violations)
+
+	// Tag keys are case-sensitive, like in AWS
+	assert.Equal(t, []Violation{
+		{Key: "Owner", Reason: ReasonEmpty, Message: "tag Owner is empty"},
+		{Key: "CostCenter", Reason: ReasonInvalid, Value: "12345", Message: `tag CostCenter value "12345" does not match [0-9]{4}`},
+		{Key: "Environment", Reason: ReasonMissing, Message: "tag Environment is missing"},
+	}, report.Stacks[1].Violations)
+
+	assert.Len(t, report.Stacks[2].Violations, 3)
+
+	compliant := Check(stacks[:1], policy, nil)
+	assert.False(t, compliant.HasViolations())
+}
+
+func TestCheck_UnreadTags(t *testing.T) {
+	policy, err := ParsePolicy([]byte(testPolicy))
+	require.NoError(t, err)
+
+	stacks := []models.Stack{
+		{StackName: "orders-prod", Region: "us-east-1", Profile: "prod"},
+		{StackName: "orders-prod", Region: "us-east-1", Profile: "dev", StackTags: map[string]string{"Owner": "team-a", "CostCenter": "1234", "Environment": "dev"}},
+	}
+	scanErrors := []models.ScanError{
+		{Profile: "prod", Region: "us-east-1", StackName: "orders-prod", Operation: "DescribeStacks", Message: "throttled"},
+		{Profile: "dev", Region: "us-east-1", StackName: "other", Operation: "DescribeStacks", Message: "throttled"},
+	}
+
+	report := Check(stacks, policy, scanErrors)
+	assert.Equal(t, 0, report.NonCompliant)
+	assert.Equal(t, 1, report.Errors)
+	assert.True(t, report.HasViolations())
+
+	// The stack without tags is not reported as missing every tag
+	assert.Equal(t, []Violation{}, report.Stacks[0].Violations)
+	assert.Equal(t, "tags could not be read: throttled", report.Stacks[0].Error)
+	assert.False(t, report.Stacks[0].Compliant())
+	assert.True(t, report.Stacks[1].Compliant())
+}